@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/chickenzord/portosync/internal/export"
+	"github.com/chickenzord/portosync/internal/portfolio"
+	"github.com/chickenzord/portosync/internal/server"
+)
+
+// runExport implements the export command
+func runExport(ctx context.Context, mcpServer *server.MCP, args []string) error {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	format := flags.String("format", string(export.FormatCSV), "output format: csv, jsonl, json, beancount or hledger")
+	accounts := flags.String("accounts", "", "comma-separated account names to export (default: all)")
+	from := flags.String("from", "", "start date (YYYY-MM-DD) of stored snapshots to export")
+	to := flags.String("to", "", "end date (YYYY-MM-DD) of stored snapshots to export")
+	output := flags.String("output", "", "output file (default: stdout)")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	opts, err := parseExportOptions(*format, *accounts, *from, *to)
+	if err != nil {
+		return err
+	}
+
+	if *output == "" {
+		return mcpServer.Export(ctx, os.Stdout, opts)
+	}
+
+	f, err := os.Create(*output)
+	if err != nil {
+		return err
+	}
+
+	if err := mcpServer.Export(ctx, f, opts); err != nil {
+		f.Close()
+
+		return err
+	}
+
+	return f.Close()
+}
+
+func parseExportOptions(format, accounts, from, to string) (server.ExportOptions, error) {
+	var opts server.ExportOptions
+
+	f, err := export.ParseFormat(format)
+	if err != nil {
+		return opts, err
+	}
+
+	opts.Format = f
+
+	for name := range strings.SplitSeq(accounts, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			opts.AccountNames = append(opts.AccountNames, name)
+		}
+	}
+
+	if from != "" {
+		if opts.From, err = portfolio.ParseDate(from); err != nil {
+			return opts, fmt.Errorf("invalid -from date: %w", err)
+		}
+	}
+
+	if to != "" {
+		if opts.To, err = portfolio.ParseDate(to); err != nil {
+			return opts, fmt.Errorf("invalid -to date: %w", err)
+		}
+	}
+
+	return opts, nil
+}
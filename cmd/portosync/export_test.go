@@ -0,0 +1,29 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/chickenzord/portosync/internal/export"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseExportOptions(t *testing.T) {
+	opts, err := parseExportOptions("jsonl", " personal, ,business ", "2026-01-01", "2026-01-31")
+	assert.NoError(t, err)
+	assert.Equal(t, export.FormatJSONL, opts.Format)
+	assert.Equal(t, []string{"personal", "business"}, opts.AccountNames)
+	assert.Equal(t, "2026-01-01", opts.From.Format("2006-01-02"))
+	assert.Equal(t, "2026-01-31", opts.To.Format("2006-01-02"))
+	assert.False(t, opts.Live())
+
+	opts, err = parseExportOptions("csv", "", "", "")
+	assert.NoError(t, err)
+	assert.Nil(t, opts.AccountNames)
+	assert.True(t, opts.Live())
+
+	_, err = parseExportOptions("xlsx", "", "", "")
+	assert.Error(t, err)
+
+	_, err = parseExportOptions("csv", "", "01/02/2026", "")
+	assert.Error(t, err)
+}
@@ -4,7 +4,14 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strings"
+	"time"
 
+	"github.com/chickenzord/portosync/internal/alert"
+	"github.com/chickenzord/portosync/internal/digest"
+	"github.com/chickenzord/portosync/internal/export"
+	"github.com/chickenzord/portosync/internal/notify"
+	"github.com/chickenzord/portosync/internal/rebalance"
 	"github.com/chickenzord/portosync/internal/server"
 	"github.com/chickenzord/portosync/internal/version"
 )
@@ -16,6 +23,44 @@ func main() {
 	kseiAccounts := parseKseiAccountsWithName(os.Getenv("KSEI_ACCOUNTS"))
 	kseiPlainPassword := os.Getenv("KSEI_PLAIN_PASSWORD") != "false" // default to true
 	kseiAuthCacheDir := os.Getenv("KSEI_AUTH_CACHE_DIR")
+	manualAccounts := parseKeyValues(os.Getenv("MANUAL_ACCOUNTS"))
+	brokerAccounts := parseBrokerAccounts(os.Getenv("BROKER_ACCOUNTS"))
+	brokerProfilesFile := os.Getenv("BROKER_PROFILES_FILE")
+	dataDir := os.Getenv("DATA_DIR")
+	fetchInterval := parseDuration(os.Getenv("FETCH_INTERVAL"))
+	rebalanceTolerance := parseFloat(os.Getenv("REBALANCE_TOLERANCE"), rebalance.DefaultTolerance)
+
+	alertWebhookURL := strings.TrimSpace(os.Getenv("ALERT_WEBHOOK_URL"))
+	alertRules := alert.Rules{
+		ValueDrop:      parseFloat(os.Getenv("ALERT_VALUE_DROP"), alert.DefaultValueDrop),
+		HoldingChanges: os.Getenv("ALERT_HOLDING_CHANGES") != "false", // default to true
+		FetchFailing:   alert.DefaultFetchFailing,
+	}
+
+	if s := os.Getenv("ALERT_FETCH_FAILING_AFTER"); s != "" {
+		alertRules.FetchFailing = parseDuration(s)
+	}
+
+	var notifiers []notify.Notifier
+
+	// Secrets pasted with surrounding whitespace would make unparsable URLs
+	if url := strings.TrimSpace(os.Getenv("SLACK_WEBHOOK_URL")); url != "" {
+		notifiers = append(notifiers, notify.Slack{URL: url})
+	}
+
+	if token := strings.TrimSpace(os.Getenv("TELEGRAM_BOT_TOKEN")); token != "" {
+		notifiers = append(notifiers, notify.Telegram{
+			URL:    os.Getenv("TELEGRAM_API_URL"),
+			Token:  token,
+			ChatID: os.Getenv("TELEGRAM_CHAT_ID"),
+		})
+	}
+
+	ledgerOpts := export.LedgerOptions{
+		AccountTemplate: os.Getenv("LEDGER_ACCOUNT_TEMPLATE"),
+		AccountNames:    parseKeyValues(os.Getenv("LEDGER_ACCOUNT_NAMES")),
+		AssetTypeNames:  parseKeyValues(os.Getenv("LEDGER_ASSET_TYPE_NAMES")),
+	}
 
 	if kseiAuthCacheDir == "" {
 		dir, err := os.MkdirTemp("", "portosync_ksei_auth")
@@ -28,7 +73,7 @@ func main() {
 
 	if len(os.Args) < 2 {
 		fmt.Fprintf(os.Stderr, "Usage: %s <command>\n", os.Args[0])
-		fmt.Fprintf(os.Stderr, "Commands: mcp-stdio, mcp-http, version\n")
+		fmt.Fprintf(os.Stderr, "Commands: mcp-stdio, mcp-http, export, version\n")
 		os.Exit(1)
 	}
 
@@ -40,6 +85,42 @@ func main() {
 		os.Exit(0)
 	}
 
+	// Settings used only by the MCP server are validated for the MCP commands,
+	// so other commands do not fail on them
+	serving := command == "mcp-http" || command == "mcp-stdio"
+
+	var (
+		rebalanceTargets []rebalance.Target
+		digestTime       time.Duration
+	)
+
+	if serving {
+		var err error
+
+		rebalanceTargets, err = rebalance.ParseTargets(parseKeyValues(os.Getenv("TARGET_ALLOCATION")))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: invalid TARGET_ALLOCATION: %v\n", err)
+			os.Exit(1)
+		}
+
+		if rebalanceTolerance < 0 {
+			fmt.Fprintf(os.Stderr, "Error: REBALANCE_TOLERANCE must not be negative\n")
+			os.Exit(1)
+		}
+
+		if os.Getenv("TELEGRAM_BOT_TOKEN") != "" && os.Getenv("TELEGRAM_CHAT_ID") == "" {
+			fmt.Fprintf(os.Stderr, "Error: TELEGRAM_CHAT_ID is required when TELEGRAM_BOT_TOKEN is set\n")
+			os.Exit(1)
+		}
+
+		if s := os.Getenv("DIGEST_TIME"); s != "" {
+			if digestTime, err = parseTimeOfDay(s); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: invalid DIGEST_TIME: %v\n", err)
+				os.Exit(1)
+			}
+		}
+	}
+
 	if bindAddr == "" {
 		bindAddr = ":8080"
 	}
@@ -49,7 +130,59 @@ func main() {
 		fmt.Fprintf(os.Stderr, "Loaded KSEI account: %s\n", name)
 	}
 
-	mcpServer := server.NewMCP(kseiAccounts, kseiPlainPassword, kseiAuthCacheDir)
+	for name, path := range manualAccounts {
+		fmt.Fprintf(os.Stderr, "Loaded manual account: %s (%s)\n", name, path)
+	}
+
+	for name, account := range brokerAccounts {
+		fmt.Fprintf(os.Stderr, "Loaded broker account: %s (%s)\n", name, account.Path)
+	}
+
+	mcpServer, err := server.NewMCP(server.Options{
+		KseiAccounts:       kseiAccounts,
+		KseiPlainPassword:  kseiPlainPassword,
+		KseiAuthCacheDir:   kseiAuthCacheDir,
+		ManualAccounts:     manualAccounts,
+		BrokerAccounts:     brokerAccounts,
+		BrokerProfilesFile: brokerProfilesFile,
+		DataDir:            dataDir,
+		Ledger:             ledgerOpts,
+		RebalanceTargets:   rebalanceTargets,
+		RebalanceTolerance: rebalanceTolerance,
+		TransactionsFile:   os.Getenv("TRANSACTIONS_FILE"),
+		IncomeFile:         os.Getenv("INCOME_FILE"),
+		BondsFile:          os.Getenv("BONDS_FILE"),
+		ClassificationFile: os.Getenv("CLASSIFICATION_FILE"),
+		Benchmarks:         parseKeyValues(os.Getenv("BENCHMARKS")),
+		AccountTags:        parseTags(os.Getenv("ACCOUNT_TAGS")),
+		HoldingTags:        parseTags(os.Getenv("HOLDING_TAGS")),
+		AlertWebhookURL:    alertWebhookURL,
+		AlertRules:         alertRules,
+		Notifiers:          notifiers,
+		DigestTopMovers:    parseInt(os.Getenv("DIGEST_TOP_MOVERS"), digest.DefaultTopMovers),
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if fetchInterval > 0 && serving {
+		fmt.Fprintf(os.Stderr, "Fetching balances in background every %s\n", fetchInterval)
+
+		go mcpServer.RunFetcher(ctx, fetchInterval)
+	} else if alertWebhookURL != "" && serving {
+		fmt.Fprintf(os.Stderr, "Warning: ALERT_WEBHOOK_URL is set but alerts are only evaluated by background fetches, set FETCH_INTERVAL to enable them\n")
+	}
+
+	if os.Getenv("DIGEST_TIME") != "" && serving {
+		if len(notifiers) == 0 {
+			fmt.Fprintf(os.Stderr, "Warning: DIGEST_TIME is set but no notifier is configured, set SLACK_WEBHOOK_URL or TELEGRAM_BOT_TOKEN to receive the digest\n")
+		} else {
+			fmt.Fprintf(os.Stderr, "Sending daily digest at %s\n", os.Getenv("DIGEST_TIME"))
+
+			go mcpServer.RunDigest(ctx, digestTime)
+		}
+	}
 
 	switch command {
 	case "mcp-http":
@@ -64,6 +197,11 @@ func main() {
 			fmt.Fprintf(os.Stderr, "Error running MCP server: %v\n", err)
 			os.Exit(1)
 		}
+	case "export":
+		if err := runExport(ctx, mcpServer, os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error exporting portfolio: %v\n", err)
+			os.Exit(1)
+		}
 	default:
 		fmt.Fprintf(os.Stderr, "Error: Unknown command %s\n", command)
 		os.Exit(1)
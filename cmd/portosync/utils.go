@@ -1,9 +1,14 @@
 package main
 
 import (
+	"cmp"
+	"fmt"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/chickenzord/portosync/internal/server"
+	"github.com/chickenzord/portosync/internal/source"
 )
 
 // parseKseiAccountsWithName parses a string of KSEI accounts in the format
@@ -31,3 +36,102 @@ func parseKseiAccountsWithName(s string) map[string]server.Account {
 
 	return accounts
 }
+
+// parseKeyValues parses a string in the format "key=value,key2=value2" into a map.
+// Entries without "=" or with an empty key are ignored.
+func parseKeyValues(s string) map[string]string {
+	values := make(map[string]string)
+
+	for entry := range strings.SplitSeq(s, ",") {
+		key, value, ok := strings.Cut(entry, "=")
+		if !ok {
+			continue
+		}
+
+		if key = strings.TrimSpace(key); key != "" {
+			values[key] = strings.TrimSpace(value)
+		}
+	}
+
+	return values
+}
+
+// parseTags parses a string in the format "key=tag1;tag2,key2=tag3" into a map of keys to tags.
+// Entries without "=" or with an empty key are ignored.
+func parseTags(s string) map[string][]string {
+	tags := make(map[string][]string)
+
+	for key, value := range parseKeyValues(s) {
+		tags[key] = strings.Split(value, ";")
+	}
+
+	return tags
+}
+
+// parseBrokerAccounts parses a string of broker CSV accounts in the format
+// "name:profile:path,name2:profile2:path2" and returns a map of account names
+// to BrokerAccount structs. The path may contain colons.
+func parseBrokerAccounts(s string) map[string]server.BrokerAccount {
+	accounts := make(map[string]server.BrokerAccount)
+
+	for entry := range strings.SplitSeq(s, ",") {
+		parts := strings.SplitN(entry, ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+
+		name := strings.TrimSpace(parts[0])
+		profile := strings.TrimSpace(parts[1])
+		path := strings.TrimSpace(parts[2])
+
+		if name != "" && path != "" {
+			accounts[name] = server.BrokerAccount{
+				Profile: cmp.Or(profile, source.DefaultBrokerProfileName),
+				Path:    path,
+			}
+		}
+	}
+
+	return accounts
+}
+
+// parseDuration parses a duration such as "6h" or "30m",
+// returning zero for empty or invalid values
+func parseDuration(s string) time.Duration {
+	d, err := time.ParseDuration(strings.TrimSpace(s))
+	if err != nil || d < 0 {
+		return 0
+	}
+
+	return d
+}
+
+// parseFloat parses a decimal number, returning fallback for empty or invalid values
+func parseFloat(s string, fallback float64) float64 {
+	f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil {
+		return fallback
+	}
+
+	return f
+}
+
+// parseInt parses an integer, returning fallback for empty or invalid values
+func parseInt(s string, fallback int) int {
+	i, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil {
+		return fallback
+	}
+
+	return i
+}
+
+// parseTimeOfDay parses a time of day in the format "HH:MM" as a duration since midnight
+func parseTimeOfDay(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day %q, expected HH:MM", s)
+	}
+
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
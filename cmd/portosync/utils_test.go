@@ -2,9 +2,11 @@ package main
 
 import (
 	"testing"
+	"time"
 
 	"github.com/chickenzord/portosync/internal/server"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestParseKseiAccountsWithName(t *testing.T) {
@@ -160,3 +162,143 @@ func TestParseKseiAccountsWithName_EmptyResult(t *testing.T) {
 	assert.NotNil(t, result)
 	assert.Empty(t, result)
 }
+
+func TestParseKeyValues(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected map[string]string
+	}{
+		{
+			name:     "multiple entries",
+			input:    "personal=Personal:Bibit, business = Company",
+			expected: map[string]string{"personal": "Personal:Bibit", "business": "Company"},
+		},
+		{
+			name:     "value with equal sign",
+			input:    "key=a=b",
+			expected: map[string]string{"key": "a=b"},
+		},
+		{
+			name:     "empty value",
+			input:    "key=",
+			expected: map[string]string{"key": ""},
+		},
+		{
+			name:     "invalid entries",
+			input:    "novalue,=empty,,",
+			expected: map[string]string{},
+		},
+		{
+			name:     "empty string",
+			input:    "",
+			expected: map[string]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, parseKeyValues(tt.input))
+		})
+	}
+}
+
+func TestParseTags(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected map[string][]string
+	}{
+		{
+			name:     "multiple entries",
+			input:    "personal=retirement;joint, BBCA=core,kids/TLKM=dividend",
+			expected: map[string][]string{"personal": {"retirement", "joint"}, "BBCA": {"core"}, "kids/TLKM": {"dividend"}},
+		},
+		{
+			name:     "invalid entries",
+			input:    "notags,=empty,,",
+			expected: map[string][]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, parseTags(tt.input))
+		})
+	}
+}
+
+func TestParseBrokerAccounts(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected map[string]server.BrokerAccount
+	}{
+		{
+			name:  "multiple accounts",
+			input: "ibkr:ibkr:/data/ibkr, stockbit : default : /data/stockbit.csv",
+			expected: map[string]server.BrokerAccount{
+				"ibkr":     {Profile: "ibkr", Path: "/data/ibkr"},
+				"stockbit": {Profile: "default", Path: "/data/stockbit.csv"},
+			},
+		},
+		{
+			name:  "empty profile uses default",
+			input: "stockbit::/data/stockbit.csv",
+			expected: map[string]server.BrokerAccount{
+				"stockbit": {Profile: "default", Path: "/data/stockbit.csv"},
+			},
+		},
+		{
+			name:  "path with colon",
+			input: "ibkr:ibkr:C:/data/ibkr",
+			expected: map[string]server.BrokerAccount{
+				"ibkr": {Profile: "ibkr", Path: "C:/data/ibkr"},
+			},
+		},
+		{
+			name:     "invalid entries",
+			input:    "ibkr:ibkr,:default:/data,ibkr:ibkr:",
+			expected: map[string]server.BrokerAccount{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, parseBrokerAccounts(tt.input))
+		})
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	assert.Equal(t, 6*time.Hour, parseDuration("6h"))
+	assert.Equal(t, 90*time.Minute, parseDuration(" 1h30m "))
+	assert.Equal(t, time.Duration(0), parseDuration(""))
+	assert.Equal(t, time.Duration(0), parseDuration("daily"))
+	assert.Equal(t, time.Duration(0), parseDuration("-1h"))
+}
+
+func TestParseTimeOfDay(t *testing.T) {
+	d, err := parseTimeOfDay(" 07:30 ")
+	require.NoError(t, err)
+	assert.Equal(t, 7*time.Hour+30*time.Minute, d)
+
+	_, err = parseTimeOfDay("25:00")
+	require.Error(t, err)
+
+	_, err = parseTimeOfDay("7am")
+	require.Error(t, err)
+}
+
+func TestParseInt(t *testing.T) {
+	assert.Equal(t, 3, parseInt(" 3 ", 5))
+	assert.Equal(t, 5, parseInt("", 5))
+	assert.Equal(t, 5, parseInt("three", 5))
+}
+
+func TestParseFloat(t *testing.T) {
+	assert.Equal(t, 2.5, parseFloat(" 2.5 ", 5))
+	assert.Equal(t, 0.0, parseFloat("0", 5))
+	assert.Equal(t, 5.0, parseFloat("", 5))
+	assert.Equal(t, 5.0, parseFloat("five", 5))
+}
@@ -0,0 +1,240 @@
+// Package alert evaluates rules against consecutive background fetches and delivers the alerts they trigger
+package alert
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/chickenzord/portosync/internal/portfolio"
+)
+
+// Alert kinds
+const (
+	KindValueDrop    = "value_drop"    // total value in a currency dropped since the previous day
+	KindNewAsset     = "new_asset"     // a holding appeared since the previous fetch
+	KindHoldingGone  = "holding_gone"  // a holding disappeared since the previous fetch
+	KindFetchFailing = "fetch_failing" // fetches kept failing for longer than allowed
+)
+
+// Default thresholds of DefaultRules
+const (
+	DefaultValueDrop    = 5.0
+	DefaultFetchFailing = 24 * time.Hour
+)
+
+// Alert is a triggered rule
+type Alert struct {
+	Kind          string    `json:"kind"`
+	Time          time.Time `json:"time"`
+	Message       string    `json:"message"`
+	Account       string    `json:"account,omitempty"`
+	Symbol        string    `json:"symbol,omitempty"`
+	Currency      string    `json:"currency,omitempty"`
+	Value         float64   `json:"value,omitempty"`
+	Previous      float64   `json:"previous,omitempty"`
+	ChangePercent float64   `json:"change_percent,omitempty"`
+}
+
+// Rules selects which alerts are raised
+type Rules struct {
+	ValueDrop      float64       // percent drop of the total value per currency since the previous day, 0 disables
+	HoldingChanges bool          // alert on holdings appearing or disappearing between fetches
+	FetchFailing   time.Duration // how long fetches may keep failing before alerting, 0 disables
+}
+
+// DefaultRules enables all rules with default thresholds
+func DefaultRules() Rules {
+	return Rules{
+		ValueDrop:      DefaultValueDrop,
+		HoldingChanges: true,
+		FetchFailing:   DefaultFetchFailing,
+	}
+}
+
+// holding identifies a non-cash balance between fetches
+type holding struct {
+	account, assetType, symbol string
+}
+
+// Evaluator keeps the state of previous fetches needed to evaluate rules. It is safe for concurrent use.
+type Evaluator struct {
+	rules Rules
+
+	mu           sync.Mutex
+	day          time.Time          // date of the latest fetch
+	dayTotals    map[string]float64 // total value per currency at the latest fetch
+	previousDay  map[string]float64 // total value per currency at the last fetch of the day before
+	dropAlerted  map[string]bool    // currencies alerted for a value drop on the current day
+	holdings     map[holding]portfolio.Balance
+	failingSince time.Time // time of the first failure since the latest successful fetch
+	failAlerted  bool
+}
+
+// NewEvaluator returns an evaluator of the rules without any previous fetch
+func NewEvaluator(rules Rules) *Evaluator {
+	return &Evaluator{rules: rules}
+}
+
+// Seed records balances known from an earlier day, such as a stored snapshot,
+// as the previous fetch without raising alerts
+func (e *Evaluator) Seed(date time.Time, balances []portfolio.Balance) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.day = portfolio.DateOf(date)
+	e.dayTotals = totals(balances)
+	e.dropAlerted = make(map[string]bool)
+	e.holdings = holdings(balances)
+}
+
+// Fetched evaluates the rules against balances of all accounts fetched at the time
+// and returns the triggered alerts. Value drops are compared with the last fetch of the
+// previous day and raised once per currency and day; holding changes with the previous fetch.
+func (e *Evaluator) Fetched(at time.Time, balances []portfolio.Balance) []Alert {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.failingSince, e.failAlerted = time.Time{}, false
+
+	if day := portfolio.DateOf(at); !day.Equal(e.day) {
+		if !e.day.IsZero() {
+			e.previousDay = e.dayTotals
+		}
+
+		e.day = day
+		e.dropAlerted = make(map[string]bool)
+	}
+
+	e.dayTotals = totals(balances)
+
+	var alerts []Alert
+
+	if e.rules.ValueDrop > 0 {
+		for _, currency := range slices.Sorted(maps.Keys(e.previousDay)) {
+			previous, value := e.previousDay[currency], e.dayTotals[currency]
+			if previous <= 0 || e.dropAlerted[currency] {
+				continue
+			}
+
+			if change := (value - previous) * 100 / previous; -change > e.rules.ValueDrop {
+				e.dropAlerted[currency] = true
+				alerts = append(alerts, Alert{
+					Kind:          KindValueDrop,
+					Time:          at,
+					Message:       fmt.Sprintf("Total %s value dropped %.2f%% since the previous day, from %.2f to %.2f", currency, -change, previous, value),
+					Currency:      currency,
+					Value:         value,
+					Previous:      previous,
+					ChangePercent: change,
+				})
+			}
+		}
+	}
+
+	current := holdings(balances)
+
+	if e.rules.HoldingChanges && e.holdings != nil {
+		for _, h := range sortedHoldings(current) {
+			if _, ok := e.holdings[h]; !ok {
+				b := current[h]
+				alerts = append(alerts, Alert{
+					Kind:     KindNewAsset,
+					Time:     at,
+					Message:  fmt.Sprintf("New %s holding %s in account %s worth %s %.2f", b.AssetType, b.AssetSymbol, b.SourceAccount, b.UnitsCurrency, b.UnitsValue),
+					Account:  b.SourceAccount,
+					Symbol:   b.AssetSymbol,
+					Currency: b.UnitsCurrency,
+					Value:    b.UnitsValue,
+				})
+			}
+		}
+
+		for _, h := range sortedHoldings(e.holdings) {
+			if _, ok := current[h]; !ok {
+				b := e.holdings[h]
+				alerts = append(alerts, Alert{
+					Kind:     KindHoldingGone,
+					Time:     at,
+					Message:  fmt.Sprintf("Holding %s in account %s disappeared, it was worth %s %.2f", b.AssetSymbol, b.SourceAccount, b.UnitsCurrency, b.UnitsValue),
+					Account:  b.SourceAccount,
+					Symbol:   b.AssetSymbol,
+					Currency: b.UnitsCurrency,
+					Previous: b.UnitsValue,
+				})
+			}
+		}
+	}
+
+	e.holdings = current
+
+	return alerts
+}
+
+// Failed records a failed fetch at the time and returns an alert once fetches
+// have kept failing for longer than allowed, until the next successful fetch
+func (e *Evaluator) Failed(at time.Time, err error) []Alert {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.failingSince.IsZero() {
+		e.failingSince = at
+	}
+
+	if e.rules.FetchFailing <= 0 || e.failAlerted || at.Sub(e.failingSince) < e.rules.FetchFailing {
+		return nil
+	}
+
+	e.failAlerted = true
+
+	return []Alert{{
+		Kind:    KindFetchFailing,
+		Time:    at,
+		Message: fmt.Sprintf("Fetching balances has been failing since %s: %v", e.failingSince.Format(time.RFC3339), err),
+	}}
+}
+
+// totals returns the total value of balances per currency
+func totals(balances []portfolio.Balance) map[string]float64 {
+	result := make(map[string]float64)
+	for _, t := range portfolio.Totals(balances) {
+		result[t.Currency] = t.TotalValue
+	}
+
+	return result
+}
+
+// holdings returns the non-cash balances by holding, summing balances of the same holding in several sub-accounts
+func holdings(balances []portfolio.Balance) map[holding]portfolio.Balance {
+	result := make(map[holding]portfolio.Balance)
+
+	for _, b := range balances {
+		if b.IsCash() {
+			continue
+		}
+
+		h := holding{account: b.SourceAccount, assetType: b.AssetType, symbol: b.AssetSymbol}
+		if existing, ok := result[h]; ok {
+			existing.UnitsAmount += b.UnitsAmount
+			existing.UnitsValue += b.UnitsValue
+			b = existing
+		}
+
+		result[h] = b
+	}
+
+	return result
+}
+
+func sortedHoldings(m map[holding]portfolio.Balance) []holding {
+	return slices.SortedFunc(maps.Keys(m), func(a, b holding) int {
+		return cmp.Or(
+			cmp.Compare(a.account, b.account),
+			cmp.Compare(a.assetType, b.assetType),
+			cmp.Compare(a.symbol, b.symbol),
+		)
+	})
+}
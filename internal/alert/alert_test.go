@@ -0,0 +1,104 @@
+package alert
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/chickenzord/portosync/internal/portfolio"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func at(s string) time.Time {
+	t, err := time.ParseInLocation("2006-01-02 15:04", s, time.Local)
+	if err != nil {
+		panic(err)
+	}
+
+	return t
+}
+
+func kinds(alerts []Alert) []string {
+	var result []string
+	for _, a := range alerts {
+		result = append(result, a.Kind)
+	}
+
+	return result
+}
+
+func TestEvaluator_Fetched(t *testing.T) {
+	e := NewEvaluator(DefaultRules())
+
+	day1 := []portfolio.Balance{
+		{SourceAccount: "personal", AssetSymbol: "BBCA", AssetType: "equity", UnitsValue: 900, UnitsCurrency: "IDR"},
+		{SourceAccount: "personal", AssetSymbol: "IDR", AssetType: "cash", UnitsValue: 100, UnitsCurrency: "IDR"},
+		{SourceAccount: "broker", AssetSymbol: "AAPL", AssetType: "equity", UnitsValue: 10, UnitsCurrency: "USD"},
+	}
+	assert.Empty(t, e.Fetched(at("2024-01-01 08:00"), day1), "the first fetch is the baseline")
+	assert.Empty(t, e.Fetched(at("2024-01-01 14:00"), day1))
+
+	// Cash changes are neither new nor gone holdings
+	day2 := []portfolio.Balance{
+		{SourceAccount: "personal", AssetSymbol: "BBCA", AssetType: "equity", UnitsValue: 800, UnitsCurrency: "IDR"},
+		{SourceAccount: "personal", AssetSymbol: "TLKM", AssetType: "equity", UnitsValue: 30, UnitsCurrency: "IDR"},
+		{SourceAccount: "broker", AssetSymbol: "AAPL", AssetType: "equity", UnitsValue: 10, UnitsCurrency: "USD"},
+	}
+	alerts := e.Fetched(at("2024-01-02 08:00"), day2)
+	require.Equal(t, []string{KindValueDrop, KindNewAsset}, kinds(alerts))
+	assert.Equal(t, "IDR", alerts[0].Currency)
+	assert.Equal(t, 1000.0, alerts[0].Previous)
+	assert.Equal(t, 830.0, alerts[0].Value)
+	assert.InDelta(t, -17, alerts[0].ChangePercent, 1e-9)
+	assert.Equal(t, "TLKM", alerts[1].Symbol)
+	assert.Equal(t, "New equity holding TLKM in account personal worth IDR 30.00", alerts[1].Message)
+
+	// A drop is raised once per day and currency, holdings are compared with the previous fetch
+	alerts = e.Fetched(at("2024-01-02 14:00"), day2[:2])
+	require.Equal(t, []string{KindValueDrop, KindHoldingGone}, kinds(alerts))
+	assert.Equal(t, "USD", alerts[0].Currency)
+	assert.Equal(t, "broker", alerts[1].Account)
+	assert.Equal(t, 10.0, alerts[1].Previous)
+	assert.Empty(t, e.Fetched(at("2024-01-02 20:00"), day2[:2]))
+
+	// The next day compares with the last fetch of the day before
+	assert.Empty(t, e.Fetched(at("2024-01-03 08:00"), day2[:2]))
+}
+
+func TestEvaluator_Seed(t *testing.T) {
+	e := NewEvaluator(Rules{ValueDrop: 10})
+	e.Seed(at("2024-01-01 00:00"), []portfolio.Balance{
+		{SourceAccount: "personal", AssetSymbol: "BBCA", AssetType: "equity", UnitsValue: 1000, UnitsCurrency: "IDR"},
+	})
+
+	alerts := e.Fetched(at("2024-01-02 08:00"), []portfolio.Balance{
+		{SourceAccount: "personal", AssetSymbol: "GOTO", AssetType: "equity", UnitsValue: 950, UnitsCurrency: "IDR"},
+	})
+	assert.Empty(t, alerts, "holding changes are disabled and the drop is below the threshold")
+
+	alerts = e.Fetched(at("2024-01-02 12:00"), []portfolio.Balance{
+		{SourceAccount: "personal", AssetSymbol: "GOTO", AssetType: "equity", UnitsValue: 850, UnitsCurrency: "IDR"},
+	})
+	assert.Equal(t, []string{KindValueDrop}, kinds(alerts))
+}
+
+func TestEvaluator_Failed(t *testing.T) {
+	e := NewEvaluator(DefaultRules())
+	err := errors.New("connection refused")
+
+	assert.Empty(t, e.Failed(at("2024-01-01 00:00"), err))
+	assert.Empty(t, e.Failed(at("2024-01-01 23:00"), err))
+
+	alerts := e.Failed(at("2024-01-02 00:00"), err)
+	require.Len(t, alerts, 1)
+	assert.Equal(t, KindFetchFailing, alerts[0].Kind)
+	assert.Contains(t, alerts[0].Message, "connection refused")
+	assert.Empty(t, e.Failed(at("2024-01-02 06:00"), err), "raised once per failing streak")
+
+	e.Fetched(at("2024-01-02 12:00"), nil)
+	assert.Empty(t, e.Failed(at("2024-01-02 18:00"), err))
+	assert.Len(t, e.Failed(at("2024-01-03 18:00"), err), 1)
+
+	assert.Empty(t, NewEvaluator(Rules{}).Failed(at("2024-01-05 00:00"), err))
+}
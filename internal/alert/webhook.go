@@ -0,0 +1,30 @@
+package alert
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/chickenzord/portosync/internal/notify"
+)
+
+// Sender delivers alerts
+type Sender interface {
+	Send(ctx context.Context, alerts []Alert) error
+}
+
+// Payload is the JSON body posted by Webhook
+type Payload struct {
+	Source string  `json:"source"`
+	Alerts []Alert `json:"alerts"`
+}
+
+// Webhook posts alerts as a JSON Payload to a URL
+type Webhook struct {
+	URL    string
+	Client *http.Client // a client with a 30 second timeout when nil
+}
+
+// Send posts the alerts in one request, failing on non-2xx responses
+func (w Webhook) Send(ctx context.Context, alerts []Alert) error {
+	return notify.PostJSON(ctx, w.Client, w.URL, Payload{Source: "portosync", Alerts: alerts})
+}
@@ -0,0 +1,63 @@
+package alert
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhook_Send(t *testing.T) {
+	var received Payload
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	alerts := []Alert{{Kind: KindNewAsset, Time: at("2024-01-02 08:00"), Message: "New equity holding", Account: "personal", Symbol: "TLKM"}}
+
+	require.NoError(t, Webhook{URL: server.URL}.Send(context.Background(), alerts))
+	assert.Equal(t, "portosync", received.Source)
+	require.Len(t, received.Alerts, 1)
+	assert.Equal(t, "TLKM", received.Alerts[0].Symbol)
+	assert.True(t, alerts[0].Time.Equal(received.Alerts[0].Time))
+}
+
+func TestWebhook_Send_Error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "bad token", http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	err := Webhook{URL: server.URL, Client: server.Client()}.Send(context.Background(), nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "401")
+	assert.Contains(t, err.Error(), "bad token")
+}
+
+func TestWebhook_Send_RedactsURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "gone", http.StatusNotFound)
+	}))
+
+	url := server.URL + "/hooks/T000/B000/secret-token"
+
+	err := Webhook{URL: url}.Send(context.Background(), nil)
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), "secret-token")
+
+	server.Close()
+
+	err = Webhook{URL: url}.Send(context.Background(), nil)
+	require.Error(t, err, "connection refused")
+	assert.NotContains(t, err.Error(), "secret-token")
+	assert.NotContains(t, err.Error(), "/hooks/")
+}
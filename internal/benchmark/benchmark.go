@@ -0,0 +1,162 @@
+// Package benchmark loads index and rate series used to compare portfolio performance against
+package benchmark
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chickenzord/portosync/internal/datafile"
+	"github.com/chickenzord/portosync/internal/portfolio"
+)
+
+// Column names recognised as index levels, in order of preference
+var levelColumns = []string{"close", "value", "price", "level", "nav"}
+
+// rateColumn holds an annual rate in percent, e.g. a deposit rate, compounded daily into an index
+const rateColumn = "rate"
+
+// rateIndexBase is the first value of indices built from rates
+const rateIndexBase = 100
+
+// Point is a benchmark value at a date
+type Point struct {
+	Date  time.Time
+	Value float64
+}
+
+// Series is a benchmark ordered by date
+type Series struct {
+	Name   string
+	Points []Point
+
+	rates []Point // annual rates the points were compounded from, nil for index levels
+}
+
+// Load reads a series from a CSV file with a date column and either an index level column
+// (close, value, price, level or nav) or a rate column holding an annual rate in percent
+func Load(name, path string) (Series, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Series{}, err
+	}
+	defer f.Close()
+
+	series, err := Read(name, f)
+	if err != nil {
+		return Series{}, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return series, nil
+}
+
+// Read reads a series in the CSV format described in Load
+func Read(name string, r io.Reader) (Series, error) {
+	columns, records, err := datafile.ReadCSV(r, ',')
+	if err != nil {
+		return Series{}, err
+	}
+
+	if len(records) == 0 {
+		return Series{}, errors.New("no data rows")
+	}
+
+	if err := datafile.RequireColumns(columns, "date"); err != nil {
+		return Series{}, err
+	}
+
+	valueColumn, isRate := "", false
+
+	for _, c := range levelColumns {
+		if slices.Contains(columns, c) {
+			valueColumn = c
+
+			break
+		}
+	}
+
+	if valueColumn == "" && slices.Contains(columns, rateColumn) {
+		valueColumn, isRate = rateColumn, true
+	}
+
+	if valueColumn == "" {
+		return Series{}, fmt.Errorf("missing value column, expected one of %s or %s", strings.Join(levelColumns, ", "), rateColumn)
+	}
+
+	series := Series{Name: name}
+
+	for i, record := range records {
+		raw, ok := record[valueColumn]
+		if !ok || record.Get("date") == "" {
+			continue
+		}
+
+		date, err := portfolio.ParseDate(record.Get("date"))
+		if err != nil {
+			return Series{}, fmt.Errorf("row %d: invalid date: %w", i+2, err)
+		}
+
+		value, err := strconv.ParseFloat(strings.ReplaceAll(raw, ",", ""), 64)
+		if err != nil {
+			return Series{}, fmt.Errorf("row %d: invalid value: %w", i+2, err)
+		}
+
+		series.Points = append(series.Points, Point{Date: date, Value: value})
+	}
+
+	slices.SortStableFunc(series.Points, func(a, b Point) int { return a.Date.Compare(b.Date) })
+
+	if isRate {
+		series.rates = series.Points
+		series.Points = compound(series.rates)
+	}
+
+	return series, nil
+}
+
+// compound turns annual rates, each valid from its date until the next one, into a daily compounded index
+func compound(rates []Point) []Point {
+	index := make([]Point, len(rates))
+
+	value := float64(rateIndexBase)
+
+	for i, r := range rates {
+		if i > 0 {
+			days := r.Date.Sub(rates[i-1].Date).Hours() / 24
+			value *= math.Pow(1+rates[i-1].Value/100, days/365)
+		}
+
+		index[i] = Point{Date: r.Date, Value: value}
+	}
+
+	return index
+}
+
+// At returns the value at the date, reporting false for dates before the series starts.
+// Index levels carry the last value on or before the date, such as the close before a holiday;
+// rate-based indices keep compounding the last rate.
+func (s Series) At(date time.Time) (float64, bool) {
+	i, found := slices.BinarySearchFunc(s.Points, date, func(p Point, d time.Time) int { return p.Date.Compare(d) })
+	if !found {
+		if i == 0 {
+			return 0, false
+		}
+
+		i--
+	}
+
+	value := s.Points[i].Value
+
+	if s.rates != nil {
+		days := date.Sub(s.Points[i].Date).Hours() / 24
+		value *= math.Pow(1+s.rates[i].Value/100, days/365)
+	}
+
+	return value, true
+}
@@ -0,0 +1,102 @@
+package benchmark
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chickenzord/portosync/internal/portfolio"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func date(s string) time.Time {
+	d, err := portfolio.ParseDate(s)
+	if err != nil {
+		panic(err)
+	}
+
+	return d
+}
+
+func TestRead_Levels(t *testing.T) {
+	series, err := Read("ihsg", strings.NewReader(`Date,Open,Close
+2024-01-03,7300,"7,350.5"
+2024-01-02,7250,7300
+,,
+`))
+	require.NoError(t, err)
+	assert.Equal(t, "ihsg", series.Name)
+	assert.Equal(t, []Point{{Date: date("2024-01-02"), Value: 7300}, {Date: date("2024-01-03"), Value: 7350.5}}, series.Points)
+
+	_, ok := series.At(date("2024-01-01"))
+	assert.False(t, ok)
+
+	value, ok := series.At(date("2024-01-02"))
+	assert.True(t, ok)
+	assert.Equal(t, 7300.0, value)
+
+	value, ok = series.At(date("2024-01-06"))
+	assert.True(t, ok)
+	assert.Equal(t, 7350.5, value, "carries the last close")
+}
+
+func TestRead_Rates(t *testing.T) {
+	series, err := Read("deposit", strings.NewReader(`date,rate
+2023-01-01,4
+2024-01-01,5
+`))
+	require.NoError(t, err)
+	require.Len(t, series.Points, 2)
+	assert.Equal(t, 100.0, series.Points[0].Value)
+	assert.InDelta(t, 104.0, series.Points[1].Value, 1e-9)
+
+	value, ok := series.At(date("2024-12-31"))
+	assert.True(t, ok)
+	assert.InDelta(t, 104*1.05, value, 1e-9, "compounds the last rate")
+}
+
+func TestRead_Errors(t *testing.T) {
+	tests := []struct {
+		content string
+		err     string
+	}{
+		{content: "date,close\n", err: "no data rows"},
+		{content: "day,close\n2024-01-01,1\n", err: `missing column "date"`},
+		{content: "date,volume\n2024-01-01,1\n", err: "missing value column"},
+		{content: "date,close\n01/01/2024,1\n", err: "row 2: invalid date"},
+		{content: "date,close\n2024-01-01,n/a\n", err: "row 2: invalid value"},
+	}
+
+	for _, tt := range tests {
+		_, err := Read("b", strings.NewReader(tt.content))
+		assert.ErrorContains(t, err, tt.err)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	series := Series{Name: "ihsg", Points: []Point{
+		{Date: date("2024-01-02"), Value: 7000},
+		{Date: date("2024-01-04"), Value: 7350},
+	}}
+
+	comparison, ok := Compare([]Point{
+		{Date: date("2024-01-01"), Value: 100},
+		{Date: date("2024-01-02"), Value: 110},
+		{Date: date("2024-01-03"), Value: 121},
+		{Date: date("2024-01-05"), Value: 99},
+	}, series)
+	require.True(t, ok)
+
+	assert.Equal(t, "2024-01-02", comparison.From)
+	assert.Equal(t, "2024-01-05", comparison.To)
+	assert.InDelta(t, -10.0, comparison.PortfolioReturn, 1e-9)
+	assert.InDelta(t, 5.0, comparison.BenchmarkReturn, 1e-9)
+	assert.InDelta(t, -15.0, comparison.Excess, 1e-9)
+	require.Len(t, comparison.Points, 3)
+	assert.Equal(t, ComparisonPoint{Date: "2024-01-02", Portfolio: 100, Benchmark: 100, Relative: 100}, comparison.Points[0])
+	assert.InDelta(t, 110.0, comparison.Points[1].Relative, 1e-9)
+
+	_, ok = Compare([]Point{{Date: date("2024-01-01"), Value: 100}}, series)
+	assert.False(t, ok)
+}
@@ -0,0 +1,70 @@
+package benchmark
+
+import (
+	"time"
+)
+
+// Comparison lines up portfolio growth against a benchmark over the dates both are known.
+// Returns are percentages.
+type Comparison struct {
+	Benchmark       string            `json:"benchmark"        jsonschema:"description:Benchmark name"`
+	From            string            `json:"from,omitempty"   jsonschema:"description:First date both the portfolio and the benchmark are known (YYYY-MM-DD)"`
+	To              string            `json:"to,omitempty"     jsonschema:"description:Last date both the portfolio and the benchmark are known (YYYY-MM-DD)"`
+	PortfolioReturn float64           `json:"portfolio_return" jsonschema:"description:Time-weighted portfolio return over the period in percent"`
+	BenchmarkReturn float64           `json:"benchmark_return" jsonschema:"description:Benchmark return over the period in percent"`
+	Excess          float64           `json:"excess"           jsonschema:"description:Portfolio return minus benchmark return in percentage points, positive when the portfolio beat the benchmark"`
+	Points          []ComparisonPoint `json:"points"           jsonschema:"description:Portfolio and benchmark rebased to 100 at the first date, at every snapshot date"`
+}
+
+// ComparisonPoint is the rebased portfolio and benchmark value at a date
+type ComparisonPoint struct {
+	Date      string  `json:"date"      jsonschema:"description:Snapshot date (YYYY-MM-DD)"`
+	Portfolio float64 `json:"portfolio" jsonschema:"description:Portfolio growth rebased to 100"`
+	Benchmark float64 `json:"benchmark" jsonschema:"description:Benchmark rebased to 100"`
+	Relative  float64 `json:"relative"  jsonschema:"description:Portfolio divided by benchmark times 100, rising while the portfolio outperforms"`
+}
+
+// Compare lines up portfolio growth points, ordered by date, against the series.
+// It reports false when fewer than two dates are covered by both.
+func Compare(portfolioPoints []Point, s Series) (Comparison, bool) {
+	comparison := Comparison{Benchmark: s.Name}
+
+	var portfolioBase, benchmarkBase float64
+
+	for _, p := range portfolioPoints {
+		value, ok := s.At(p.Date)
+		if !ok || value == 0 {
+			continue
+		}
+
+		if portfolioBase == 0 {
+			if p.Value == 0 {
+				continue
+			}
+
+			portfolioBase, benchmarkBase = p.Value, value
+		}
+
+		point := ComparisonPoint{
+			Date:      p.Date.Format(time.DateOnly),
+			Portfolio: p.Value / portfolioBase * 100,
+			Benchmark: value / benchmarkBase * 100,
+		}
+		point.Relative = point.Portfolio / point.Benchmark * 100
+
+		comparison.Points = append(comparison.Points, point)
+	}
+
+	if len(comparison.Points) < 2 {
+		return comparison, false
+	}
+
+	first, last := comparison.Points[0], comparison.Points[len(comparison.Points)-1]
+	comparison.From = first.Date
+	comparison.To = last.Date
+	comparison.PortfolioReturn = last.Portfolio - 100
+	comparison.BenchmarkReturn = last.Benchmark - 100
+	comparison.Excess = comparison.PortfolioReturn - comparison.BenchmarkReturn
+
+	return comparison, true
+}
@@ -0,0 +1,209 @@
+// Package bond provides reference data of bonds, which KSEI balances only report by symbol and amount
+package bond
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chickenzord/portosync/internal/datafile"
+	"github.com/chickenzord/portosync/internal/portfolio"
+)
+
+const (
+	// defaultFaceValue is the face value per unit, as KSEI reports bond units as the face amount held
+	defaultFaceValue = 1
+
+	// defaultCouponFrequency is the number of coupon payments per year, semiannual like most government bonds
+	defaultCouponFrequency = 2
+
+	defaultCurrency = "IDR"
+
+	daysPerYear = 365.25
+)
+
+// Bond is the reference data of a bond
+type Bond struct {
+	Symbol          string    `json:"symbol"           jsonschema:"description:Bond series or symbol, e.g. FR0098"`
+	Name            string    `json:"name,omitempty"   jsonschema:"description:Bond name"`
+	CouponRate      float64   `json:"coupon_rate"      jsonschema:"description:Annual coupon rate in percent of face value"`
+	CouponFrequency int       `json:"coupon_frequency" jsonschema:"description:Number of coupon payments per year"`
+	MaturityDate    time.Time `json:"maturity_date"    jsonschema:"description:Date the face value is repaid"`
+	FaceValue       float64   `json:"face_value"       jsonschema:"description:Face value per unit held"`
+	Currency        string    `json:"currency"         jsonschema:"description:Currency of the face value"`
+}
+
+// YearsToMaturity returns the years left from date until maturity, zero once matured
+func (b Bond) YearsToMaturity(date time.Time) float64 {
+	return max(b.MaturityDate.Sub(portfolio.DateOf(date)).Hours()/24/daysPerYear, 0)
+}
+
+// CurrentYield returns the annual coupon in percent of the price per unit
+func (b Bond) CurrentYield(price float64) float64 {
+	if price <= 0 {
+		return 0
+	}
+
+	return b.CouponRate * b.FaceValue / price
+}
+
+// NextCoupon returns the first coupon date after date, counting back from maturity in equal periods.
+// It reports false once the bond has matured.
+func (b Bond) NextCoupon(date time.Time) (time.Time, bool) {
+	date = portfolio.DateOf(date)
+	if !b.MaturityDate.After(date) {
+		return time.Time{}, false
+	}
+
+	months := 12 / max(b.CouponFrequency, 1)
+	next := b.MaturityDate
+
+	for i := 1; ; i++ {
+		previous := b.MaturityDate.AddDate(0, -months*i, 0)
+		if !previous.After(date) {
+			return next, true
+		}
+
+		next = previous
+	}
+}
+
+// Catalogue is bond reference data by symbol
+type Catalogue map[string]Bond
+
+// Lookup returns the bond of a symbol
+func (c Catalogue) Lookup(symbol string) (Bond, bool) {
+	b, ok := c[strings.ToUpper(strings.TrimSpace(symbol))]
+
+	return b, ok
+}
+
+// Apply sets coupon, maturity and yield fields of bond balances found in the catalogue
+func (c Catalogue) Apply(balances []portfolio.Balance, date time.Time) {
+	for i := range balances {
+		b := &balances[i]
+		if b.AssetType != portfolio.AssetTypeBond {
+			continue
+		}
+
+		bond, ok := c.Lookup(b.AssetSymbol)
+		if !ok {
+			continue
+		}
+
+		b.CouponRate = bond.CouponRate
+		b.MaturityDate = bond.MaturityDate.Format(time.DateOnly)
+		b.YearsToMaturity = bond.YearsToMaturity(date)
+
+		if b.UnitsAmount > 0 {
+			b.CurrentYield = bond.CurrentYield(b.UnitsValue / b.UnitsAmount)
+		}
+
+		if b.AssetName == "" || b.AssetName == b.AssetSymbol {
+			b.AssetName = cmp.Or(bond.Name, b.AssetName)
+		}
+	}
+}
+
+// Load reads a catalogue from a CSV file with header columns symbol, coupon_rate, maturity_date
+// and optionally name, coupon_frequency (default 2), face_value (default 1) and currency (default IDR)
+func Load(path string) (Catalogue, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	catalogue, err := Read(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return catalogue, nil
+}
+
+// Read reads a catalogue in the CSV format described in Load
+func Read(r io.Reader) (Catalogue, error) {
+	columns, records, err := datafile.ReadCSV(r, ',')
+	if err != nil {
+		return nil, err
+	}
+
+	catalogue := make(Catalogue)
+
+	if len(columns) == 0 {
+		return catalogue, nil
+	}
+
+	if err := datafile.RequireColumns(columns, "symbol", "coupon_rate", "maturity_date"); err != nil {
+		return nil, err
+	}
+
+	for i, record := range records {
+		if record.Get("symbol") == "" {
+			continue
+		}
+
+		b, err := parseRow(record.Get)
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", i+2, err)
+		}
+
+		if _, ok := catalogue[b.Symbol]; ok {
+			return nil, fmt.Errorf("row %d: duplicate symbol %s", i+2, b.Symbol)
+		}
+
+		catalogue[b.Symbol] = b
+	}
+
+	return catalogue, nil
+}
+
+func parseRow(field func(name string) string) (Bond, error) {
+	maturity, err := portfolio.ParseDate(field("maturity_date"))
+	if err != nil {
+		return Bond{}, fmt.Errorf("invalid maturity_date: %w", err)
+	}
+
+	b := Bond{
+		Symbol:          strings.ToUpper(field("symbol")),
+		Name:            field("name"),
+		MaturityDate:    maturity,
+		FaceValue:       defaultFaceValue,
+		CouponFrequency: defaultCouponFrequency,
+		Currency:        strings.ToUpper(cmp.Or(field("currency"), defaultCurrency)),
+	}
+
+	if b.CouponRate, err = strconv.ParseFloat(strings.TrimSuffix(field("coupon_rate"), "%"), 64); err != nil {
+		return b, fmt.Errorf("invalid coupon_rate: %w", err)
+	}
+
+	if s := strings.ReplaceAll(field("face_value"), ",", ""); s != "" {
+		if b.FaceValue, err = strconv.ParseFloat(s, 64); err != nil {
+			return b, fmt.Errorf("invalid face_value: %w", err)
+		}
+	}
+
+	if s := field("coupon_frequency"); s != "" {
+		if b.CouponFrequency, err = strconv.Atoi(s); err != nil {
+			return b, fmt.Errorf("invalid coupon_frequency: %w", err)
+		}
+	}
+
+	switch {
+	case b.CouponRate < 0:
+		return b, errors.New("coupon_rate must not be negative")
+	case b.FaceValue <= 0:
+		return b, errors.New("face_value must be positive")
+	case !slices.Contains([]int{1, 2, 4, 12}, b.CouponFrequency):
+		return b, errors.New("coupon_frequency must be 1, 2, 4 or 12")
+	}
+
+	return b, nil
+}
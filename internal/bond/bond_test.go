@@ -0,0 +1,112 @@
+package bond
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chickenzord/portosync/internal/portfolio"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func date(s string) time.Time {
+	d, err := portfolio.ParseDate(s)
+	if err != nil {
+		panic(err)
+	}
+
+	return d
+}
+
+const catalogueCSV = `symbol,name,coupon_rate,maturity_date,coupon_frequency,face_value
+fr0098,Obligasi Negara FR0098,7.125%,2038-06-15,,
+ORI023T3,ORI023 Tenor 3,6.1,2026-07-15,12,
+`
+
+func TestRead(t *testing.T) {
+	catalogue, err := Read(strings.NewReader(catalogueCSV))
+	require.NoError(t, err)
+	require.Len(t, catalogue, 2)
+
+	fr, ok := catalogue.Lookup(" fr0098")
+	require.True(t, ok)
+	assert.Equal(t, Bond{
+		Symbol:          "FR0098",
+		Name:            "Obligasi Negara FR0098",
+		CouponRate:      7.125,
+		CouponFrequency: 2,
+		MaturityDate:    date("2038-06-15"),
+		FaceValue:       1,
+		Currency:        "IDR",
+	}, fr)
+
+	for _, input := range []string{
+		"symbol,coupon_rate\nFR0098,7\n",
+		"symbol,coupon_rate,maturity_date\nFR0098,abc,2038-06-15\n",
+		"symbol,coupon_rate,maturity_date\nFR0098,7,15/06/2038\n",
+		"symbol,coupon_rate,maturity_date,coupon_frequency\nFR0098,7,2038-06-15,3\n",
+		"symbol,coupon_rate,maturity_date,face_value\nFR0098,7,2038-06-15,0\n",
+		"symbol,coupon_rate,maturity_date\nFR0098,7,2038-06-15\nfr0098,7,2038-06-15\n",
+	} {
+		_, err := Read(strings.NewReader(input))
+		assert.Error(t, err, input)
+	}
+}
+
+func TestBond(t *testing.T) {
+	b := Bond{CouponRate: 6, CouponFrequency: 2, MaturityDate: date("2026-06-15"), FaceValue: 1}
+
+	assert.InDelta(t, 2.0, b.YearsToMaturity(date("2024-06-15")), 0.01)
+	assert.Zero(t, b.YearsToMaturity(date("2027-01-01")))
+	assert.InDelta(t, 6.0/0.96, b.CurrentYield(0.96), 1e-9)
+	assert.Zero(t, b.CurrentYield(0))
+
+	next, ok := b.NextCoupon(date("2024-07-01"))
+	require.True(t, ok)
+	assert.Equal(t, date("2024-12-15"), next)
+
+	next, ok = b.NextCoupon(date("2026-06-14"))
+	require.True(t, ok)
+	assert.Equal(t, date("2026-06-15"), next)
+
+	_, ok = b.NextCoupon(date("2026-06-15"))
+	assert.False(t, ok)
+}
+
+func TestCatalogue(t *testing.T) {
+	catalogue, err := Read(strings.NewReader(catalogueCSV))
+	require.NoError(t, err)
+
+	balances := []portfolio.Balance{
+		{SourceAccount: "personal", AssetSymbol: "FR0098", AssetType: "bond", UnitsAmount: 100000000, UnitsValue: 104000000, UnitsCurrency: "IDR"},
+		{SourceAccount: "personal", AssetSymbol: "ORI023T3", AssetName: "ORI023T3", AssetType: "bond", UnitsAmount: 50000000, UnitsValue: 50000000, UnitsCurrency: "IDR"},
+		{SourceAccount: "business", AssetSymbol: "PBS999", AssetType: "bond", UnitsAmount: 1000000, UnitsValue: 1000000, UnitsCurrency: "IDR"},
+		{SourceAccount: "personal", AssetSymbol: "BBCA", AssetType: "equity", UnitsAmount: 100, UnitsValue: 1000000, UnitsCurrency: "IDR"},
+	}
+
+	catalogue.Apply(balances, date("2026-01-15"))
+
+	assert.Equal(t, 7.125, balances[0].CouponRate)
+	assert.Equal(t, "2038-06-15", balances[0].MaturityDate)
+	assert.InDelta(t, 12.41, balances[0].YearsToMaturity, 0.01)
+	assert.InDelta(t, 7.125/1.04, balances[0].CurrentYield, 1e-9)
+	assert.Equal(t, "ORI023 Tenor 3", balances[1].AssetName)
+	assert.Empty(t, balances[2].MaturityDate)
+	assert.Zero(t, balances[3].CouponRate)
+	assert.Contains(t, balances[1].Description(), "coupon 6.100%, matures 2026-07-15")
+
+	maturities, unknown := catalogue.Upcoming(balances, date("2026-01-15"), date("2027-01-15"))
+	assert.Equal(t, []string{"PBS999"}, unknown)
+	require.Len(t, maturities, 1)
+
+	m := maturities[0]
+	assert.Equal(t, "ORI023T3", m.Symbol)
+	assert.Equal(t, 181, m.DaysToMaturity)
+	assert.Equal(t, 50000000.0, m.Principal)
+	assert.Equal(t, "2026-02-15", m.NextCouponDate)
+	assert.InDelta(t, 6*50000000*0.061/12, m.RemainingCoupons, 1e-6, "six monthly coupons left")
+
+	maturities, _ = catalogue.Upcoming(balances, date("2026-01-15"), date("2040-01-01"))
+	assert.Len(t, maturities, 2)
+}
@@ -0,0 +1,87 @@
+package bond
+
+import (
+	"cmp"
+	"slices"
+	"time"
+
+	"github.com/chickenzord/portosync/internal/portfolio"
+)
+
+// Maturity is a bond holding maturing within a period
+type Maturity struct {
+	Account          string  `json:"account"                 jsonschema:"description:Account name holding the bond"`
+	Symbol           string  `json:"symbol"                  jsonschema:"description:Bond series or symbol"`
+	Name             string  `json:"name,omitempty"          jsonschema:"description:Bond name"`
+	MaturityDate     string  `json:"maturity_date"           jsonschema:"description:Date the face value is repaid (YYYY-MM-DD)"`
+	DaysToMaturity   int     `json:"days_to_maturity"        jsonschema:"description:Days left until the maturity date"`
+	Principal        float64 `json:"principal"               jsonschema:"description:Face amount repaid at maturity"`
+	CurrentValue     float64 `json:"current_value"           jsonschema:"description:Current market value of the holding"`
+	Currency         string  `json:"currency"                jsonschema:"description:Currency of the principal and value"`
+	CouponRate       float64 `json:"coupon_rate"             jsonschema:"description:Annual coupon rate in percent of face value"`
+	NextCouponDate   string  `json:"next_coupon_date"        jsonschema:"description:Date of the next coupon payment (YYYY-MM-DD)"`
+	RemainingCoupons float64 `json:"remaining_coupons"       jsonschema:"description:Gross coupon income still to be paid until maturity"`
+	CurrentYield     float64 `json:"current_yield,omitempty" jsonschema:"description:Annual coupon income in percent of the current value"`
+}
+
+// Upcoming returns bond holdings maturing after date and no later than until, earliest first,
+// and the symbols of bond holdings missing from the catalogue
+func (c Catalogue) Upcoming(balances []portfolio.Balance, date, until time.Time) ([]Maturity, []string) {
+	date = portfolio.DateOf(date)
+
+	var (
+		maturities []Maturity
+		unknown    []string
+	)
+
+	for _, b := range balances {
+		if b.AssetType != portfolio.AssetTypeBond || b.UnitsAmount <= 0 {
+			continue
+		}
+
+		bond, ok := c.Lookup(b.AssetSymbol)
+		if !ok {
+			if !slices.Contains(unknown, b.AssetSymbol) {
+				unknown = append(unknown, b.AssetSymbol)
+			}
+
+			continue
+		}
+
+		if !bond.MaturityDate.After(date) || bond.MaturityDate.After(until) {
+			continue
+		}
+
+		m := Maturity{
+			Account:        b.SourceAccount,
+			Symbol:         bond.Symbol,
+			Name:           cmp.Or(bond.Name, b.AssetName),
+			MaturityDate:   bond.MaturityDate.Format(time.DateOnly),
+			DaysToMaturity: int(bond.MaturityDate.Sub(date).Hours()/24 + 0.5),
+			Principal:      b.UnitsAmount * bond.FaceValue,
+			CurrentValue:   b.UnitsValue,
+			Currency:       b.UnitsCurrency,
+			CouponRate:     bond.CouponRate,
+			CurrentYield:   bond.CurrentYield(b.UnitsValue / b.UnitsAmount),
+		}
+
+		// Count coupons left, each paying its share of the annual rate
+		coupon := m.Principal * bond.CouponRate / 100 / float64(max(bond.CouponFrequency, 1))
+		for d, ok := bond.NextCoupon(date); ok; d, ok = bond.NextCoupon(d) {
+			if m.NextCouponDate == "" {
+				m.NextCouponDate = d.Format(time.DateOnly)
+			}
+
+			m.RemainingCoupons += coupon
+		}
+
+		maturities = append(maturities, m)
+	}
+
+	slices.SortFunc(maturities, func(a, b Maturity) int {
+		return cmp.Or(cmp.Compare(a.MaturityDate, b.MaturityDate), cmp.Compare(a.Account, b.Account), cmp.Compare(a.Symbol, b.Symbol))
+	})
+	slices.Sort(unknown)
+
+	return maturities, unknown
+}
@@ -0,0 +1,126 @@
+// Package classify maps asset symbols to sectors, sub-industries and issuer groups
+package classify
+
+import (
+	"bytes"
+	"cmp"
+	_ "embed"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/chickenzord/portosync/internal/datafile"
+	"github.com/chickenzord/portosync/internal/portfolio"
+)
+
+// idxCSV classifies frequently held IDX equities by IDX-IC sector
+//
+//go:embed idx.csv
+var idxCSV []byte
+
+// Default returns the embedded classification of IDX equities
+var Default = sync.OnceValue(func() Catalogue {
+	catalogue, err := Read(bytes.NewReader(idxCSV))
+	if err != nil {
+		panic(fmt.Errorf("invalid embedded classification: %w", err))
+	}
+
+	return catalogue
+})
+
+// Class is the sector, sub-industry and issuer group of an asset
+type Class struct {
+	Sector      string `json:"sector,omitempty"       jsonschema:"description:Business sector of the issuer"`
+	SubIndustry string `json:"sub_industry,omitempty" jsonschema:"description:Sub-industry of the issuer within its sector"`
+	IssuerGroup string `json:"issuer_group,omitempty" jsonschema:"description:Business group or owner the issuer belongs to"`
+}
+
+// Catalogue maps symbols to their class
+type Catalogue map[string]Class
+
+// Lookup returns the class of a symbol
+func (c Catalogue) Lookup(symbol string) (Class, bool) {
+	class, ok := c[strings.ToUpper(strings.TrimSpace(symbol))]
+
+	return class, ok
+}
+
+// Merge returns a catalogue of c with the classes of override replacing those of the same symbol
+func (c Catalogue) Merge(override Catalogue) Catalogue {
+	merged := maps.Clone(c)
+	if merged == nil {
+		merged = make(Catalogue)
+	}
+
+	maps.Copy(merged, override)
+
+	return merged
+}
+
+// Apply sets the sector, sub-industry and issuer group of non-cash balances found in the catalogue
+func (c Catalogue) Apply(balances []portfolio.Balance) {
+	for i := range balances {
+		b := &balances[i]
+		if b.IsCash() {
+			continue
+		}
+
+		if class, ok := c.Lookup(b.AssetSymbol); ok {
+			b.Sector = class.Sector
+			b.SubIndustry = class.SubIndustry
+			b.IssuerGroup = class.IssuerGroup
+		}
+	}
+}
+
+// Load reads a catalogue from a CSV file with header columns symbol and sector
+// and optionally sub_industry and issuer_group (or issuer)
+func Load(path string) (Catalogue, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	catalogue, err := Read(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return catalogue, nil
+}
+
+// Read reads a catalogue in the CSV format described in Load
+func Read(r io.Reader) (Catalogue, error) {
+	columns, records, err := datafile.ReadCSV(r, ',')
+	if err != nil {
+		return nil, err
+	}
+
+	catalogue := make(Catalogue)
+
+	if len(columns) == 0 {
+		return catalogue, nil
+	}
+
+	if err := datafile.RequireColumns(columns, "symbol", "sector"); err != nil {
+		return nil, err
+	}
+
+	for _, record := range records {
+		if record.Get("symbol") == "" {
+			continue
+		}
+
+		catalogue[strings.ToUpper(record.Get("symbol"))] = Class{
+			Sector:      record.Get("sector"),
+			SubIndustry: record.Get("sub_industry"),
+			IssuerGroup: cmp.Or(record.Get("issuer_group"), record.Get("issuer")),
+		}
+	}
+
+	return catalogue, nil
+}
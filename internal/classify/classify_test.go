@@ -0,0 +1,72 @@
+package classify
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/chickenzord/portosync/internal/portfolio"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRead(t *testing.T) {
+	catalogue, err := Read(strings.NewReader("Symbol,Sector,Sub_Industry,Issuer_Group\nbbca,Financials,Banks,Djarum\nTLKM,Infrastructures,,\n,,,\n"))
+	require.NoError(t, err)
+	assert.Len(t, catalogue, 2)
+
+	class, ok := catalogue.Lookup(" BBCA")
+	require.True(t, ok)
+	assert.Equal(t, Class{Sector: "Financials", SubIndustry: "Banks", IssuerGroup: "Djarum"}, class)
+
+	_, ok = catalogue.Lookup("XXXX")
+	assert.False(t, ok)
+
+	catalogue, err = Read(strings.NewReader("symbol,sector,issuer\nBBCA,Financials,Djarum\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "Djarum", catalogue["BBCA"].IssuerGroup)
+
+	_, err = Read(strings.NewReader("symbol,issuer\nBBCA,Djarum\n"))
+	assert.Error(t, err)
+}
+
+func TestDefault(t *testing.T) {
+	catalogue := Default()
+	assert.NotEmpty(t, catalogue)
+
+	for symbol, class := range catalogue {
+		assert.NotEmpty(t, class.Sector, symbol)
+		assert.NotEmpty(t, class.SubIndustry, symbol)
+		assert.NotEmpty(t, class.IssuerGroup, symbol)
+	}
+
+	class, ok := catalogue.Lookup("BBRI")
+	require.True(t, ok)
+	assert.Equal(t, Class{Sector: "Financials", SubIndustry: "Banks", IssuerGroup: "BUMN"}, class)
+}
+
+func TestCatalogue_MergeAndApply(t *testing.T) {
+	base := Catalogue{
+		"BBCA": {Sector: "Financials", SubIndustry: "Banks", IssuerGroup: "Djarum"},
+		"TLKM": {Sector: "Infrastructures"},
+	}
+
+	merged := base.Merge(Catalogue{"TLKM": {Sector: "Telecom"}, "GOTO": {Sector: "Technology"}})
+	assert.Len(t, merged, 3)
+	assert.Equal(t, "Telecom", merged["TLKM"].Sector)
+	assert.Equal(t, "Infrastructures", base["TLKM"].Sector, "base is not modified")
+	assert.Len(t, Catalogue(nil).Merge(base), 2)
+
+	balances := []portfolio.Balance{
+		{AssetSymbol: "BBCA", AssetType: "equity"},
+		{AssetSymbol: "XXXX", AssetType: "equity"},
+		{AssetSymbol: "GOTO", AssetType: portfolio.AssetTypeCash},
+	}
+
+	merged.Apply(balances)
+
+	assert.Equal(t, "Financials", balances[0].Sector)
+	assert.Equal(t, "Banks", balances[0].SubIndustry)
+	assert.Equal(t, "Djarum", balances[0].IssuerGroup)
+	assert.Empty(t, balances[1].Sector)
+	assert.Empty(t, balances[2].Sector)
+}
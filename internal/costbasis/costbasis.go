@@ -0,0 +1,222 @@
+// Package costbasis tracks the average purchase price of holdings to compute unrealized gains
+package costbasis
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chickenzord/portosync/internal/datafile"
+	"github.com/chickenzord/portosync/internal/portfolio"
+	"github.com/chickenzord/portosync/internal/transaction"
+)
+
+// Entry is the average purchase price of a symbol held in an account
+type Entry struct {
+	Account      string    `json:"account"             jsonschema:"description:Account name holding the asset"`
+	Symbol       string    `json:"symbol"              jsonschema:"description:Asset symbol or fund code"`
+	AveragePrice float64   `json:"average_price"       jsonschema:"description:Average purchase price per unit including fees"`
+	Units        float64   `json:"units,omitempty"     jsonschema:"description:Units held when the average price was recorded"`
+	UpdatedAt    time.Time `json:"updated_at,omitzero" jsonschema:"description:When the entry was recorded, or the date of the last transaction"`
+}
+
+type key struct {
+	account string
+	symbol  string
+}
+
+func keyOf(account, symbol string) key {
+	return key{account: account, symbol: strings.ToUpper(symbol)}
+}
+
+// FromTransactions computes average purchase prices using the average cost method.
+// Sells reduce units at the current average price; holdings sold completely are left out.
+func FromTransactions(transactions []transaction.Transaction) []Entry {
+	type position struct {
+		units, cost float64
+		updatedAt   time.Time
+	}
+
+	positions := make(map[key]*position)
+
+	var order []key
+
+	sorted := slices.Clone(transactions)
+	transaction.Sort(sorted)
+
+	for _, t := range sorted {
+		k := keyOf(t.Account, t.Symbol)
+
+		p, ok := positions[k]
+		if !ok {
+			p = &position{}
+			positions[k] = p
+			order = append(order, k)
+		}
+
+		p.updatedAt = t.Date
+
+		if t.IsIncrease() {
+			p.units += t.Units
+			p.cost += t.Units*t.Price + t.Fee
+
+			continue
+		}
+
+		if p.units <= t.Units {
+			p.units, p.cost = 0, 0
+
+			continue
+		}
+
+		p.cost -= p.cost / p.units * t.Units
+		p.units -= t.Units
+	}
+
+	var entries []Entry
+
+	for _, k := range order {
+		p := positions[k]
+		if p.units <= 0 {
+			continue
+		}
+
+		entries = append(entries, Entry{
+			Account:      k.account,
+			Symbol:       k.symbol,
+			AveragePrice: p.cost / p.units,
+			Units:        p.units,
+			UpdatedAt:    p.updatedAt,
+		})
+	}
+
+	return entries
+}
+
+// Book looks up average purchase prices by account and symbol
+type Book struct {
+	entries map[key]Entry
+}
+
+// NewBook creates a book from entry sets, entries in later sets overriding earlier ones
+func NewBook(entrySets ...[]Entry) Book {
+	b := Book{entries: make(map[key]Entry)}
+
+	for _, entries := range entrySets {
+		for _, e := range entries {
+			b.entries[keyOf(e.Account, e.Symbol)] = e
+		}
+	}
+
+	return b
+}
+
+// Merge creates a book from entries computed from transactions and entries entered manually.
+// An entered entry overrides the transactions of its holding up to when it was entered,
+// transactions dated after it take precedence again.
+func Merge(fromTransactions, entered []Entry) Book {
+	b := NewBook(fromTransactions)
+
+	for _, e := range entered {
+		k := keyOf(e.Account, e.Symbol)
+		if t, ok := b.entries[k]; ok && t.UpdatedAt.After(e.UpdatedAt) {
+			continue
+		}
+
+		b.entries[k] = e
+	}
+
+	return b
+}
+
+// Lookup returns the entry of a symbol held in an account
+func (b Book) Lookup(account, symbol string) (Entry, bool) {
+	e, ok := b.entries[keyOf(account, symbol)]
+
+	return e, ok
+}
+
+// Apply sets the cost basis and unrealized gain of non-cash balances found in the book
+func (b Book) Apply(balances []portfolio.Balance) {
+	for i := range balances {
+		if balances[i].IsCash() {
+			continue
+		}
+
+		if e, ok := b.Lookup(balances[i].SourceAccount, balances[i].AssetSymbol); ok {
+			balances[i].SetCostBasis(e.AveragePrice * balances[i].UnitsAmount)
+		}
+	}
+}
+
+// Store keeps manually entered cost basis entries in a JSON file
+type Store struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewStore creates a store backed by the file at path, which is created on first write
+func NewStore(path string) *Store {
+	return &Store{path: path}
+}
+
+// Entries returns all stored entries ordered by account and symbol
+func (s *Store) Entries() ([]Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.read()
+}
+
+// Set stores the entry, replacing any entry of the same account and symbol
+func (s *Store) Set(entry Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, err := s.read()
+	if err != nil {
+		return err
+	}
+
+	entry.Symbol = strings.ToUpper(entry.Symbol)
+	entries = slices.DeleteFunc(entries, func(e Entry) bool { return keyOf(e.Account, e.Symbol) == keyOf(entry.Account, entry.Symbol) })
+
+	return s.write(append(entries, entry))
+}
+
+// Delete removes the entry of a symbol held in an account, reporting whether it existed
+func (s *Store) Delete(account, symbol string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, err := s.read()
+	if err != nil {
+		return false, err
+	}
+
+	remaining := slices.DeleteFunc(entries, func(e Entry) bool { return keyOf(e.Account, e.Symbol) == keyOf(account, symbol) })
+	if len(remaining) == len(entries) {
+		return false, nil
+	}
+
+	return true, s.write(remaining)
+}
+
+func (s *Store) read() ([]Entry, error) {
+	var entries []Entry
+	if _, err := datafile.ReadJSON(s.path, &entries); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+func (s *Store) write(entries []Entry) error {
+	slices.SortFunc(entries, func(a, b Entry) int {
+		return cmp.Or(cmp.Compare(a.Account, b.Account), cmp.Compare(a.Symbol, b.Symbol))
+	})
+
+	return datafile.WriteJSON(s.path, entries)
+}
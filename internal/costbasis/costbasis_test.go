@@ -0,0 +1,111 @@
+package costbasis
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/chickenzord/portosync/internal/portfolio"
+	"github.com/chickenzord/portosync/internal/transaction"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func date(s string) time.Time {
+	d, err := portfolio.ParseDate(s)
+	if err != nil {
+		panic(err)
+	}
+
+	return d
+}
+
+func TestFromTransactions(t *testing.T) {
+	entries := FromTransactions([]transaction.Transaction{
+		{Date: date("2024-03-01"), Account: "personal", Symbol: "BBCA", Type: transaction.TypeSell, Units: 100, Price: 11000},
+		{Date: date("2024-01-01"), Account: "personal", Symbol: "BBCA", Type: transaction.TypeBuy, Units: 100, Price: 9000, Fee: 1000},
+		{Date: date("2024-02-01"), Account: "personal", Symbol: "BBCA", Type: transaction.TypeBuy, Units: 100, Price: 10000, Fee: 1000},
+		{Date: date("2024-01-01"), Account: "personal", Symbol: "TLKM", Type: transaction.TypeBuy, Units: 100, Price: 4000},
+		{Date: date("2024-02-01"), Account: "personal", Symbol: "TLKM", Type: transaction.TypeSell, Units: 100, Price: 3000},
+		{Date: date("2024-01-01"), Account: "business", Symbol: "SCMMF", Type: transaction.TypeSubscription, Units: 1000, Price: 1500},
+		{Date: date("2024-01-05"), Account: "business", Symbol: "SCMMF", Type: transaction.TypeRedemption, Units: 400, Price: 1510},
+	})
+
+	assert.Equal(t, []Entry{
+		{Account: "business", Symbol: "SCMMF", AveragePrice: 1500, Units: 600, UpdatedAt: date("2024-01-05")},
+		{Account: "personal", Symbol: "BBCA", AveragePrice: 9510, Units: 100, UpdatedAt: date("2024-03-01")},
+	}, entries)
+}
+
+func TestBook_Apply(t *testing.T) {
+	book := NewBook(
+		[]Entry{{Account: "personal", Symbol: "BBCA", AveragePrice: 8000}, {Account: "personal", Symbol: "TLKM", AveragePrice: 4000}},
+		[]Entry{{Account: "personal", Symbol: "bbca", AveragePrice: 9000}},
+	)
+
+	balances := []portfolio.Balance{
+		{SourceAccount: "personal", AssetSymbol: "BBCA", UnitsAmount: 100, UnitsValue: 990000},
+		{SourceAccount: "business", AssetSymbol: "BBCA", UnitsAmount: 100, UnitsValue: 990000},
+		{SourceAccount: "personal", AssetSymbol: "IDR", AssetType: portfolio.AssetTypeCash, UnitsAmount: 100, UnitsValue: 100},
+	}
+	book.Apply(balances)
+
+	assert.Equal(t, 900000.0, balances[0].CostBasis)
+	require.NotNil(t, balances[0].UnrealizedGain)
+	assert.Equal(t, 90000.0, *balances[0].UnrealizedGain)
+	assert.Equal(t, 10.0, balances[0].UnrealizedGainPercent)
+	assert.False(t, balances[1].HasCostBasis())
+	assert.False(t, balances[2].HasCostBasis())
+}
+
+func TestMerge(t *testing.T) {
+	entered := []Entry{
+		{Account: "personal", Symbol: "BBCA", AveragePrice: 9000, UpdatedAt: time.Date(2024, 2, 1, 10, 0, 0, 0, time.Local)},
+		{Account: "personal", Symbol: "TLKM", AveragePrice: 4000, UpdatedAt: time.Date(2024, 2, 1, 10, 0, 0, 0, time.Local)},
+	}
+	fromTransactions := []Entry{
+		{Account: "personal", Symbol: "BBCA", AveragePrice: 8000, UpdatedAt: date("2024-02-01")},
+		{Account: "personal", Symbol: "TLKM", AveragePrice: 3500, UpdatedAt: date("2024-03-01")},
+	}
+
+	book := Merge(fromTransactions, entered)
+
+	bbca, ok := book.Lookup("personal", "bbca")
+	require.True(t, ok)
+	assert.Equal(t, 9000.0, bbca.AveragePrice, "entered after the last transaction")
+
+	tlkm, ok := book.Lookup("personal", "TLKM")
+	require.True(t, ok)
+	assert.Equal(t, 3500.0, tlkm.AveragePrice, "a later buy takes precedence over the entered price")
+}
+
+func TestStore(t *testing.T) {
+	store := NewStore(filepath.Join(t.TempDir(), "data", "cost_basis.json"))
+
+	entries, err := store.Entries()
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	require.NoError(t, store.Set(Entry{Account: "personal", Symbol: "tlkm", AveragePrice: 4000}))
+	require.NoError(t, store.Set(Entry{Account: "personal", Symbol: "BBCA", AveragePrice: 8000}))
+	require.NoError(t, store.Set(Entry{Account: "personal", Symbol: "BBCA", AveragePrice: 9000}))
+
+	entries, err = store.Entries()
+	require.NoError(t, err)
+	assert.Equal(t, []Entry{
+		{Account: "personal", Symbol: "BBCA", AveragePrice: 9000},
+		{Account: "personal", Symbol: "TLKM", AveragePrice: 4000},
+	}, entries)
+
+	removed, err := store.Delete("personal", "tlkm")
+	require.NoError(t, err)
+	assert.True(t, removed)
+
+	removed, err = store.Delete("personal", "TLKM")
+	require.NoError(t, err)
+	assert.False(t, removed)
+
+	entries, err = store.Entries()
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
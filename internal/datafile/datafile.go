@@ -0,0 +1,110 @@
+// Package datafile reads the CSV files maintained by users and stores JSON files kept by portosync
+package datafile
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Record is a CSV row keyed by lowercase column name. Columns missing from a short row have no key.
+type Record map[string]string
+
+// Get returns the value of a column, empty when the row lacks it
+func (r Record) Get(column string) string {
+	return r[column]
+}
+
+// ReadCSV reads CSV data with a header row. Column names are lowercased and trimmed, ignoring a leading
+// byte order mark, and values are trimmed. It returns the column names and one record per data row.
+func ReadCSV(r io.Reader, comma rune) ([]string, []Record, error) {
+	cr := csv.NewReader(r)
+	cr.Comma = comma
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	rows, err := cr.ReadAll()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(rows) == 0 {
+		return nil, nil, nil
+	}
+
+	columns := make([]string, len(rows[0]))
+	for i, h := range rows[0] {
+		columns[i] = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")))
+	}
+
+	records := make([]Record, 0, len(rows)-1)
+
+	for _, row := range rows[1:] {
+		record := make(Record, len(columns))
+
+		for i, v := range row {
+			if i < len(columns) {
+				record[columns[i]] = strings.TrimSpace(v)
+			}
+		}
+
+		records = append(records, record)
+	}
+
+	return columns, records, nil
+}
+
+// RequireColumns returns an error naming the first required column missing from columns
+func RequireColumns(columns []string, required ...string) error {
+	for _, c := range required {
+		if !slices.Contains(columns, c) {
+			return fmt.Errorf("missing column %q", c)
+		}
+	}
+
+	return nil
+}
+
+// ReadJSON decodes the JSON file at path into v, reporting false without error when the file does not exist
+func ReadJSON(path string, v any) (bool, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// WriteJSON encodes v as indented JSON into the file at path, creating its directory if needed
+func WriteJSON(path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so readers never observe partial content
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,52 @@
+package datafile
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadCSV(t *testing.T) {
+	columns, records, err := ReadCSV(strings.NewReader("\ufeffDate, Symbol ,Price\n2024-01-02, bbca ,9500\n2024-01-03\n"), ',')
+	require.NoError(t, err)
+	assert.Equal(t, []string{"date", "symbol", "price"}, columns)
+	assert.Equal(t, []Record{
+		{"date": "2024-01-02", "symbol": "bbca", "price": "9500"},
+		{"date": "2024-01-03"},
+	}, records)
+	assert.Empty(t, records[1].Get("price"))
+
+	assert.NoError(t, RequireColumns(columns, "date", "price"))
+	assert.EqualError(t, RequireColumns(columns, "date", "units"), `missing column "units"`)
+
+	_, records, err = ReadCSV(strings.NewReader("symbol;units\nBBCA;100\n"), ';')
+	require.NoError(t, err)
+	assert.Equal(t, "100", records[0].Get("units"))
+
+	columns, records, err = ReadCSV(strings.NewReader(""), ',')
+	require.NoError(t, err)
+	assert.Nil(t, columns)
+	assert.Nil(t, records)
+}
+
+func TestJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "entries.json")
+
+	var entries []string
+
+	found, err := ReadJSON(path, &entries)
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	require.NoError(t, WriteJSON(path, []string{"BBCA", "TLKM"}))
+
+	found, err = ReadJSON(path, &entries)
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, []string{"BBCA", "TLKM"}, entries)
+
+	assert.NoFileExists(t, path+".tmp")
+}
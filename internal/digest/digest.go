@@ -0,0 +1,206 @@
+// Package digest summarizes the portfolio of a day against the previous day for daily notifications
+package digest
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/chickenzord/portosync/internal/notify"
+	"github.com/chickenzord/portosync/internal/portfolio"
+)
+
+// DefaultTopMovers is the number of top movers listed when none is configured
+const DefaultTopMovers = 5
+
+// Total is the value of all holdings in one currency
+type Total struct {
+	Currency      string
+	Value         float64
+	Previous      float64
+	Change        float64
+	ChangePercent float64
+	New           bool // no holdings in the currency on the previous day
+}
+
+// Mover is a holding whose price changed since the previous day
+type Mover struct {
+	Account       string
+	Symbol        string
+	Currency      string
+	Value         float64
+	Previous      float64 // value of the current units at the previous price
+	Change        float64 // change in value of the current units
+	ChangePercent float64 // change in price
+}
+
+// Digest is a daily summary of the portfolio
+type Digest struct {
+	Date        time.Time
+	Totals      []Total // sorted by currency
+	TopMovers   []Mover // sorted by absolute change, largest first
+	Failures    []string
+	HasPrevious bool // whether balances of a previous day were available for comparison
+}
+
+// holding identifies a balance between days
+type holding struct {
+	account, assetType, symbol, currency string
+}
+
+// Compose summarizes balances of the date against previous balances, which may be nil
+// when unknown. Top movers are the topN holdings present on both days with the largest
+// absolute change in value at constant units, so trades do not count as moves; failures are messages of fetches that failed since the previous digest.
+func Compose(date time.Time, current, previous []portfolio.Balance, failures []string, topN int) Digest {
+	d := Digest{
+		Date:        portfolio.DateOf(date),
+		Failures:    failures,
+		HasPrevious: previous != nil,
+	}
+
+	values, previousValues := totals(current), totals(previous)
+
+	for _, currency := range slices.Sorted(maps.Keys(values)) {
+		t := Total{Currency: currency, Value: values[currency]}
+
+		if p, ok := previousValues[currency]; ok {
+			t.Previous = p
+			t.Change = t.Value - p
+
+			if p != 0 {
+				t.ChangePercent = t.Change * 100 / p
+			}
+		} else {
+			t.New = d.HasPrevious
+		}
+
+		d.Totals = append(d.Totals, t)
+	}
+
+	before := holdings(previous)
+
+	for h, c := range holdings(current) {
+		p, ok := before[h]
+		if !ok || p.units == 0 || c.units == 0 || c.price() == p.price() {
+			continue
+		}
+
+		// Value the current units at both prices, so buys and sells are not mistaken for moves
+		m := Mover{
+			Account:  h.account,
+			Symbol:   h.symbol,
+			Currency: h.currency,
+			Value:    c.value,
+			Previous: c.units * p.price(),
+		}
+
+		m.Change = m.Value - m.Previous
+
+		if m.Previous != 0 {
+			m.ChangePercent = m.Change * 100 / m.Previous
+		}
+
+		d.TopMovers = append(d.TopMovers, m)
+	}
+
+	slices.SortFunc(d.TopMovers, func(a, b Mover) int {
+		return cmp.Or(
+			cmp.Compare(math.Abs(b.Change), math.Abs(a.Change)),
+			cmp.Compare(a.Account, b.Account),
+			cmp.Compare(a.Symbol, b.Symbol),
+		)
+	})
+
+	if len(d.TopMovers) > topN {
+		d.TopMovers = d.TopMovers[:max(topN, 0)]
+	}
+
+	return d
+}
+
+// Message formats the digest as a notification
+func (d Digest) Message() notify.Message {
+	var sb strings.Builder
+
+	sb.WriteString("Total value:\n")
+
+	if len(d.Totals) == 0 {
+		sb.WriteString("- no holdings\n")
+	}
+
+	for _, t := range d.Totals {
+		switch {
+		case t.New:
+			fmt.Fprintf(&sb, "- %s %.2f (new)\n", t.Currency, t.Value)
+		case d.HasPrevious:
+			fmt.Fprintf(&sb, "- %s %.2f (%+.2f, %+.2f%%)\n", t.Currency, t.Value, t.Change, t.ChangePercent)
+		default:
+			fmt.Fprintf(&sb, "- %s %.2f\n", t.Currency, t.Value)
+		}
+	}
+
+	if !d.HasPrevious {
+		sb.WriteString("No previous day to compare with\n")
+	} else if len(d.TopMovers) > 0 {
+		sb.WriteString("\nTop movers:\n")
+
+		for _, m := range d.TopMovers {
+			fmt.Fprintf(&sb, "- %s (%s) %s %+.2f (%+.2f%%)\n", m.Symbol, m.Account, m.Currency, m.Change, m.ChangePercent)
+		}
+	}
+
+	if len(d.Failures) > 0 {
+		sb.WriteString("\nFailures:\n")
+
+		for _, f := range d.Failures {
+			fmt.Fprintf(&sb, "- %s\n", f)
+		}
+	}
+
+	return notify.Message{
+		Title: "Portfolio digest " + d.Date.Format(time.DateOnly),
+		Text:  strings.TrimSuffix(sb.String(), "\n"),
+	}
+}
+
+// totals sums values of balances per currency
+func totals(balances []portfolio.Balance) map[string]float64 {
+	result := make(map[string]float64)
+	for _, b := range balances {
+		result[b.UnitsCurrency] += b.UnitsValue
+	}
+
+	return result
+}
+
+// position is the units and value of a holding
+type position struct {
+	units, value float64
+}
+
+func (p position) price() float64 {
+	return p.value / p.units
+}
+
+// holdings sums units and values of non-cash balances per holding, merging sub-accounts
+func holdings(balances []portfolio.Balance) map[holding]position {
+	result := make(map[holding]position)
+
+	for _, b := range balances {
+		if b.IsCash() {
+			continue
+		}
+
+		h := holding{b.SourceAccount, b.AssetType, b.AssetSymbol, b.UnitsCurrency}
+		p := result[h]
+		p.units += b.UnitsAmount
+		p.value += b.UnitsValue
+		result[h] = p
+	}
+
+	return result
+}
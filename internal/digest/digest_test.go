@@ -0,0 +1,96 @@
+package digest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/chickenzord/portosync/internal/portfolio"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func date(s string) time.Time {
+	t, err := portfolio.ParseDate(s)
+	if err != nil {
+		panic(err)
+	}
+
+	return t
+}
+
+func TestCompose(t *testing.T) {
+	previous := []portfolio.Balance{
+		{SourceAccount: "personal", AssetSymbol: "BBCA", AssetType: "equity", UnitsAmount: 100, UnitsValue: 1000, UnitsCurrency: "IDR"},
+		{SourceAccount: "personal", AssetSymbol: "TLKM", AssetType: "equity", UnitsAmount: 100, UnitsValue: 500, UnitsCurrency: "IDR"},
+		{SourceAccount: "personal", AssetSymbol: "ASII", AssetType: "equity", UnitsAmount: 100, UnitsValue: 200, UnitsCurrency: "IDR"},
+		{SourceAccount: "personal", AssetSymbol: "IDR", AssetType: "cash", UnitsValue: 300, UnitsCurrency: "IDR"},
+	}
+	current := []portfolio.Balance{
+		{SourceAccount: "personal", AssetSymbol: "BBCA", AssetType: "equity", UnitsAmount: 100, UnitsValue: 1100, UnitsCurrency: "IDR"},
+		{SourceAccount: "personal", AssetSymbol: "TLKM", AssetType: "equity", UnitsAmount: 100, UnitsValue: 300, UnitsCurrency: "IDR"},
+		{SourceAccount: "personal", AssetSymbol: "ASII", AssetType: "equity", UnitsAmount: 100, UnitsValue: 200, UnitsCurrency: "IDR"},
+		{SourceAccount: "personal", AssetSymbol: "IDR", AssetType: "cash", UnitsValue: 100, UnitsCurrency: "IDR"},
+		{SourceAccount: "broker", AssetSymbol: "AAPL", AssetType: "equity", UnitsAmount: 1, UnitsValue: 10, UnitsCurrency: "USD"},
+	}
+
+	d := Compose(date("2024-01-02"), current, previous, []string{"KSEI login failed"}, 5)
+
+	assert.True(t, d.HasPrevious)
+	assert.Equal(t, []Total{
+		{Currency: "IDR", Value: 1700, Previous: 2000, Change: -300, ChangePercent: -15},
+		{Currency: "USD", Value: 10, New: true},
+	}, d.Totals)
+	assert.Equal(t, []Mover{
+		{Account: "personal", Symbol: "TLKM", Currency: "IDR", Value: 300, Previous: 500, Change: -200, ChangePercent: -40},
+		{Account: "personal", Symbol: "BBCA", Currency: "IDR", Value: 1100, Previous: 1000, Change: 100, ChangePercent: 10},
+	}, d.TopMovers, "unchanged, new and cash holdings are not movers")
+
+	require.Len(t, Compose(date("2024-01-02"), current, previous, nil, 1).TopMovers, 1)
+	assert.Empty(t, Compose(date("2024-01-02"), current, previous, nil, 0).TopMovers)
+
+	msg := d.Message()
+	assert.Equal(t, "Portfolio digest 2024-01-02", msg.Title)
+	assert.Equal(t, `Total value:
+- IDR 1700.00 (-300.00, -15.00%)
+- USD 10.00 (new)
+
+Top movers:
+- TLKM (personal) IDR -200.00 (-40.00%)
+- BBCA (personal) IDR +100.00 (+10.00%)
+
+Failures:
+- KSEI login failed`, msg.Text)
+}
+
+func TestCompose_WithoutPrevious(t *testing.T) {
+	current := []portfolio.Balance{
+		{SourceAccount: "personal", AssetSymbol: "BBCA", AssetType: "equity", UnitsAmount: 100, UnitsValue: 1100, UnitsCurrency: "IDR"},
+	}
+
+	d := Compose(date("2024-01-02"), current, nil, nil, 5)
+
+	assert.False(t, d.HasPrevious)
+	assert.Empty(t, d.TopMovers)
+	assert.Equal(t, "Total value:\n- IDR 1100.00\nNo previous day to compare with", d.Message().Text)
+}
+
+func TestCompose_Trades(t *testing.T) {
+	previous := []portfolio.Balance{
+		{SourceAccount: "personal", AssetSymbol: "BBCA", AssetType: "equity", UnitsAmount: 100, UnitsValue: 1000, UnitsCurrency: "IDR"},
+		{SourceAccount: "personal", AssetSymbol: "TLKM", AssetType: "equity", UnitsAmount: 100, UnitsValue: 500, UnitsCurrency: "IDR"},
+		{SourceAccount: "personal", AssetSymbol: "SCMMF", AssetType: "mutual_fund", UnitsAmount: 1000, UnitsValue: 1500, UnitsCurrency: "IDR"},
+	}
+	current := []portfolio.Balance{
+		{SourceAccount: "personal", AssetSymbol: "BBCA", AssetType: "equity", UnitsAmount: 1000, UnitsValue: 10000, UnitsCurrency: "IDR"},
+		{SourceAccount: "personal", AssetSymbol: "TLKM", AssetType: "equity", UnitsAmount: 50, UnitsValue: 275, UnitsCurrency: "IDR"},
+		{SourceAccount: "personal", AssetSymbol: "SCMMF", AssetType: "mutual_fund", UnitsAmount: 2000, UnitsValue: 3020, UnitsCurrency: "IDR"},
+	}
+
+	d := Compose(date("2024-01-02"), current, previous, nil, 5)
+
+	// BBCA was bought at an unchanged price, TLKM was partly sold while its price rose 10%
+	assert.Equal(t, []Mover{
+		{Account: "personal", Symbol: "TLKM", Currency: "IDR", Value: 275, Previous: 250, Change: 25, ChangePercent: 10},
+		{Account: "personal", Symbol: "SCMMF", Currency: "IDR", Value: 3020, Previous: 3000, Change: 20, ChangePercent: 20 * 100 / 3000.0},
+	}, d.TopMovers)
+}
@@ -0,0 +1,169 @@
+// Package export writes portfolio balances into file formats meant for spreadsheets and other tools
+package export
+
+import (
+	"cmp"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/chickenzord/portosync/internal/portfolio"
+)
+
+type Format string
+
+const (
+	FormatCSV       Format = "csv"
+	FormatJSONL     Format = "jsonl"
+	FormatJSON      Format = "json"
+	FormatBeancount Format = "beancount"
+	FormatHledger   Format = "hledger"
+)
+
+// Formats lists all supported export formats
+var Formats = []Format{FormatCSV, FormatJSONL, FormatJSON, FormatBeancount, FormatHledger}
+
+// Options configures format-specific export behavior
+type Options struct {
+	Ledger LedgerOptions
+}
+
+// ParseFormat parses a format name case-insensitively
+func ParseFormat(s string) (Format, error) {
+	f := Format(strings.ToLower(strings.TrimSpace(s)))
+	if slices.Contains(Formats, f) {
+		return f, nil
+	}
+
+	return "", fmt.Errorf("unsupported export format %q", s)
+}
+
+// Row is a single exported balance tagged with the date it was observed
+type Row struct {
+	Date string `json:"date"`
+	portfolio.Balance
+}
+
+// column describes a CSV column. New columns must be appended to keep the column order stable.
+type column struct {
+	name  string
+	value func(Row) string
+}
+
+var columns = []column{
+	{"date", func(r Row) string { return r.Date }},
+	{"source_type", func(r Row) string { return r.SourceType }},
+	{"source_account", func(r Row) string { return r.SourceAccount }},
+	{"asset_symbol", func(r Row) string { return r.AssetSymbol }},
+	{"asset_name", func(r Row) string { return r.AssetName }},
+	{"asset_type", func(r Row) string { return r.AssetType }},
+	{"asset_sub_type", func(r Row) string { return r.AssetSubType }},
+	{"units_amount", func(r Row) string { return formatFloat(r.UnitsAmount) }},
+	{"units_value", func(r Row) string { return formatFloat(r.UnitsValue) }},
+	{"units_currency", func(r Row) string { return r.UnitsCurrency }},
+	{"participant", func(r Row) string { return r.Participant }},
+	{"sub_account", func(r Row) string { return r.SubAccount }},
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// Rows flattens snapshots into rows sorted by date, account, asset type and symbol
+func Rows(snapshots []portfolio.Snapshot) []Row {
+	var rows []Row
+
+	for _, snapshot := range snapshots {
+		for _, b := range snapshot.Balances {
+			rows = append(rows, Row{
+				Date:    snapshot.DateString(),
+				Balance: b,
+			})
+		}
+	}
+
+	slices.SortStableFunc(rows, func(a, b Row) int {
+		return cmp.Or(
+			cmp.Compare(a.Date, b.Date),
+			cmp.Compare(a.SourceAccount, b.SourceAccount),
+			cmp.Compare(a.AssetType, b.AssetType),
+			cmp.Compare(a.AssetSymbol, b.AssetSymbol),
+		)
+	})
+
+	return rows
+}
+
+// Write exports all balances in the snapshots to w using the given format
+func Write(w io.Writer, format Format, snapshots []portfolio.Snapshot, opts Options) error {
+	switch format {
+	case FormatCSV:
+		return writeCSV(w, Rows(snapshots))
+	case FormatJSONL:
+		return writeJSONL(w, Rows(snapshots))
+	case FormatJSON:
+		return writeJSON(w, Rows(snapshots))
+	case FormatBeancount:
+		return writeBeancount(w, snapshots, opts.Ledger)
+	case FormatHledger:
+		return writeHledger(w, snapshots, opts.Ledger)
+	default:
+		return fmt.Errorf("unsupported export format %q", format)
+	}
+}
+
+func writeCSV(w io.Writer, rows []Row) error {
+	cw := csv.NewWriter(w)
+
+	header := make([]string, len(columns))
+	for i, c := range columns {
+		header[i] = c.name
+	}
+
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	record := make([]string, len(columns))
+
+	for _, row := range rows {
+		for i, c := range columns {
+			record[i] = c.value(row)
+		}
+
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+
+	return cw.Error()
+}
+
+func writeJSONL(w io.Writer, rows []Row) error {
+	enc := json.NewEncoder(w)
+
+	for _, row := range rows {
+		if err := enc.Encode(row); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func writeJSON(w io.Writer, rows []Row) error {
+	if rows == nil {
+		rows = []Row{}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(rows)
+}
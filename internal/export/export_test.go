@@ -0,0 +1,83 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chickenzord/portosync/internal/portfolio"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testSnapshots = []portfolio.Snapshot{
+	{
+		Date: time.Date(2026, 1, 2, 0, 0, 0, 0, time.Local),
+		Balances: []portfolio.Balance{
+			{SourceType: "ksei", SourceAccount: "personal", AssetSymbol: "TLKM", AssetName: "Telkom Indonesia", AssetType: "equity", UnitsAmount: 500, UnitsValue: 1500000, UnitsCurrency: "IDR"},
+			{SourceType: "ksei", SourceAccount: "business", AssetSymbol: "BBCA", AssetName: "Bank Central Asia, Tbk", AssetType: "equity", UnitsAmount: 100, UnitsValue: 950000.5, UnitsCurrency: "IDR"},
+		},
+	},
+	{
+		Date: time.Date(2026, 1, 1, 0, 0, 0, 0, time.Local),
+		Balances: []portfolio.Balance{
+			{SourceType: "ksei", SourceAccount: "personal", AssetSymbol: "TLKM", AssetName: "Telkom Indonesia", AssetType: "equity", UnitsAmount: 500, UnitsValue: 1450000, UnitsCurrency: "IDR"},
+		},
+	},
+}
+
+func TestParseFormat(t *testing.T) {
+	f, err := ParseFormat(" CSV ")
+	assert.NoError(t, err)
+	assert.Equal(t, FormatCSV, f)
+
+	_, err = ParseFormat("xlsx")
+	assert.Error(t, err)
+}
+
+func TestWrite_CSV(t *testing.T) {
+	var buf bytes.Buffer
+
+	require.NoError(t, Write(&buf, FormatCSV, testSnapshots, Options{}))
+
+	expected := strings.Join([]string{
+		"date,source_type,source_account,asset_symbol,asset_name,asset_type,asset_sub_type,units_amount,units_value,units_currency,participant,sub_account",
+		"2026-01-01,ksei,personal,TLKM,Telkom Indonesia,equity,,500,1450000,IDR,,",
+		`2026-01-02,ksei,business,BBCA,"Bank Central Asia, Tbk",equity,,100,950000.5,IDR,,`,
+		"2026-01-02,ksei,personal,TLKM,Telkom Indonesia,equity,,500,1500000,IDR,,",
+	}, "\n") + "\n"
+
+	assert.Equal(t, expected, buf.String())
+}
+
+func TestWrite_JSONL(t *testing.T) {
+	var buf bytes.Buffer
+
+	require.NoError(t, Write(&buf, FormatJSONL, testSnapshots, Options{}))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 3)
+
+	var row Row
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &row))
+	assert.Equal(t, "2026-01-02", row.Date)
+	assert.Equal(t, "BBCA", row.AssetSymbol)
+	assert.True(t, strings.HasPrefix(lines[0], `{"date":"2026-01-01","source_type":"ksei"`))
+}
+
+func TestWrite_JSON(t *testing.T) {
+	var buf bytes.Buffer
+
+	require.NoError(t, Write(&buf, FormatJSON, testSnapshots, Options{}))
+
+	var rows []Row
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &rows))
+	assert.Len(t, rows, 3)
+	assert.Contains(t, buf.String(), "\n  {\n")
+
+	buf.Reset()
+	require.NoError(t, Write(&buf, FormatJSON, nil, Options{}))
+	assert.Equal(t, "[]\n", buf.String())
+}
@@ -0,0 +1,338 @@
+package export
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/chickenzord/portosync/internal/portfolio"
+)
+
+// DefaultLedgerAccountTemplate is used when LedgerOptions.AccountTemplate is empty
+const DefaultLedgerAccountTemplate = "Assets:Investments:{account}:{asset_type}"
+
+// LedgerOptions configures how balances are mapped into plain-text accounting ledgers
+type LedgerOptions struct {
+	// AccountTemplate builds the ledger account path of a balance.
+	// Supported placeholders are {source_type}, {account} and {asset_type}.
+	AccountTemplate string
+
+	// AccountNames maps a source account name to its {account} path segment(s),
+	// unmapped names are converted to CamelCase
+	AccountNames map[string]string
+
+	// AssetTypeNames maps an asset type to its {asset_type} path segment(s),
+	// unmapped types are converted to CamelCase
+	AssetTypeNames map[string]string
+}
+
+// LedgerAccount returns the ledger account path holding the balance
+func (o LedgerOptions) LedgerAccount(b portfolio.Balance) string {
+	account, ok := o.AccountNames[b.SourceAccount]
+	if !ok {
+		account = ledgerSegment(b.SourceAccount)
+	}
+
+	assetType, ok := o.AssetTypeNames[b.AssetType]
+	if !ok {
+		assetType = ledgerSegment(b.AssetType)
+	}
+
+	path := strings.NewReplacer(
+		"{source_type}", ledgerSegment(b.SourceType),
+		"{account}", account,
+		"{asset_type}", assetType,
+	).Replace(cmp.Or(o.AccountTemplate, DefaultLedgerAccountTemplate))
+
+	// Drop empty components left by empty placeholders
+	components := slices.DeleteFunc(strings.Split(path, ":"), func(s string) bool { return s == "" })
+
+	return strings.Join(components, ":")
+}
+
+// ledgerSegment converts s into a CamelCase account component, e.g. "mutual_fund" into "MutualFund"
+func ledgerSegment(s string) string {
+	var sb strings.Builder
+
+	upper := true
+
+	for _, r := range s {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+
+			continue
+		}
+
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+
+		sb.WriteRune(r)
+	}
+
+	return sb.String()
+}
+
+// commodity converts an asset symbol into a valid Beancount commodity name:
+// uppercase, starting with a letter, ending with a letter or digit, at most 24 characters
+func commodity(symbol string) string {
+	var sb strings.Builder
+
+	for _, r := range strings.ToUpper(symbol) {
+		switch {
+		case (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || strings.ContainsRune("'._-", r):
+			sb.WriteRune(r)
+		default:
+			sb.WriteRune('-')
+		}
+	}
+
+	c := sb.String()
+
+	if c == "" || c[0] < 'A' || c[0] > 'Z' {
+		c = "X" + c
+	}
+
+	if len(c) > 24 {
+		c = c[:24]
+	}
+
+	return strings.TrimRight(c, "'._-")
+}
+
+// hledgerCommodity quotes commodity names hledger would not parse as bare symbols
+func hledgerCommodity(symbol string) string {
+	c := commodity(symbol)
+	if strings.IndexFunc(c, func(r rune) bool { return !unicode.IsLetter(r) }) >= 0 {
+		return `"` + c + `"`
+	}
+
+	return c
+}
+
+type ledgerPrice struct {
+	commodity string
+	price     float64
+	currency  string
+}
+
+// ledgerPrices returns the unit price of each commodity in the snapshot, derived from balance values
+func ledgerPrices(balances []portfolio.Balance, commodityFn func(string) string) []ledgerPrice {
+	seen := make(map[string]bool)
+
+	var prices []ledgerPrice
+
+	for _, b := range balances {
+		c := commodityFn(b.AssetSymbol)
+		if seen[c] || b.UnitsAmount == 0 || b.UnitsCurrency == "" || b.AssetSymbol == b.UnitsCurrency {
+			continue
+		}
+
+		seen[c] = true
+		prices = append(prices, ledgerPrice{
+			commodity: c,
+			price:     b.UnitsValue / b.UnitsAmount,
+			currency:  b.UnitsCurrency,
+		})
+	}
+
+	slices.SortFunc(prices, func(a, b ledgerPrice) int { return cmp.Compare(a.commodity, b.commodity) })
+
+	return prices
+}
+
+// ledgerPosition is the total amount of a commodity held in a ledger account
+type ledgerPosition struct {
+	account   string
+	commodity string
+	amount    float64
+}
+
+// ledgerPositions sums balances per ledger account and commodity, ordered by account and commodity.
+// Balances of several sub-accounts or participants may map to the same ledger account.
+func ledgerPositions(balances []portfolio.Balance, opts LedgerOptions, commodityFn func(string) string) []ledgerPosition {
+	index := make(map[[2]string]int)
+
+	var positions []ledgerPosition
+
+	for _, b := range balances {
+		key := [2]string{opts.LedgerAccount(b), commodityFn(b.AssetSymbol)}
+
+		i, ok := index[key]
+		if !ok {
+			i = len(positions)
+			index[key] = i
+			positions = append(positions, ledgerPosition{account: key[0], commodity: key[1]})
+		}
+
+		positions[i].amount += b.UnitsAmount
+	}
+
+	slices.SortFunc(positions, func(a, b ledgerPosition) int {
+		return cmp.Or(cmp.Compare(a.account, b.account), cmp.Compare(a.commodity, b.commodity))
+	})
+
+	return positions
+}
+
+// ledgerAccounts returns the sorted ledger accounts of all snapshots
+func ledgerAccounts(snapshots []portfolio.Snapshot, opts LedgerOptions) []string {
+	var accounts []string
+
+	for _, snapshot := range snapshots {
+		for _, b := range snapshot.Balances {
+			accounts = append(accounts, opts.LedgerAccount(b))
+		}
+	}
+
+	slices.Sort(accounts)
+
+	return slices.Compact(accounts)
+}
+
+// Opening balances accounts balance the amounts padded or assigned to asset accounts, as snapshots carry no transactions
+const (
+	beancountOpeningAccount = "Equity:Opening-Balances"
+	hledgerOpeningAccount   = "equity:opening-balances"
+)
+
+// ledgerBook tracks the amount last reported per ledger account and commodity
+type ledgerBook map[[2]string]float64
+
+// update records the positions of a snapshot. It returns them together with zero positions of commodities
+// no longer held, ordered by account and commodity, and the sorted accounts whose amounts changed.
+func (b ledgerBook) update(positions []ledgerPosition) ([]ledgerPosition, []string) {
+	current := make(map[[2]string]bool, len(positions))
+
+	var changed []string
+
+	for _, p := range positions {
+		key := [2]string{p.account, p.commodity}
+		current[key] = true
+
+		if amount, ok := b[key]; !ok || amount != p.amount {
+			changed = append(changed, p.account)
+		}
+
+		b[key] = p.amount
+	}
+
+	for key := range b {
+		if !current[key] {
+			positions = append(positions, ledgerPosition{account: key[0], commodity: key[1]})
+			changed = append(changed, key[0])
+			delete(b, key)
+		}
+	}
+
+	slices.SortFunc(positions, func(a, b ledgerPosition) int {
+		return cmp.Or(cmp.Compare(a.account, b.account), cmp.Compare(a.commodity, b.commodity))
+	})
+	slices.Sort(changed)
+
+	return positions, slices.Compact(changed)
+}
+
+// writeBeancount writes open directives for all ledger accounts, dated the day before the first snapshot,
+// followed by price and balance directives for each snapshot. Snapshots carry no transactions, so changed
+// accounts are padded from the opening balances account the day before their balance is asserted.
+func writeBeancount(w io.Writer, snapshots []portfolio.Snapshot, opts LedgerOptions) error {
+	sorted := sortedSnapshots(snapshots)
+
+	if accounts := ledgerAccounts(sorted, opts); len(accounts) > 0 {
+		date := sorted[0].Date.AddDate(0, 0, -1).Format(time.DateOnly)
+
+		for _, account := range append([]string{beancountOpeningAccount}, accounts...) {
+			if _, err := fmt.Fprintf(w, "%s open %s\n", date, account); err != nil {
+				return err
+			}
+		}
+
+		if _, err := fmt.Fprintln(w); err != nil {
+			return err
+		}
+	}
+
+	book := make(ledgerBook)
+
+	for _, snapshot := range sorted {
+		date := snapshot.DateString()
+		positions, changed := book.update(ledgerPositions(snapshot.Balances, opts, commodity))
+
+		for _, account := range changed {
+			padDate := snapshot.Date.AddDate(0, 0, -1).Format(time.DateOnly)
+			if _, err := fmt.Fprintf(w, "%s pad %s %s\n", padDate, account, beancountOpeningAccount); err != nil {
+				return err
+			}
+		}
+
+		for _, p := range ledgerPrices(snapshot.Balances, commodity) {
+			if _, err := fmt.Fprintf(w, "%s price %s %s %s\n", date, p.commodity, formatFloat(p.price), p.currency); err != nil {
+				return err
+			}
+		}
+
+		for _, p := range positions {
+			if _, err := fmt.Fprintf(w, "%s balance %s %s %s\n", date, p.account, formatFloat(p.amount), p.commodity); err != nil {
+				return err
+			}
+		}
+
+		if _, err := fmt.Fprintln(w); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeHledger writes market price directives and a balance assignment transaction for each snapshot,
+// balanced by the opening balances account as snapshots carry no transactions
+func writeHledger(w io.Writer, snapshots []portfolio.Snapshot, opts LedgerOptions) error {
+	book := make(ledgerBook)
+
+	for _, snapshot := range sortedSnapshots(snapshots) {
+		date := snapshot.DateString()
+		positions, _ := book.update(ledgerPositions(snapshot.Balances, opts, hledgerCommodity))
+
+		for _, p := range ledgerPrices(snapshot.Balances, hledgerCommodity) {
+			if _, err := fmt.Fprintf(w, "P %s %s %s %s\n", date, p.commodity, formatFloat(p.price), p.currency); err != nil {
+				return err
+			}
+		}
+
+		if len(positions) > 0 {
+			if _, err := fmt.Fprintf(w, "\n%s portosync balances\n", date); err != nil {
+				return err
+			}
+
+			for _, p := range positions {
+				if _, err := fmt.Fprintf(w, "    %s  = %s %s\n", p.account, formatFloat(p.amount), p.commodity); err != nil {
+					return err
+				}
+			}
+
+			if _, err := fmt.Fprintf(w, "    %s\n", hledgerOpeningAccount); err != nil {
+				return err
+			}
+		}
+
+		if _, err := fmt.Fprintln(w); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func sortedSnapshots(snapshots []portfolio.Snapshot) []portfolio.Snapshot {
+	sorted := slices.Clone(snapshots)
+	slices.SortStableFunc(sorted, func(a, b portfolio.Snapshot) int { return a.Date.Compare(b.Date) })
+
+	return sorted
+}
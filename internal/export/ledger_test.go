@@ -0,0 +1,240 @@
+package export
+
+import (
+	"bytes"
+	"cmp"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chickenzord/portosync/internal/portfolio"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLedgerOptions_LedgerAccount(t *testing.T) {
+	balance := portfolio.Balance{SourceType: "ksei", SourceAccount: "personal", AssetType: "mutual_fund"}
+
+	tests := []struct {
+		name     string
+		opts     LedgerOptions
+		expected string
+	}{
+		{
+			name:     "default template",
+			expected: "Assets:Investments:Personal:MutualFund",
+		},
+		{
+			name: "custom template and mappings",
+			opts: LedgerOptions{
+				AccountTemplate: "Assets:{source_type}:{account}:{asset_type}",
+				AccountNames:    map[string]string{"personal": "Me:Bibit"},
+				AssetTypeNames:  map[string]string{"mutual_fund": "Funds"},
+			},
+			expected: "Assets:Ksei:Me:Bibit:Funds",
+		},
+		{
+			name: "empty mapping removes component",
+			opts: LedgerOptions{
+				AssetTypeNames: map[string]string{"mutual_fund": ""},
+			},
+			expected: "Assets:Investments:Personal",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.opts.LedgerAccount(balance))
+		})
+	}
+}
+
+func TestCommodity(t *testing.T) {
+	assert.Equal(t, "BBCA", commodity("BBCA"))
+	assert.Equal(t, "FR0091", commodity("fr0091"))
+	assert.Equal(t, "X123", commodity("123"))
+	assert.Equal(t, "ABC-DEF", commodity("ABC DEF"))
+	assert.Equal(t, "ABCDEFGHIJKLMNOPQRSTUVWX", commodity("ABCDEFGHIJKLMNOPQRSTUVWXYZ"))
+	assert.Equal(t, `"FR0091"`, hledgerCommodity("FR0091"))
+	assert.Equal(t, "BBCA", hledgerCommodity("BBCA"))
+}
+
+func TestWrite_Beancount(t *testing.T) {
+	var buf bytes.Buffer
+
+	require.NoError(t, Write(&buf, FormatBeancount, testSnapshots, Options{}))
+
+	expected := "" +
+		"2025-12-31 open Equity:Opening-Balances\n" +
+		"2025-12-31 open Assets:Investments:Business:Equity\n" +
+		"2025-12-31 open Assets:Investments:Personal:Equity\n" +
+		"\n" +
+		"2025-12-31 pad Assets:Investments:Personal:Equity Equity:Opening-Balances\n" +
+		"2026-01-01 price TLKM 2900 IDR\n" +
+		"2026-01-01 balance Assets:Investments:Personal:Equity 500 TLKM\n" +
+		"\n" +
+		"2026-01-01 pad Assets:Investments:Business:Equity Equity:Opening-Balances\n" +
+		"2026-01-02 price BBCA 9500.005 IDR\n" +
+		"2026-01-02 price TLKM 3000 IDR\n" +
+		"2026-01-02 balance Assets:Investments:Business:Equity 100 BBCA\n" +
+		"2026-01-02 balance Assets:Investments:Personal:Equity 500 TLKM\n" +
+		"\n"
+
+	assert.Equal(t, expected, buf.String())
+	checkBeancount(t, buf.String())
+}
+
+func TestWrite_BeancountChanges(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, 1, d, 0, 0, 0, 0, time.Local) }
+	tlkm := func(amount float64) portfolio.Balance {
+		return portfolio.Balance{SourceAccount: "personal", AssetSymbol: "TLKM", AssetType: "equity", UnitsAmount: amount, UnitsValue: amount * 3000, UnitsCurrency: "IDR"}
+	}
+	bbca := portfolio.Balance{SourceAccount: "personal", AssetSymbol: "BBCA", AssetType: "equity", UnitsAmount: 100, UnitsValue: 950000, UnitsCurrency: "IDR"}
+
+	var buf bytes.Buffer
+
+	require.NoError(t, Write(&buf, FormatBeancount, []portfolio.Snapshot{
+		{Date: day(1), Balances: []portfolio.Balance{tlkm(500), bbca}},
+		{Date: day(2), Balances: []portfolio.Balance{tlkm(500), bbca}},
+		{Date: day(5), Balances: []portfolio.Balance{tlkm(800), bbca}},
+		{Date: day(6), Balances: []portfolio.Balance{bbca}},
+	}, Options{}))
+
+	out := buf.String()
+	checkBeancount(t, out)
+	assert.NotContains(t, out, "2026-01-01 pad", "unchanged amounts are not padded")
+	assert.Contains(t, out, "2026-01-04 pad Assets:Investments:Personal:Equity Equity:Opening-Balances\n")
+	assert.Contains(t, out, "2026-01-06 balance Assets:Investments:Personal:Equity 0 TLKM\n", "sold commodities are asserted empty")
+}
+
+// checkBeancount verifies the directives the way bean-check does: accounts are opened before use,
+// balances are checked at the start of their day and every pad is used by a following balance
+func checkBeancount(t *testing.T, text string) {
+	t.Helper()
+
+	type directive struct {
+		date, kind string
+		args       []string
+	}
+
+	order := map[string]int{"open": 0, "balance": 1, "price": 2, "pad": 2}
+
+	var directives []directive
+
+	for line := range strings.Lines(text) {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		require.GreaterOrEqual(t, len(fields), 3, line)
+		directives = append(directives, directive{date: fields[0], kind: fields[1], args: fields[2:]})
+	}
+
+	slices.SortStableFunc(directives, func(a, b directive) int {
+		return cmp.Or(cmp.Compare(a.date, b.date), cmp.Compare(order[a.kind], order[b.kind]))
+	})
+
+	opened := make(map[string]bool)
+	amounts := make(map[[2]string]string)
+	pads := make(map[string]bool) // pending pads by account, true once used
+
+	for _, d := range directives {
+		switch d.kind {
+		case "open":
+			opened[d.args[0]] = true
+		case "pad":
+			require.True(t, opened[d.args[0]] && opened[d.args[1]], "pad of unopened account %v", d)
+
+			used, pending := pads[d.args[0]]
+			require.True(t, !pending || used, "unused pad before %v", d)
+			pads[d.args[0]] = false
+		case "balance":
+			account, amount, commodity := d.args[0], d.args[1], d.args[2]
+			require.True(t, opened[account], "balance of unopened account %v", d)
+
+			key := [2]string{account, commodity}
+			if current := cmp.Or(amounts[key], "0"); current != amount {
+				_, pending := pads[account]
+				require.True(t, pending, "balance %v fails, the account has %s %s", d, current, commodity)
+
+				pads[account] = true
+				amounts[key] = amount
+			}
+		}
+	}
+
+	for account, used := range pads {
+		assert.True(t, used, "unused pad of %s", account)
+	}
+}
+
+func TestWrite_Hledger(t *testing.T) {
+	var buf bytes.Buffer
+
+	require.NoError(t, Write(&buf, FormatHledger, testSnapshots, Options{}))
+
+	expected := "" +
+		"P 2026-01-01 TLKM 2900 IDR\n" +
+		"\n" +
+		"2026-01-01 portosync balances\n" +
+		"    Assets:Investments:Personal:Equity  = 500 TLKM\n" +
+		"    equity:opening-balances\n" +
+		"\n" +
+		"P 2026-01-02 BBCA 9500.005 IDR\n" +
+		"P 2026-01-02 TLKM 3000 IDR\n" +
+		"\n" +
+		"2026-01-02 portosync balances\n" +
+		"    Assets:Investments:Business:Equity  = 100 BBCA\n" +
+		"    Assets:Investments:Personal:Equity  = 500 TLKM\n" +
+		"    equity:opening-balances\n" +
+		"\n"
+
+	assert.Equal(t, expected, buf.String())
+
+	// Commodities no longer held are assigned zero
+	buf.Reset()
+	require.NoError(t, Write(&buf, FormatHledger, []portfolio.Snapshot{testSnapshots[0], {Date: time.Date(2026, 1, 3, 0, 0, 0, 0, time.Local)}}, Options{}))
+	assert.Contains(t, buf.String(), "2026-01-03 portosync balances\n"+
+		"    Assets:Investments:Business:Equity  = 0 BBCA\n"+
+		"    Assets:Investments:Personal:Equity  = 0 TLKM\n"+
+		"    equity:opening-balances\n")
+}
+
+func TestWrite_LedgerSubAccounts(t *testing.T) {
+	snapshots := []portfolio.Snapshot{
+		{
+			Date: time.Date(2026, 1, 1, 0, 0, 0, 0, time.Local),
+			Balances: []portfolio.Balance{
+				{SourceType: "ksei", SourceAccount: "personal", AssetSymbol: "BBCA", AssetType: "equity", UnitsAmount: 100, UnitsValue: 950000, UnitsCurrency: "IDR", Participant: "Broker A", SubAccount: "001"},
+				{SourceType: "ksei", SourceAccount: "personal", AssetSymbol: "BBCA", AssetType: "equity", UnitsAmount: 300, UnitsValue: 2850000, UnitsCurrency: "IDR", Participant: "Broker B", SubAccount: "002"},
+			},
+		},
+	}
+
+	var beancount bytes.Buffer
+
+	require.NoError(t, Write(&beancount, FormatBeancount, snapshots, Options{}))
+
+	assert.Equal(t, ""+
+		"2025-12-31 open Equity:Opening-Balances\n"+
+		"2025-12-31 open Assets:Investments:Personal:Equity\n"+
+		"\n"+
+		"2025-12-31 pad Assets:Investments:Personal:Equity Equity:Opening-Balances\n"+
+		"2026-01-01 price BBCA 9500 IDR\n"+
+		"2026-01-01 balance Assets:Investments:Personal:Equity 400 BBCA\n"+
+		"\n", beancount.String(), "sub-accounts holding the same symbol are asserted once")
+
+	var hledger bytes.Buffer
+
+	require.NoError(t, Write(&hledger, FormatHledger, snapshots, Options{}))
+
+	assert.Equal(t, ""+
+		"P 2026-01-01 BBCA 9500 IDR\n"+
+		"\n"+
+		"2026-01-01 portosync balances\n"+
+		"    Assets:Investments:Personal:Equity  = 400 BBCA\n"+
+		"    equity:opening-balances\n"+
+		"\n", hledger.String())
+}
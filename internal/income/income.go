@@ -0,0 +1,253 @@
+// Package income records dividends, coupons and other income received from holdings
+package income
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chickenzord/portosync/internal/datafile"
+	"github.com/chickenzord/portosync/internal/portfolio"
+)
+
+// Income types
+const (
+	TypeDividend     = "dividend"
+	TypeCoupon       = "coupon"
+	TypeInterest     = "interest"
+	TypeDistribution = "distribution"
+)
+
+// Types lists supported income types
+var Types = []string{TypeDividend, TypeCoupon, TypeInterest, TypeDistribution}
+
+// Entry origins
+const (
+	OriginFile      = "file"
+	OriginManual    = "manual"
+	OriginProjected = "projected"
+)
+
+// defaultCurrency is used for entries without a currency
+const defaultCurrency = "IDR"
+
+// Entry is income paid by a holding on a date. Entries dated in the future are expected payments.
+type Entry struct {
+	Date     time.Time `json:"date"             jsonschema:"description:Payment date"`
+	Account  string    `json:"account"          jsonschema:"description:Account name holding the asset"`
+	Symbol   string    `json:"symbol"           jsonschema:"description:Asset symbol or fund code paying the income"`
+	Type     string    `json:"type"             jsonschema:"description:dividend, coupon, interest or distribution"`
+	Amount   float64   `json:"amount"           jsonschema:"description:Gross amount before tax"`
+	Tax      float64   `json:"tax,omitempty"    jsonschema:"description:Tax withheld from the gross amount"`
+	Currency string    `json:"currency"         jsonschema:"description:Currency of the amount"`
+	Note     string    `json:"note,omitempty"   jsonschema:"description:Free-text note"`
+	Origin   string    `json:"origin,omitempty" jsonschema:"description:file when read from the income file, manual when recorded with a tool, projected when expected from past payments"`
+}
+
+// Net returns the amount received after tax
+func (e Entry) Net() float64 {
+	return e.Amount - e.Tax
+}
+
+// Key identifies the entry by date, account, symbol and type, one payment per day each
+func (e Entry) Key() string {
+	return strings.Join([]string{e.Date.Format(time.DateOnly), e.Account, strings.ToUpper(e.Symbol), e.Type}, "|")
+}
+
+// Validate normalizes the entry and reports invalid fields
+func (e *Entry) Validate() error {
+	e.Symbol = strings.ToUpper(strings.TrimSpace(e.Symbol))
+	e.Type = strings.ToLower(strings.TrimSpace(e.Type))
+	e.Currency = strings.ToUpper(cmp.Or(strings.TrimSpace(e.Currency), defaultCurrency))
+	e.Date = portfolio.DateOf(e.Date)
+
+	switch {
+	case e.Account == "":
+		return errors.New("account is required")
+	case e.Symbol == "":
+		return errors.New("symbol is required")
+	case !slices.Contains(Types, e.Type):
+		return fmt.Errorf("unknown type %q, supported types are %s", e.Type, strings.Join(Types, ", "))
+	case e.Amount <= 0:
+		return errors.New("amount must be positive")
+	case e.Tax < 0 || e.Tax > e.Amount:
+		return errors.New("tax must be between zero and the amount")
+	}
+
+	return nil
+}
+
+// Load reads income entries from a CSV file with header columns
+// date, account, symbol, type, amount and optionally tax, currency and note.
+// Entries are returned ordered by date.
+func Load(path string) ([]Entry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	entries, err := Read(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return entries, nil
+}
+
+// Read reads income entries in the CSV format described in Load
+func Read(r io.Reader) ([]Entry, error) {
+	columns, records, err := datafile.ReadCSV(r, ',')
+	if err != nil {
+		return nil, err
+	}
+
+	if len(columns) == 0 {
+		return nil, nil
+	}
+
+	if err := datafile.RequireColumns(columns, "date", "account", "symbol", "type", "amount"); err != nil {
+		return nil, err
+	}
+
+	var entries []Entry
+
+	for i, record := range records {
+		if record.Get("symbol") == "" {
+			continue
+		}
+
+		e, err := parseRow(record.Get)
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", i+2, err)
+		}
+
+		entries = append(entries, e)
+	}
+
+	Sort(entries)
+
+	return entries, nil
+}
+
+func parseRow(field func(name string) string) (Entry, error) {
+	date, err := portfolio.ParseDate(field("date"))
+	if err != nil {
+		return Entry{}, fmt.Errorf("invalid date: %w", err)
+	}
+
+	e := Entry{
+		Date:     date,
+		Account:  field("account"),
+		Symbol:   field("symbol"),
+		Type:     field("type"),
+		Currency: field("currency"),
+		Note:     field("note"),
+		Origin:   OriginFile,
+	}
+
+	for name, dst := range map[string]*float64{"amount": &e.Amount, "tax": &e.Tax} {
+		s := strings.ReplaceAll(field(name), ",", "")
+		if s == "" {
+			continue
+		}
+
+		if *dst, err = strconv.ParseFloat(s, 64); err != nil {
+			return e, fmt.Errorf("invalid %s: %w", name, err)
+		}
+	}
+
+	return e, e.Validate()
+}
+
+// Sort orders entries by date, account, symbol and type
+func Sort(entries []Entry) {
+	slices.SortStableFunc(entries, func(a, b Entry) int {
+		return cmp.Or(
+			a.Date.Compare(b.Date),
+			cmp.Compare(a.Account, b.Account),
+			cmp.Compare(a.Symbol, b.Symbol),
+			cmp.Compare(a.Type, b.Type),
+		)
+	})
+}
+
+// Store keeps manually recorded income entries in a JSON file
+type Store struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewStore creates a store backed by the file at path, which is created on first write
+func NewStore(path string) *Store {
+	return &Store{path: path}
+}
+
+// Entries returns all stored entries ordered by date
+func (s *Store) Entries() ([]Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.read()
+}
+
+// Set stores the entry, replacing any entry with the same key
+func (s *Store) Set(entry Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, err := s.read()
+	if err != nil {
+		return err
+	}
+
+	entry.Origin = OriginManual
+	entries = slices.DeleteFunc(entries, func(e Entry) bool { return e.Key() == entry.Key() })
+
+	return s.write(append(entries, entry))
+}
+
+// Delete removes the entry with the same key as entry, reporting whether it existed
+func (s *Store) Delete(entry Entry) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, err := s.read()
+	if err != nil {
+		return false, err
+	}
+
+	remaining := slices.DeleteFunc(entries, func(e Entry) bool { return e.Key() == entry.Key() })
+	if len(remaining) == len(entries) {
+		return false, nil
+	}
+
+	return true, s.write(remaining)
+}
+
+func (s *Store) read() ([]Entry, error) {
+	var entries []Entry
+	if _, err := datafile.ReadJSON(s.path, &entries); err != nil {
+		return nil, err
+	}
+
+	// Decoded dates carry a fixed zone, use local dates like entries read from CSV
+	for i := range entries {
+		entries[i].Date = portfolio.DateOf(entries[i].Date.In(time.Local))
+	}
+
+	return entries, nil
+}
+
+func (s *Store) write(entries []Entry) error {
+	Sort(entries)
+
+	return datafile.WriteJSON(s.path, entries)
+}
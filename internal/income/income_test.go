@@ -0,0 +1,122 @@
+package income
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chickenzord/portosync/internal/portfolio"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func date(s string) time.Time {
+	d, err := portfolio.ParseDate(s)
+	if err != nil {
+		panic(err)
+	}
+
+	return d
+}
+
+func TestRead(t *testing.T) {
+	entries, err := Read(strings.NewReader(`date,account,symbol,type,amount,tax,currency,note
+2024-05-20,personal,bbca,Dividend,"1,350,000",135000,,final dividend
+2024-03-15,personal,FR0098,coupon,1781250,178125,idr,
+,,,,,,,
+`))
+	require.NoError(t, err)
+
+	assert.Equal(t, []Entry{
+		{Date: date("2024-03-15"), Account: "personal", Symbol: "FR0098", Type: TypeCoupon, Amount: 1781250, Tax: 178125, Currency: "IDR", Origin: OriginFile},
+		{Date: date("2024-05-20"), Account: "personal", Symbol: "BBCA", Type: TypeDividend, Amount: 1350000, Tax: 135000, Currency: "IDR", Note: "final dividend", Origin: OriginFile},
+	}, entries)
+	assert.Equal(t, 1215000.0, entries[1].Net())
+
+	for _, input := range []string{
+		"date,account,symbol,type\n",
+		"date,account,symbol,type,amount\n20/05/2024,personal,BBCA,dividend,1\n",
+		"date,account,symbol,type,amount\n2024-05-20,personal,BBCA,bonus,1\n",
+		"date,account,symbol,type,amount\n2024-05-20,personal,BBCA,dividend,0\n",
+		"date,account,symbol,type,amount,tax\n2024-05-20,personal,BBCA,dividend,1,2\n",
+		"date,account,symbol,type,amount\n2024-05-20,,BBCA,dividend,1\n",
+	} {
+		_, err := Read(strings.NewReader(input))
+		assert.Error(t, err, input)
+	}
+}
+
+func TestStore(t *testing.T) {
+	store := NewStore(filepath.Join(t.TempDir(), "income.json"))
+
+	entries, err := store.Entries()
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	entry := Entry{Date: date("2024-05-20"), Account: "personal", Symbol: "BBCA", Type: TypeDividend, Amount: 100, Currency: "IDR"}
+	require.NoError(t, store.Set(entry))
+
+	entry.Amount = 150
+	require.NoError(t, store.Set(entry))
+	require.NoError(t, store.Set(Entry{Date: date("2024-03-15"), Account: "personal", Symbol: "FR0098", Type: TypeCoupon, Amount: 50, Currency: "IDR"}))
+
+	entries, err = store.Entries()
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "FR0098", entries[0].Symbol)
+	assert.Equal(t, 150.0, entries[1].Amount)
+	assert.Equal(t, OriginManual, entries[1].Origin)
+	assert.Equal(t, entry.Key(), entries[1].Key())
+
+	removed, err := store.Delete(entry)
+	require.NoError(t, err)
+	assert.True(t, removed)
+
+	removed, err = store.Delete(entry)
+	require.NoError(t, err)
+	assert.False(t, removed)
+}
+
+func TestProjectAndSummarize(t *testing.T) {
+	entries := []Entry{
+		{Date: date("2023-03-15"), Account: "personal", Symbol: "FR0098", Type: TypeCoupon, Amount: 100, Tax: 10, Currency: "IDR"},
+		{Date: date("2023-09-15"), Account: "personal", Symbol: "FR0098", Type: TypeCoupon, Amount: 100, Tax: 10, Currency: "IDR"},
+		{Date: date("2024-03-15"), Account: "personal", Symbol: "FR0098", Type: TypeCoupon, Amount: 100, Tax: 10, Currency: "IDR"},
+		{Date: date("2023-05-20"), Account: "personal", Symbol: "BBCA", Type: TypeDividend, Amount: 50, Currency: "IDR"},
+		{Date: date("2024-05-02"), Account: "personal", Symbol: "BBCA", Type: TypeDividend, Amount: 60, Currency: "IDR"},
+		{Date: date("2023-11-01"), Account: "personal", Symbol: "SOLD", Type: TypeDividend, Amount: 70, Currency: "IDR"},
+		{Date: date("2023-12-01"), Account: "personal", Symbol: "AAPL", Type: TypeDividend, Amount: 1, Currency: "USD"},
+	}
+
+	held := func(account, symbol string) bool { return symbol != "SOLD" }
+
+	projected := Project(entries, date("2024-04-01"), held)
+
+	var got []string
+	for _, e := range projected {
+		assert.Equal(t, OriginProjected, e.Origin)
+		got = append(got, e.Date.Format(time.DateOnly)+" "+e.Symbol)
+	}
+
+	assert.Equal(t, []string{"2024-09-15 FR0098", "2024-12-01 AAPL", "2025-03-15 FR0098"}, got, "2024-05-02 BBCA is recorded in May already")
+
+	all := append(entries, projected...)
+	Sort(all)
+
+	summary := Summarize(all, date("2024-04-01"))
+
+	assert.Equal(t, []Totals{
+		{Currency: "IDR", Received: 50 + 70 + 90*3, Projected: 60 + 90*2, Tax: 30, Payments: 8},
+		{Currency: "USD", Received: 1, Projected: 1, Payments: 2},
+	}, summary.Totals)
+
+	require.NotEmpty(t, summary.Months)
+	assert.Equal(t, MonthTotals{Month: "2023-03", Totals: Totals{Currency: "IDR", Received: 90, Tax: 10, Payments: 1}}, summary.Months[0])
+
+	require.NotEmpty(t, summary.Assets)
+	assert.Equal(t, AssetTotals{
+		Account: "personal", Symbol: "FR0098", Type: TypeCoupon, LastPaid: "2024-03-15",
+		Totals: Totals{Currency: "IDR", Received: 270, Projected: 180, Tax: 30, Payments: 5},
+	}, summary.Assets[0])
+}
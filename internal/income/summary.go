@@ -0,0 +1,157 @@
+package income
+
+import (
+	"cmp"
+	"slices"
+	"time"
+)
+
+// Project returns payments expected in the year after today by repeating every payment
+// received in the year up to today one year later, for holdings that held reports as still held.
+// Expected payments already recorded for the same account, symbol and type in the same month take precedence.
+func Project(entries []Entry, today time.Time, held func(account, symbol string) bool) []Entry {
+	type key struct {
+		month                 string
+		account, symbol, kind string
+	}
+
+	recorded := make(map[key]bool)
+
+	for _, e := range entries {
+		if e.Date.After(today) {
+			recorded[key{e.Date.Format("2006-01"), e.Account, e.Symbol, e.Type}] = true
+		}
+	}
+
+	var projected []Entry
+
+	for _, e := range entries {
+		if e.Date.After(today) || !e.Date.After(today.AddDate(-1, 0, 0)) || !held(e.Account, e.Symbol) {
+			continue
+		}
+
+		p := e
+		p.Date = e.Date.AddDate(1, 0, 0)
+		p.Origin = OriginProjected
+		p.Note = "expected from payment on " + e.Date.Format(time.DateOnly)
+
+		k := key{p.Date.Format("2006-01"), p.Account, p.Symbol, p.Type}
+		if recorded[k] {
+			continue
+		}
+
+		projected = append(projected, p)
+	}
+
+	Sort(projected)
+
+	return projected
+}
+
+// Totals are net income amounts in a single currency, split into received and expected income
+type Totals struct {
+	Currency  string  `json:"currency"  jsonschema:"description:Currency of the amounts"`
+	Received  float64 `json:"received"  jsonschema:"description:Net income received up to today"`
+	Projected float64 `json:"projected" jsonschema:"description:Net income expected after today"`
+	Tax       float64 `json:"tax"       jsonschema:"description:Tax withheld from income received"`
+	Payments  int     `json:"payments"  jsonschema:"description:Number of payments received or expected"`
+}
+
+func (t *Totals) add(e Entry, today time.Time) {
+	t.Payments++
+
+	if e.Date.After(today) {
+		t.Projected += e.Net()
+
+		return
+	}
+
+	t.Received += e.Net()
+	t.Tax += e.Tax
+}
+
+// MonthTotals are income totals of a calendar month
+type MonthTotals struct {
+	Month string `json:"month" jsonschema:"description:Calendar month (YYYY-MM)"`
+	Totals
+}
+
+// AssetTotals are income totals of a symbol held in an account
+type AssetTotals struct {
+	Account  string `json:"account"             jsonschema:"description:Account name holding the asset"`
+	Symbol   string `json:"symbol"              jsonschema:"description:Asset symbol or fund code"`
+	Type     string `json:"type"                jsonschema:"description:Income type of the latest payment"`
+	LastPaid string `json:"last_paid,omitempty" jsonschema:"description:Date of the latest payment received (YYYY-MM-DD)"`
+	Totals
+}
+
+// Summary totals income overall, per month and per asset, in separate rows per currency
+type Summary struct {
+	Totals []Totals      `json:"totals" jsonschema:"description:Totals per currency"`
+	Months []MonthTotals `json:"months" jsonschema:"description:Totals per month and currency in ascending order"`
+	Assets []AssetTotals `json:"assets" jsonschema:"description:Totals per account, symbol and currency, highest income first"`
+}
+
+// Summarize totals entries ordered by date, counting entries after today as expected income
+func Summarize(entries []Entry, today time.Time) Summary {
+	totals := make(map[string]*Totals)
+	months := make(map[[2]string]*MonthTotals)
+	assets := make(map[[3]string]*AssetTotals)
+
+	for _, e := range entries {
+		if totals[e.Currency] == nil {
+			totals[e.Currency] = &Totals{Currency: e.Currency}
+		}
+
+		totals[e.Currency].add(e, today)
+
+		month := e.Date.Format("2006-01")
+		if months[[2]string{month, e.Currency}] == nil {
+			months[[2]string{month, e.Currency}] = &MonthTotals{Month: month, Totals: Totals{Currency: e.Currency}}
+		}
+
+		months[[2]string{month, e.Currency}].add(e, today)
+
+		k := [3]string{e.Account, e.Symbol, e.Currency}
+		if assets[k] == nil {
+			assets[k] = &AssetTotals{Account: e.Account, Symbol: e.Symbol, Totals: Totals{Currency: e.Currency}}
+		}
+
+		a := assets[k]
+		a.add(e, today)
+		a.Type = e.Type
+
+		if !e.Date.After(today) {
+			a.LastPaid = e.Date.Format(time.DateOnly)
+		}
+	}
+
+	var summary Summary
+
+	for _, t := range totals {
+		summary.Totals = append(summary.Totals, *t)
+	}
+
+	for _, m := range months {
+		summary.Months = append(summary.Months, *m)
+	}
+
+	for _, a := range assets {
+		summary.Assets = append(summary.Assets, *a)
+	}
+
+	slices.SortFunc(summary.Totals, func(a, b Totals) int { return cmp.Compare(a.Currency, b.Currency) })
+	slices.SortFunc(summary.Months, func(a, b MonthTotals) int {
+		return cmp.Or(cmp.Compare(a.Month, b.Month), cmp.Compare(a.Currency, b.Currency))
+	})
+	slices.SortFunc(summary.Assets, func(a, b AssetTotals) int {
+		return cmp.Or(
+			cmp.Compare(a.Currency, b.Currency),
+			cmp.Compare(b.Received+b.Projected, a.Received+a.Projected),
+			cmp.Compare(a.Account, b.Account),
+			cmp.Compare(a.Symbol, b.Symbol),
+		)
+	})
+
+	return summary
+}
@@ -0,0 +1,384 @@
+// Package networth builds the total portfolio value over time from snapshots, converted to one currency
+package networth
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/chickenzord/portosync/internal/portfolio"
+)
+
+// Resolutions of a timeline. Auto picks days for up to three months, weeks for up to two years and months beyond.
+const (
+	ResolutionAuto  = "auto"
+	ResolutionDay   = "day"
+	ResolutionWeek  = "week"
+	ResolutionMonth = "month"
+)
+
+// Resolutions lists supported resolutions
+var Resolutions = []string{ResolutionAuto, ResolutionDay, ResolutionWeek, ResolutionMonth}
+
+// Fill rules for periods without a snapshot
+const (
+	FillPrevious = "previous" // carry the last known value forward
+	FillLinear   = "linear"   // interpolate between the surrounding snapshots
+	FillNone     = "none"     // leave the period out
+)
+
+// Fills lists supported fill rules
+var Fills = []string{FillPrevious, FillLinear, FillNone}
+
+// Spans up to which auto resolution uses days and weeks
+const (
+	maxDailyDays  = 92
+	maxWeeklyDays = 731
+)
+
+// Options configures how a timeline is built
+type Options struct {
+	Currency   string
+	Resolution string // one of Resolutions, auto when empty
+	Fill       string // one of Fills, previous when empty
+
+	// Accounts limits the timeline to these accounts, all accounts when nil
+	Accounts []string
+
+	// Rate returns the value of one unit of currency in Currency at the date
+	Rate func(currency string, date time.Time) (float64, bool)
+}
+
+// Point is the net worth at the end of a period
+type Point struct {
+	Date            string   `json:"date"                       jsonschema:"description:First day of the period (YYYY-MM-DD), or the first day of the timeline for a period cut short"`
+	AsOf            string   `json:"as_of,omitempty"            jsonschema:"description:Date of the snapshot the value was taken from, the last one in the period, or the one carried forward into a filled period"`
+	Value           float64  `json:"value"                      jsonschema:"description:Total value of all holdings and cash"`
+	SecuritiesValue float64  `json:"securities_value"           jsonschema:"description:Value of non-cash holdings"`
+	CashValue       float64  `json:"cash_value"                 jsonschema:"description:Value of cash balances"`
+	Change          float64  `json:"change"                     jsonschema:"description:Change in value since the previous point"`
+	ChangePercent   float64  `json:"change_percent"             jsonschema:"description:Change in value since the previous point in percent"`
+	Filled          bool     `json:"filled,omitempty"           jsonschema:"description:True when the period has no snapshot and its value was filled in by the fill rule"`
+	CarriedAccounts []string `json:"carried_accounts,omitempty" jsonschema:"description:Accounts missing from the snapshot, e.g. after a failed fetch, valued at their last known snapshot instead"`
+}
+
+// Timeline is the net worth per period between two dates
+type Timeline struct {
+	Currency      string   `json:"currency"              jsonschema:"description:Currency all values are converted to"`
+	Resolution    string   `json:"resolution"            jsonschema:"description:Length of each period: day, week (starting Monday) or month"`
+	Fill          string   `json:"fill"                  jsonschema:"description:Rule used for periods without a snapshot: previous, linear or none"`
+	From          string   `json:"from,omitempty"        jsonschema:"description:First day of the timeline (YYYY-MM-DD)"`
+	To            string   `json:"to,omitempty"          jsonschema:"description:Last day of the timeline (YYYY-MM-DD)"`
+	Points        []Point  `json:"points"                jsonschema:"description:Net worth per period in ascending date order"`
+	StartValue    float64  `json:"start_value"           jsonschema:"description:Value of the first point"`
+	EndValue      float64  `json:"end_value"             jsonschema:"description:Value of the last point"`
+	Change        float64  `json:"change"                jsonschema:"description:End value minus start value"`
+	ChangePercent float64  `json:"change_percent"        jsonschema:"description:Change in percent of the start value"`
+	High          *Point   `json:"high,omitempty"        jsonschema:"description:Point with the highest value"`
+	Low           *Point   `json:"low,omitempty"         jsonschema:"description:Point with the lowest value"`
+	Filled        int      `json:"filled"                jsonschema:"description:Number of points filled in for periods without a snapshot"`
+	Unconverted   []string `json:"unconverted,omitempty" jsonschema:"description:Currencies without an exchange rate on some snapshot date, whose balances were left out on those dates"`
+}
+
+// observation is the value of a snapshot, with missing accounts carried forward
+type observation struct {
+	date       time.Time
+	securities float64
+	cash       float64
+	carried    []string
+}
+
+func (o observation) value() float64 {
+	return o.securities + o.cash
+}
+
+// Build returns the net worth per period between from and to from snapshots ordered by date.
+// Snapshots before from are used to carry values forward into the first periods.
+// A zero from or to starts or ends the timeline at the first or last snapshot.
+func Build(snapshots []portfolio.Snapshot, from, to time.Time, opts Options) Timeline {
+	timeline := Timeline{
+		Currency:   opts.Currency,
+		Resolution: opts.Resolution,
+		Fill:       opts.Fill,
+		Points:     []Point{},
+	}
+
+	if timeline.Fill == "" {
+		timeline.Fill = FillPrevious
+	}
+
+	observations := observe(snapshots, opts, &timeline)
+	if len(observations) == 0 {
+		return timeline
+	}
+
+	if from.IsZero() {
+		from = observations[0].date
+	}
+
+	if to.IsZero() {
+		to = observations[len(observations)-1].date
+	}
+
+	from, to = portfolio.DateOf(from), portfolio.DateOf(to)
+	if to.Before(from) {
+		return timeline
+	}
+
+	if timeline.Resolution == "" || timeline.Resolution == ResolutionAuto {
+		timeline.Resolution = autoResolution(from, to)
+	}
+
+	timeline.From = from.Format(time.DateOnly)
+	timeline.To = to.Format(time.DateOnly)
+
+	for start := periodStart(from, timeline.Resolution); !start.After(to); start = nextPeriod(start, timeline.Resolution) {
+		// The first and last periods may be cut short by the timeline range
+		first, end := start, nextPeriod(start, timeline.Resolution).AddDate(0, 0, -1)
+		if first.Before(from) {
+			first = from
+		}
+
+		if end.After(to) {
+			end = to
+		}
+
+		point, ok := pointAt(observations, first, end, timeline.Fill)
+		if !ok {
+			continue
+		}
+
+		if point.Filled {
+			timeline.Filled++
+		}
+
+		timeline.Points = append(timeline.Points, point)
+	}
+
+	summarize(&timeline)
+
+	return timeline
+}
+
+// observe values every snapshot in the options currency. Accounts present in an earlier snapshot
+// but not fetched into a later one keep their last known value until they reappear, while accounts
+// fetched without holdings are valued at zero from then on.
+func observe(snapshots []portfolio.Snapshot, opts Options, timeline *Timeline) []observation {
+	type accountValue struct{ securities, cash float64 }
+
+	known := make(map[string]accountValue)
+
+	var observations []observation
+
+	for _, s := range snapshots {
+		present := make(map[string]accountValue)
+
+		for _, b := range s.Balances {
+			if opts.Accounts != nil && !slices.Contains(opts.Accounts, b.SourceAccount) {
+				continue
+			}
+
+			value, ok := convert(b, s.Date, opts)
+			if !ok {
+				if !slices.Contains(timeline.Unconverted, b.UnitsCurrency) {
+					timeline.Unconverted = append(timeline.Unconverted, b.UnitsCurrency)
+				}
+
+				continue
+			}
+
+			v := present[b.SourceAccount]
+			if b.IsCash() {
+				v.cash += value
+			} else {
+				v.securities += value
+			}
+
+			present[b.SourceAccount] = v
+		}
+
+		var emptied bool
+
+		for _, account := range s.FetchedAccounts() {
+			if opts.Accounts != nil && !slices.Contains(opts.Accounts, account) {
+				continue
+			}
+
+			if _, ok := present[account]; !ok && !hasBalances(s, account) {
+				delete(known, account)
+
+				emptied = true
+			}
+		}
+
+		if len(present) == 0 && !emptied {
+			continue
+		}
+
+		o := observation{date: portfolio.DateOf(s.Date)}
+
+		for account, v := range known {
+			if _, ok := present[account]; !ok {
+				o.securities += v.securities
+				o.cash += v.cash
+				o.carried = append(o.carried, account)
+			}
+		}
+
+		for account, v := range present {
+			o.securities += v.securities
+			o.cash += v.cash
+			known[account] = v
+		}
+
+		slices.Sort(o.carried)
+		observations = append(observations, o)
+	}
+
+	slices.Sort(timeline.Unconverted)
+
+	return observations
+}
+
+// hasBalances reports whether the snapshot holds any balance of the account
+func hasBalances(s portfolio.Snapshot, account string) bool {
+	return slices.ContainsFunc(s.Balances, func(b portfolio.Balance) bool { return b.SourceAccount == account })
+}
+
+// convert returns the balance value in the options currency
+func convert(b portfolio.Balance, date time.Time, opts Options) (float64, bool) {
+	if strings.EqualFold(b.UnitsCurrency, opts.Currency) {
+		return b.UnitsValue, true
+	}
+
+	if opts.Rate == nil {
+		return 0, false
+	}
+
+	rate, ok := opts.Rate(strings.ToUpper(b.UnitsCurrency), date)
+	if !ok {
+		return 0, false
+	}
+
+	return b.UnitsValue * rate, true
+}
+
+// pointAt returns the point of the period from start to end, using the last observation in the period
+// or, when there is none, the fill rule
+func pointAt(observations []observation, start, end time.Time, fill string) (Point, bool) {
+	// Index of the first observation after the period
+	next, _ := slices.BinarySearchFunc(observations, end, func(o observation, d time.Time) int {
+		if o.date.After(d) {
+			return 1
+		}
+
+		return -1
+	})
+
+	point := Point{Date: start.Format(time.DateOnly)}
+
+	if next > 0 && !observations[next-1].date.Before(start) {
+		o := observations[next-1]
+		point.AsOf = o.date.Format(time.DateOnly)
+		point.SecuritiesValue, point.CashValue = o.securities, o.cash
+		point.CarriedAccounts = o.carried
+		point.Value = o.value()
+
+		return point, true
+	}
+
+	if next == 0 || fill == FillNone {
+		return point, false
+	}
+
+	prev := observations[next-1]
+	point.Filled = true
+	point.AsOf = prev.date.Format(time.DateOnly)
+	point.SecuritiesValue, point.CashValue = prev.securities, prev.cash
+
+	if fill == FillLinear && next < len(observations) {
+		following := observations[next]
+		w := end.Sub(prev.date).Hours() / following.date.Sub(prev.date).Hours()
+
+		point.AsOf = ""
+		point.SecuritiesValue += (following.securities - prev.securities) * w
+		point.CashValue += (following.cash - prev.cash) * w
+	}
+
+	point.Value = point.SecuritiesValue + point.CashValue
+
+	return point, true
+}
+
+// summarize sets the changes between points and the summary of the timeline
+func summarize(timeline *Timeline) {
+	points := timeline.Points
+	if len(points) == 0 {
+		return
+	}
+
+	high, low := 0, 0
+
+	for i := range points {
+		if i > 0 {
+			points[i].Change, points[i].ChangePercent = change(points[i-1].Value, points[i].Value)
+		}
+
+		if points[i].Value > points[high].Value {
+			high = i
+		}
+
+		if points[i].Value < points[low].Value {
+			low = i
+		}
+	}
+
+	timeline.StartValue = points[0].Value
+	timeline.EndValue = points[len(points)-1].Value
+	timeline.Change, timeline.ChangePercent = change(timeline.StartValue, timeline.EndValue)
+	timeline.High = &points[high]
+	timeline.Low = &points[low]
+}
+
+func change(from, to float64) (float64, float64) {
+	if from == 0 {
+		return to - from, 0
+	}
+
+	return to - from, (to - from) * 100 / from
+}
+
+func autoResolution(from, to time.Time) string {
+	switch days := to.Sub(from).Hours() / 24; {
+	case days <= maxDailyDays:
+		return ResolutionDay
+	case days <= maxWeeklyDays:
+		return ResolutionWeek
+	default:
+		return ResolutionMonth
+	}
+}
+
+// periodStart returns the first day of the period containing date
+func periodStart(date time.Time, resolution string) time.Time {
+	switch resolution {
+	case ResolutionWeek:
+		return date.AddDate(0, 0, -(int(date.Weekday())+6)%7)
+	case ResolutionMonth:
+		return time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, date.Location())
+	default:
+		return date
+	}
+}
+
+// nextPeriod returns the first day of the period following the one starting at start
+func nextPeriod(start time.Time, resolution string) time.Time {
+	switch resolution {
+	case ResolutionWeek:
+		return start.AddDate(0, 0, 7)
+	case ResolutionMonth:
+		return start.AddDate(0, 1, 0)
+	default:
+		return start.AddDate(0, 0, 1)
+	}
+}
@@ -0,0 +1,179 @@
+package networth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/chickenzord/portosync/internal/portfolio"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func date(s string) time.Time {
+	d, err := portfolio.ParseDate(s)
+	if err != nil {
+		panic(err)
+	}
+
+	return d
+}
+
+func snapshot(d string, balances ...portfolio.Balance) portfolio.Snapshot {
+	return portfolio.Snapshot{Date: date(d), Balances: balances}
+}
+
+func balance(account, symbol string, value float64, currency string) portfolio.Balance {
+	assetType := "equity"
+	if symbol == currency {
+		assetType = portfolio.AssetTypeCash
+	}
+
+	return portfolio.Balance{SourceAccount: account, AssetSymbol: symbol, AssetType: assetType, UnitsValue: value, UnitsCurrency: currency}
+}
+
+func values(points []Point) map[string]float64 {
+	result := make(map[string]float64, len(points))
+	for _, p := range points {
+		result[p.Date] = p.Value
+	}
+
+	return result
+}
+
+func TestBuild_Daily(t *testing.T) {
+	snapshots := []portfolio.Snapshot{
+		snapshot("2024-01-01", balance("personal", "BBCA", 1000, "IDR"), balance("personal", "IDR", 100, "IDR"), balance("broker", "AAPL", 10, "USD")),
+		snapshot("2024-01-02", balance("personal", "BBCA", 1100, "IDR"), balance("personal", "IDR", 100, "IDR")),
+		snapshot("2024-01-04", balance("personal", "BBCA", 1300, "IDR"), balance("personal", "IDR", 100, "IDR"), balance("broker", "AAPL", 12, "USD")),
+	}
+
+	rates := map[string]float64{"USD": 100}
+	opts := Options{
+		Currency: "IDR",
+		Rate: func(currency string, _ time.Time) (float64, bool) {
+			rate, ok := rates[currency]
+
+			return rate, ok
+		},
+	}
+
+	timeline := Build(snapshots, time.Time{}, time.Time{}, opts)
+	assert.Equal(t, ResolutionDay, timeline.Resolution)
+	assert.Equal(t, FillPrevious, timeline.Fill)
+	assert.Equal(t, "2024-01-01", timeline.From)
+	assert.Equal(t, "2024-01-04", timeline.To)
+	require.Len(t, timeline.Points, 4)
+
+	assert.Equal(t, map[string]float64{
+		"2024-01-01": 2100,
+		"2024-01-02": 2200, // broker carried forward
+		"2024-01-03": 2200, // filled from the previous day
+		"2024-01-04": 2600,
+	}, values(timeline.Points))
+
+	assert.Equal(t, []string{"broker"}, timeline.Points[1].CarriedAccounts)
+	assert.True(t, timeline.Points[2].Filled)
+	assert.Equal(t, "2024-01-02", timeline.Points[2].AsOf)
+	assert.Equal(t, 100.0, timeline.Points[3].CashValue)
+	assert.Equal(t, 2500.0, timeline.Points[3].SecuritiesValue)
+	assert.Equal(t, 400.0, timeline.Points[3].Change)
+	assert.InDelta(t, 18.18, timeline.Points[3].ChangePercent, 0.01)
+	assert.Equal(t, 1, timeline.Filled)
+	assert.Equal(t, 2100.0, timeline.StartValue)
+	assert.Equal(t, 2600.0, timeline.EndValue)
+	assert.InDelta(t, 23.81, timeline.ChangePercent, 0.01)
+	assert.Equal(t, "2024-01-04", timeline.High.Date)
+	assert.Equal(t, "2024-01-01", timeline.Low.Date)
+	assert.Empty(t, timeline.Unconverted)
+
+	opts.Fill = FillLinear
+	timeline = Build(snapshots, time.Time{}, time.Time{}, opts)
+	assert.Equal(t, 2400.0, values(timeline.Points)["2024-01-03"])
+	assert.Empty(t, timeline.Points[2].AsOf)
+
+	opts.Fill = FillNone
+	timeline = Build(snapshots, time.Time{}, time.Time{}, opts)
+	assert.Len(t, timeline.Points, 3)
+	assert.Zero(t, timeline.Filled)
+
+	delete(rates, "USD")
+	timeline = Build(snapshots, time.Time{}, time.Time{}, opts)
+	assert.Equal(t, []string{"USD"}, timeline.Unconverted)
+	assert.Equal(t, 1100.0, timeline.StartValue)
+}
+
+func TestBuild_EmptiedAccount(t *testing.T) {
+	emptied := snapshot("2024-01-02", balance("personal", "BBCA", 1100, "IDR"))
+	emptied.Accounts = []string{"broker", "personal"}
+
+	snapshots := []portfolio.Snapshot{
+		snapshot("2024-01-01", balance("personal", "BBCA", 1000, "IDR"), balance("broker", "BBRI", 500, "IDR"), balance("removed", "GOTO", 50, "IDR")),
+		emptied,
+		snapshot("2024-01-03", balance("personal", "BBCA", 1200, "IDR")),
+	}
+
+	timeline := Build(snapshots, time.Time{}, time.Time{}, Options{Currency: "IDR", Accounts: []string{"broker", "personal"}})
+	assert.Equal(t, map[string]float64{
+		"2024-01-01": 1500, // removed account is no longer configured
+		"2024-01-02": 1100, // broker fetched without holdings
+		"2024-01-03": 1200, // broker is not carried from before it was emptied
+	}, values(timeline.Points))
+	assert.Empty(t, timeline.Points[2].CarriedAccounts)
+}
+
+func TestBuild_Range(t *testing.T) {
+	snapshots := []portfolio.Snapshot{
+		snapshot("2024-01-01", balance("personal", "BBCA", 1000, "IDR")),
+		snapshot("2024-01-05", balance("personal", "BBCA", 1500, "IDR")),
+	}
+
+	opts := Options{Currency: "IDR"}
+
+	// Values before from are carried into the first days, and the last value up to to
+	timeline := Build(snapshots, date("2024-01-03"), date("2024-01-06"), opts)
+	assert.Equal(t, map[string]float64{
+		"2024-01-03": 1000,
+		"2024-01-04": 1000,
+		"2024-01-05": 1500,
+		"2024-01-06": 1500,
+	}, values(timeline.Points))
+
+	opts.Accounts = []string{"business"}
+	timeline = Build(snapshots, time.Time{}, time.Time{}, opts)
+	assert.Empty(t, timeline.Points)
+	assert.Empty(t, timeline.From)
+
+	timeline = Build(nil, time.Time{}, time.Time{}, Options{Currency: "IDR"})
+	assert.NotNil(t, timeline.Points)
+	assert.Nil(t, timeline.High)
+}
+
+func TestBuild_Resolution(t *testing.T) {
+	snapshots := []portfolio.Snapshot{
+		snapshot("2024-01-03", balance("personal", "BBCA", 1000, "IDR")), // Wednesday
+		snapshot("2024-01-05", balance("personal", "BBCA", 1100, "IDR")),
+		snapshot("2024-01-09", balance("personal", "BBCA", 1200, "IDR")),
+		snapshot("2024-02-20", balance("personal", "BBCA", 1500, "IDR")),
+	}
+
+	timeline := Build(snapshots, time.Time{}, date("2024-02-29"), Options{Currency: "IDR", Resolution: ResolutionMonth})
+	require.Len(t, timeline.Points, 2)
+	assert.Equal(t, Point{Date: "2024-01-03", AsOf: "2024-01-09", Value: 1200, SecuritiesValue: 1200}, timeline.Points[0])
+	assert.Equal(t, "2024-02-01", timeline.Points[1].Date)
+	assert.Equal(t, 1500.0, timeline.Points[1].Value)
+
+	timeline = Build(snapshots, time.Time{}, time.Time{}, Options{Currency: "IDR", Resolution: ResolutionWeek, Fill: FillNone})
+	assert.Equal(t, map[string]float64{
+		"2024-01-03": 1100,
+		"2024-01-08": 1200,
+		"2024-02-19": 1500,
+	}, values(timeline.Points))
+
+	timeline = Build(snapshots, time.Time{}, time.Time{}, Options{Currency: "IDR", Resolution: ResolutionWeek})
+	assert.Len(t, timeline.Points, 8)
+	assert.Equal(t, 5, timeline.Filled)
+
+	assert.Equal(t, ResolutionDay, Build(snapshots, time.Time{}, time.Time{}, Options{Currency: "IDR"}).Resolution)
+	assert.Equal(t, ResolutionWeek, Build(snapshots, time.Time{}, date("2024-12-31"), Options{Currency: "IDR"}).Resolution)
+	assert.Equal(t, ResolutionMonth, Build(snapshots, time.Time{}, date("2026-12-31"), Options{Currency: "IDR", Resolution: ResolutionAuto}).Resolution)
+}
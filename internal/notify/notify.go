@@ -0,0 +1,86 @@
+// Package notify delivers text messages to chat services such as Slack and Telegram
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// requestTimeout bounds a request when no client is configured
+const requestTimeout = 30 * time.Second
+
+// Message is a notification with a short title and a plain text body of one or more lines
+type Message struct {
+	Title string
+	Text  string
+}
+
+// Notifier delivers messages
+type Notifier interface {
+	Notify(ctx context.Context, msg Message) error
+}
+
+// All delivers the message through every notifier, returning the errors of those that failed
+func All(ctx context.Context, notifiers []Notifier, msg Message) error {
+	var errs []error
+
+	for _, n := range notifiers {
+		if err := n.Notify(ctx, msg); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// PostJSON posts body as JSON to endpoint, failing on non-2xx responses. Errors mention only
+// the host of the endpoint, as webhook URLs often carry a secret token in their path.
+// A client with a 30 second timeout is used when client is nil.
+func PostJSON(ctx context.Context, client *http.Client, endpoint string, body any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(data))
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			return fmt.Errorf("invalid URL: %w", urlErr.Err)
+		}
+
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	if client == nil {
+		client = &http.Client{Timeout: requestTimeout}
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			return fmt.Errorf("%s: %w", req.URL.Host, urlErr.Err)
+		}
+
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(res.Body, 512))
+
+		return fmt.Errorf("%s responded with %s: %s", req.URL.Host, res.Status, bytes.TrimSpace(message))
+	}
+
+	return nil
+}
@@ -0,0 +1,78 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fake records the path and JSON body of requests, responding with status
+func fake(t *testing.T, status int) (*httptest.Server, *[]map[string]string, *[]string) {
+	var bodies []map[string]string
+
+	var paths []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+
+		bodies = append(bodies, body)
+		paths = append(paths, r.URL.Path)
+
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &bodies, &paths
+}
+
+func TestSlack_Notify(t *testing.T) {
+	server, bodies, _ := fake(t, http.StatusOK)
+
+	err := Slack{URL: server.URL}.Notify(context.Background(), Message{Title: "Digest <today>", Text: "BBCA & TLKM\n- up"})
+	require.NoError(t, err)
+	require.Len(t, *bodies, 1)
+	assert.Equal(t, map[string]string{"text": "*Digest &lt;today&gt;*\nBBCA &amp; TLKM\n- up"}, (*bodies)[0])
+}
+
+func TestTelegram_Notify(t *testing.T) {
+	server, bodies, paths := fake(t, http.StatusOK)
+
+	err := Telegram{URL: server.URL + "/", Token: "123:abc", ChatID: "-42"}.Notify(context.Background(), Message{Title: "Digest", Text: "BBCA <up>"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/bot123:abc/sendMessage"}, *paths)
+	assert.Equal(t, map[string]string{"chat_id": "-42", "text": "<b>Digest</b>\nBBCA &lt;up&gt;", "parse_mode": "HTML"}, (*bodies)[0])
+}
+
+func TestAll(t *testing.T) {
+	ok, okBodies, _ := fake(t, http.StatusNoContent)
+	failing, _, _ := fake(t, http.StatusForbidden)
+
+	err := All(context.Background(), []Notifier{
+		Slack{URL: failing.URL},
+		Slack{URL: ok.URL},
+		Telegram{URL: failing.URL, Token: "secret", ChatID: "1"},
+	}, Message{Text: "hello"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "403")
+	assert.NotContains(t, err.Error(), "secret", "the bot token is part of the URL and must not be logged")
+	assert.Len(t, *okBodies, 1, "failures do not stop other notifiers")
+
+	assert.NoError(t, All(context.Background(), nil, Message{}))
+}
+
+func TestPostJSON_InvalidURL(t *testing.T) {
+	err := Slack{URL: "https://hooks.slack.com/services/T000/B000/secret\n"}.Notify(context.Background(), Message{Title: "Digest"})
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), "secret")
+
+	err = Telegram{Token: "123:secret token\n", ChatID: "1"}.Notify(context.Background(), Message{Title: "Digest"})
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), "secret")
+}
@@ -0,0 +1,34 @@
+package notify
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+// slackPayload is the body of a Slack incoming webhook request
+type slackPayload struct {
+	Text string `json:"text"`
+}
+
+// Slack posts messages to a Slack incoming webhook, or any endpoint accepting its payload
+type Slack struct {
+	URL    string
+	Client *http.Client // a client with a 30 second timeout when nil
+}
+
+// Notify posts the message with its title in bold
+func (s Slack) Notify(ctx context.Context, msg Message) error {
+	return PostJSON(ctx, s.Client, s.URL, slackPayload{Text: slackText(msg)})
+}
+
+// slackText formats the message as Slack mrkdwn, escaping the characters Slack treats as control sequences
+func slackText(msg Message) string {
+	escape := strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace
+
+	if msg.Title == "" {
+		return escape(msg.Text)
+	}
+
+	return "*" + escape(msg.Title) + "*\n" + escape(msg.Text)
+}
@@ -0,0 +1,47 @@
+package notify
+
+import (
+	"cmp"
+	"context"
+	"html"
+	"net/http"
+	"strings"
+)
+
+// DefaultTelegramURL is the Telegram Bot API endpoint
+const DefaultTelegramURL = "https://api.telegram.org"
+
+// telegramPayload is the body of a Telegram Bot API sendMessage request
+type telegramPayload struct {
+	ChatID    string `json:"chat_id"`
+	Text      string `json:"text"`
+	ParseMode string `json:"parse_mode"`
+}
+
+// Telegram sends messages to a chat through the Telegram Bot API, or any endpoint accepting its requests
+type Telegram struct {
+	URL    string // DefaultTelegramURL when empty
+	Token  string
+	ChatID string
+	Client *http.Client // a client with a 30 second timeout when nil
+}
+
+// Notify sends the message with its title in bold
+func (t Telegram) Notify(ctx context.Context, msg Message) error {
+	url := strings.TrimSuffix(cmp.Or(t.URL, DefaultTelegramURL), "/") + "/bot" + t.Token + "/sendMessage"
+
+	return PostJSON(ctx, t.Client, url, telegramPayload{
+		ChatID:    t.ChatID,
+		Text:      telegramText(msg),
+		ParseMode: "HTML",
+	})
+}
+
+// telegramText formats the message as Telegram HTML
+func telegramText(msg Message) string {
+	if msg.Title == "" {
+		return html.EscapeString(msg.Text)
+	}
+
+	return "<b>" + html.EscapeString(msg.Title) + "</b>\n" + html.EscapeString(msg.Text)
+}
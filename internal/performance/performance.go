@@ -0,0 +1,251 @@
+// Package performance computes time-weighted and money-weighted returns from portfolio snapshots
+package performance
+
+import (
+	"math"
+	"slices"
+	"time"
+
+	"github.com/chickenzord/portosync/internal/portfolio"
+	"github.com/chickenzord/portosync/internal/transaction"
+)
+
+const daysPerYear = 365.0
+
+// Scope selects the holdings measured together, such as one account or one asset type
+type Scope struct {
+	Name string
+
+	// Accounts measured together. Snapshots are used once every account appeared,
+	// accounts missing afterwards keep their balances of the previous snapshot.
+	Accounts []string
+
+	// Match selects balances and, through their account and symbol, transactions
+	Match func(b portfolio.Balance) bool
+}
+
+// Result is the performance of a scope over the snapshots found in a date range.
+// Returns are percentages; cash balances are excluded and buys and sells are treated as cash flows.
+type Result struct {
+	Scope         string   `json:"scope"                    jsonschema:"description:Measured holdings: overall, account:NAME or asset_type:TYPE"`
+	From          string   `json:"from,omitempty"           jsonschema:"description:Date of the first snapshot used (YYYY-MM-DD)"`
+	To            string   `json:"to,omitempty"             jsonschema:"description:Date of the last snapshot used (YYYY-MM-DD)"`
+	StartValue    float64  `json:"start_value"              jsonschema:"description:Value of the holdings at the first snapshot"`
+	EndValue      float64  `json:"end_value"                jsonschema:"description:Value of the holdings at the last snapshot"`
+	NetFlows      float64  `json:"net_flows"                jsonschema:"description:Buys minus sells between the first and last snapshot"`
+	Gain          float64  `json:"gain"                     jsonschema:"description:End value minus start value minus net flows"`
+	TWR           float64  `json:"twr"                      jsonschema:"description:Time-weighted return in percent, chaining returns between consecutive snapshots so buys and sells do not distort it"`
+	TWRAnnualized *float64 `json:"twr_annualized,omitempty" jsonschema:"description:Time-weighted return annualized over the period in percent, omitted for periods shorter than 30 days"`
+	XIRR          *float64 `json:"xirr,omitempty"           jsonschema:"description:Money-weighted annual return in percent (XIRR) including the timing of buys and sells, omitted when it cannot be solved"`
+	Periods       int      `json:"periods"                  jsonschema:"description:Number of chained periods between consecutive snapshots"`
+	MissingDays   int      `json:"missing_days"             jsonschema:"description:Days between the first and last snapshot without a usable snapshot, bridged by chaining the surrounding snapshots"`
+	CarriedDays   int      `json:"carried_days"             jsonschema:"description:Snapshots missing an account, for example after a failed fetch, where the account kept its balances of the previous snapshot"`
+}
+
+// minAnnualizedDays avoids extrapolating returns of very short periods to a year
+const minAnnualizedDays = 30
+
+// Compute measures the scope over the snapshots, which must be ordered by date and share one currency.
+// Snapshots before every account of the scope appeared are skipped; accounts missing from later
+// snapshots keep their previous balances, so a failed fetch of one account does not drop the day.
+// Transactions are treated as cash flows at the end of the period they fall in.
+func Compute(snapshots []portfolio.Snapshot, transactions []transaction.Transaction, scope Scope, currency string) Result {
+	result := Result{Scope: scope.Name}
+
+	c := chainSnapshots(snapshots, transactions, scope, currency)
+	if len(c.snapshots) == 0 {
+		return result
+	}
+
+	first, last := c.snapshots[0], c.snapshots[len(c.snapshots)-1]
+	result.From = first.DateString()
+	result.To = last.DateString()
+	result.Periods = len(c.snapshots) - 1
+	result.MissingDays = days(first.Date, last.Date) + 1 - len(c.snapshots)
+	result.CarriedDays = c.carried
+	result.StartValue = c.values[0]
+	result.EndValue = c.values[len(c.values)-1]
+
+	for _, f := range c.flows {
+		result.NetFlows += f
+	}
+
+	growth := c.growth[len(c.growth)-1]
+
+	result.Gain = result.EndValue - result.StartValue - result.NetFlows
+	result.TWR = (growth - 1) * 100
+
+	if d := days(first.Date, last.Date); d >= minAnnualizedDays && growth > 0 {
+		annualized := (math.Pow(growth, daysPerYear/float64(d)) - 1) * 100
+		result.TWRAnnualized = &annualized
+	}
+
+	cashFlows := append([]CashFlow{{Date: first.Date, Amount: -result.StartValue}}, c.cashFlows...)
+	cashFlows = append(cashFlows, CashFlow{Date: last.Date, Amount: result.EndValue})
+
+	if rate, ok := XIRR(cashFlows); ok {
+		rate *= 100
+		result.XIRR = &rate
+	}
+
+	return result
+}
+
+// Point is the time-weighted growth of a scope at a snapshot date, starting at 100
+type Point struct {
+	Date  time.Time
+	Value float64
+}
+
+// Index returns the time-weighted growth of the scope at every usable snapshot,
+// suitable for lining up against benchmark series
+func Index(snapshots []portfolio.Snapshot, transactions []transaction.Transaction, scope Scope, currency string) []Point {
+	c := chainSnapshots(snapshots, transactions, scope, currency)
+
+	points := make([]Point, len(c.snapshots))
+	for i, s := range c.snapshots {
+		points[i] = Point{Date: s.Date, Value: c.growth[i] * 100}
+	}
+
+	return points
+}
+
+// chain holds per-snapshot values of a scope and the flows of the periods ending at each snapshot
+type chain struct {
+	snapshots []portfolio.Snapshot
+	values    []float64
+	flows     []float64 // net buys of the period ending at the snapshot, zero for the first
+	growth    []float64 // cumulative time-weighted growth factor, one for the first
+	cashFlows []CashFlow
+	carried   int // snapshots where missing accounts kept their previous balances
+}
+
+func chainSnapshots(snapshots []portfolio.Snapshot, transactions []transaction.Transaction, scope Scope, currency string) chain {
+	c := chain{}
+
+	// Latest balances of each account of the scope
+	latest := make(map[string][]portfolio.Balance, len(scope.Accounts))
+
+	for _, s := range snapshots {
+		var missing []string
+
+		fetched := s.FetchedAccounts()
+
+		for _, account := range scope.Accounts {
+			// Accounts fetched without holdings are empty rather than missing
+			if !slices.Contains(fetched, account) {
+				missing = append(missing, account)
+
+				continue
+			}
+
+			latest[account] = accountBalances(s, account)
+		}
+
+		if len(latest) < len(scope.Accounts) {
+			continue
+		}
+
+		if len(missing) > 0 {
+			s.Balances = slices.Clone(s.Balances)
+			for _, account := range missing {
+				s.Balances = append(s.Balances, latest[account]...)
+			}
+
+			c.carried++
+		}
+
+		c.snapshots = append(c.snapshots, s)
+	}
+
+	if len(c.snapshots) == 0 {
+		return c
+	}
+
+	assetTypes := assetTypesOf(c.snapshots)
+	matchTransaction := func(t transaction.Transaction) bool {
+		b := portfolio.Balance{SourceAccount: t.Account, AssetSymbol: t.Symbol, UnitsCurrency: currency}
+		b.AssetType = assetTypes[[2]string{t.Account, t.Symbol}]
+
+		return scope.Match(b)
+	}
+
+	c.values = make([]float64, len(c.snapshots))
+	c.flows = make([]float64, len(c.snapshots))
+	c.growth = make([]float64, len(c.snapshots))
+
+	for i, s := range c.snapshots {
+		c.values[i] = value(s, scope, currency)
+		c.growth[i] = 1
+
+		if i == 0 {
+			continue
+		}
+
+		for _, t := range transactions {
+			if !t.Date.After(c.snapshots[i-1].Date) || t.Date.After(s.Date) || !matchTransaction(t) {
+				continue
+			}
+
+			amount := t.Value()
+			if !t.IsIncrease() {
+				amount = -amount
+			}
+
+			c.flows[i] += amount
+			c.cashFlows = append(c.cashFlows, CashFlow{Date: t.Date, Amount: -amount})
+		}
+
+		c.growth[i] = c.growth[i-1]
+
+		// Periods starting without holdings have no return to chain
+		if c.values[i-1] > 0 {
+			c.growth[i] *= (c.values[i] - c.flows[i]) / c.values[i-1]
+		}
+	}
+
+	return c
+}
+
+// accountBalances returns balances of the account in the snapshot
+func accountBalances(s portfolio.Snapshot, account string) []portfolio.Balance {
+	var balances []portfolio.Balance
+
+	for _, b := range s.Balances {
+		if b.SourceAccount == account {
+			balances = append(balances, b)
+		}
+	}
+
+	return balances
+}
+
+func value(s portfolio.Snapshot, scope Scope, currency string) float64 {
+	var total float64
+
+	for _, b := range s.Balances {
+		if !b.IsCash() && b.UnitsCurrency == currency && scope.Match(b) {
+			total += b.UnitsValue
+		}
+	}
+
+	return total
+}
+
+// assetTypesOf maps account and symbol to the asset type observed in snapshots
+func assetTypesOf(snapshots []portfolio.Snapshot) map[[2]string]string {
+	types := make(map[[2]string]string)
+
+	for _, s := range snapshots {
+		for _, b := range s.Balances {
+			types[[2]string{b.SourceAccount, b.AssetSymbol}] = b.AssetType
+		}
+	}
+
+	return types
+}
+
+func days(from, to time.Time) int {
+	// Round to absorb daylight saving time shifts
+	return int(math.Round(to.Sub(from).Hours() / 24))
+}
@@ -0,0 +1,109 @@
+package performance
+
+import (
+	"testing"
+	"time"
+
+	"github.com/chickenzord/portosync/internal/portfolio"
+	"github.com/chickenzord/portosync/internal/transaction"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func date(s string) time.Time {
+	d, err := portfolio.ParseDate(s)
+	if err != nil {
+		panic(err)
+	}
+
+	return d
+}
+
+func overall(portfolio.Balance) bool { return true }
+
+func TestCompute(t *testing.T) {
+	snapshots := []portfolio.Snapshot{
+		{Date: date("2024-01-01"), Balances: []portfolio.Balance{
+			{SourceAccount: "personal", AssetSymbol: "BBCA", AssetType: "equity", UnitsAmount: 100, UnitsValue: 1000, UnitsCurrency: "IDR"},
+			{SourceAccount: "personal", AssetSymbol: "IDR", AssetType: portfolio.AssetTypeCash, UnitsValue: 5000, UnitsCurrency: "IDR"},
+		}},
+		// Price up 10%
+		{Date: date("2024-01-02"), Balances: []portfolio.Balance{
+			{SourceAccount: "personal", AssetSymbol: "BBCA", AssetType: "equity", UnitsAmount: 100, UnitsValue: 1100, UnitsCurrency: "IDR"},
+		}},
+		// Account missing, keeps its balances of the previous snapshot
+		{Date: date("2024-01-03"), Balances: []portfolio.Balance{
+			{SourceAccount: "business", AssetSymbol: "TLKM", AssetType: "equity", UnitsAmount: 100, UnitsValue: 400, UnitsCurrency: "IDR"},
+		}},
+		// Price down 10%, bought 100 more at the new price
+		{Date: date("2024-01-05"), Balances: []portfolio.Balance{
+			{SourceAccount: "personal", AssetSymbol: "BBCA", AssetType: "equity", UnitsAmount: 200, UnitsValue: 1980, UnitsCurrency: "IDR"},
+		}},
+	}
+
+	transactions := []transaction.Transaction{
+		{Date: date("2024-01-04"), Account: "personal", Symbol: "BBCA", Type: transaction.TypeBuy, Units: 100, Price: 9.9},
+		{Date: date("2024-01-04"), Account: "business", Symbol: "TLKM", Type: transaction.TypeBuy, Units: 100, Price: 4},
+	}
+
+	result := Compute(snapshots, transactions, Scope{Name: "account:personal", Accounts: []string{"personal"}, Match: func(b portfolio.Balance) bool {
+		return b.SourceAccount == "personal"
+	}}, "IDR")
+
+	assert.Equal(t, "2024-01-01", result.From)
+	assert.Equal(t, "2024-01-05", result.To)
+	assert.Equal(t, 1000.0, result.StartValue)
+	assert.Equal(t, 1980.0, result.EndValue)
+	assert.InDelta(t, 990.0, result.NetFlows, 1e-9)
+	assert.InDelta(t, -10.0, result.Gain, 1e-9)
+	assert.InDelta(t, -1.0, result.TWR, 1e-9, "1.1 * 0.9 - 1")
+	assert.Equal(t, 3, result.Periods)
+	assert.Equal(t, 1, result.MissingDays)
+	assert.Equal(t, 1, result.CarriedDays)
+	assert.Nil(t, result.TWRAnnualized, "period too short")
+	require.NotNil(t, result.XIRR)
+	assert.Less(t, *result.XIRR, 0.0)
+
+	// An account fetched without holdings is not carried
+	snapshots[2].Accounts = []string{"business", "personal"}
+	result = Compute(snapshots, transactions, Scope{Name: "account:personal", Accounts: []string{"personal"}, Match: func(b portfolio.Balance) bool {
+		return b.SourceAccount == "personal"
+	}}, "IDR")
+	assert.Zero(t, result.CarriedDays)
+	assert.Equal(t, 3, result.Periods)
+
+	empty := Compute(snapshots, transactions, Scope{Name: "account:other", Accounts: []string{"other"}, Match: overall}, "IDR")
+	assert.Equal(t, Result{Scope: "account:other"}, empty)
+}
+
+func TestCompute_Annualized(t *testing.T) {
+	snapshots := []portfolio.Snapshot{
+		{Date: date("2023-01-01"), Balances: []portfolio.Balance{{SourceAccount: "a", AssetSymbol: "X", UnitsValue: 1000, UnitsCurrency: "IDR"}}},
+		{Date: date("2024-01-01"), Balances: []portfolio.Balance{{SourceAccount: "a", AssetSymbol: "X", UnitsValue: 1060, UnitsCurrency: "IDR"}}},
+		{Date: date("2024-01-01"), Balances: []portfolio.Balance{{SourceAccount: "a", AssetSymbol: "Y", UnitsValue: 1, UnitsCurrency: "USD"}}},
+	}
+
+	result := Compute(snapshots[:2], nil, Scope{Name: "overall", Match: overall}, "IDR")
+	assert.InDelta(t, 6.0, result.TWR, 1e-9)
+	require.NotNil(t, result.TWRAnnualized)
+	assert.InDelta(t, 6.0, *result.TWRAnnualized, 1e-9)
+	require.NotNil(t, result.XIRR)
+	assert.InDelta(t, 6.0, *result.XIRR, 1e-6)
+	assert.Equal(t, 364, result.MissingDays)
+}
+
+func TestXIRR(t *testing.T) {
+	rate, ok := XIRR([]CashFlow{
+		{Date: date("2024-01-01"), Amount: -1000},
+		{Date: date("2024-07-01"), Amount: -1000},
+		{Date: date("2024-12-31"), Amount: 2150},
+	})
+	require.True(t, ok)
+	assert.InDelta(t, 0.0999, rate, 0.001)
+
+	_, ok = XIRR([]CashFlow{{Date: date("2024-01-01"), Amount: -1000}, {Date: date("2024-12-31"), Amount: -10}})
+	assert.False(t, ok)
+
+	_, ok = XIRR(nil)
+	assert.False(t, ok)
+}
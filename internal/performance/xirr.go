@@ -0,0 +1,78 @@
+package performance
+
+import (
+	"math"
+	"time"
+)
+
+// CashFlow is an amount paid (negative) or received (positive) by the investor on a date
+type CashFlow struct {
+	Date   time.Time
+	Amount float64
+}
+
+const (
+	xirrTolerance     = 1e-9
+	xirrMaxIterations = 100
+)
+
+// XIRR returns the annual rate at which the net present value of the cash flows is zero.
+// It reports false when the flows do not have both signs or no rate is found.
+func XIRR(flows []CashFlow) (float64, bool) {
+	if len(flows) < 2 {
+		return 0, false
+	}
+
+	var hasPositive, hasNegative bool
+
+	for _, f := range flows {
+		hasPositive = hasPositive || f.Amount > 0
+		hasNegative = hasNegative || f.Amount < 0
+	}
+
+	if !hasPositive || !hasNegative {
+		return 0, false
+	}
+
+	start := flows[0].Date
+	for _, f := range flows {
+		if f.Date.Before(start) {
+			start = f.Date
+		}
+	}
+
+	npv := func(rate float64) float64 {
+		var sum float64
+		for _, f := range flows {
+			sum += f.Amount / math.Pow(1+rate, f.Date.Sub(start).Hours()/24/daysPerYear)
+		}
+
+		return sum
+	}
+
+	// Bracket the root, NPV decreases with the rate for investments paid before they are received
+	low, high := -0.9999, 1.0
+	for npv(low)*npv(high) > 0 {
+		high *= 2
+		if high > 1e6 {
+			return 0, false
+		}
+	}
+
+	for range xirrMaxIterations {
+		mid := (low + high) / 2
+
+		v := npv(mid)
+		if math.Abs(v) < xirrTolerance || high-low < xirrTolerance {
+			return mid, true
+		}
+
+		if npv(low)*v < 0 {
+			high = mid
+		} else {
+			low = mid
+		}
+	}
+
+	return (low + high) / 2, true
+}
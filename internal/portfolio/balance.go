@@ -0,0 +1,260 @@
+package portfolio
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// AssetTypeCash is the asset type of cash balances, whose symbol is the currency code
+const AssetTypeCash = "cash"
+
+// AssetTypeBond is the asset type of bonds, whose units are the face amount held
+const AssetTypeBond = "bond"
+
+// Unclassified is the sector and issuer group of holdings missing from the classification
+const Unclassified = "unclassified"
+
+type Balance struct {
+	SourceType    string  `json:"source_type"           jsonschema:"description:Type of data source providing this balance (e.g., KSEI for Indonesian securities depository)"`
+	SourceAccount string  `json:"source_account"        jsonschema:"description:The account name from which this balance was retrieved, matching one of the configured account names"`
+	AssetSymbol   string  `json:"asset_symbol"          jsonschema:"description:Trading symbol or ticker of the asset (e.g., BBCA for Bank Central Asia stock)"`
+	AssetName     string  `json:"asset_name"            jsonschema:"description:Full descriptive name of the asset"`
+	AssetType     string  `json:"asset_type"            jsonschema:"description:Primary classification of the asset (e.g., Stock, Bond, Mutual Fund)"`
+	AssetSubType  string  `json:"asset_sub_type"        jsonschema:"description:Additional classification or subtype of the asset, providing more granular categorization"`
+	UnitsAmount   float64 `json:"units_amount"          jsonschema:"description:Quantity of asset units held in the account"`
+	UnitsValue    float64 `json:"units_value"           jsonschema:"description:Total monetary value of the asset holdings in the specified currency"`
+	UnitsCurrency string  `json:"units_currency"        jsonschema:"description:Currency code for the asset value (e.g., IDR for Indonesian Rupiah, USD for US Dollar)"`
+	Participant   string  `json:"participant,omitempty" jsonschema:"description:Broker, asset manager or bank administering the holding, when provided by the source"`
+	SubAccount    string  `json:"sub_account,omitempty" jsonschema:"description:Securities sub-account or RDN account number holding the asset, when provided by the source"`
+
+	Sector      string `json:"sector,omitempty"       jsonschema:"description:Business sector of the issuer (e.g., Financials), when the symbol is classified"`
+	SubIndustry string `json:"sub_industry,omitempty" jsonschema:"description:Sub-industry of the issuer within its sector (e.g., Banks), when the symbol is classified"`
+	IssuerGroup string `json:"issuer_group,omitempty" jsonschema:"description:Business group or owner of the issuer (e.g., BUMN for state-owned enterprises), when the symbol is classified"`
+
+	Tags []string `json:"tags,omitempty" jsonschema:"description:User-defined tags of the holding and its account (e.g., retirement or joint), when configured"`
+
+	CostBasis             float64  `json:"cost_basis,omitempty"              jsonschema:"description:Purchase cost of the units held, when the average purchase price is known"`
+	UnrealizedGain        *float64 `json:"unrealized_gain,omitempty"         jsonschema:"description:Value minus cost basis, present when cost basis is known"`
+	UnrealizedGainPercent float64  `json:"unrealized_gain_percent,omitempty" jsonschema:"description:Unrealized gain as a percentage of cost basis"`
+
+	CouponRate      float64 `json:"coupon_rate,omitempty"       jsonschema:"description:Annual coupon rate in percent of face value, for bonds in the bond catalogue"`
+	MaturityDate    string  `json:"maturity_date,omitempty"     jsonschema:"description:Maturity date (YYYY-MM-DD), for bonds in the bond catalogue"`
+	YearsToMaturity float64 `json:"years_to_maturity,omitempty" jsonschema:"description:Years left until the maturity date, for bonds in the bond catalogue"`
+	CurrentYield    float64 `json:"current_yield,omitempty"     jsonschema:"description:Annual coupon income in percent of the current value, for bonds in the bond catalogue"`
+}
+
+func (b Balance) AssetTypeFull() string {
+	fragments := []string{}
+
+	if b.AssetType != "" {
+		fragments = append(fragments, b.AssetType)
+	}
+
+	if b.AssetSubType != "" {
+		fragments = append(fragments, b.AssetSubType)
+	}
+
+	return strings.Join(fragments, "/")
+}
+
+// IsCash reports whether the balance is a cash balance rather than a security holding
+func (b Balance) IsCash() bool {
+	return b.AssetType == AssetTypeCash
+}
+
+// SetCostBasis records the purchase cost of the units held and derives the unrealized gain
+func (b *Balance) SetCostBasis(cost float64) {
+	gain := b.UnitsValue - cost
+
+	b.CostBasis = cost
+	b.UnrealizedGain = &gain
+	b.UnrealizedGainPercent = 0
+
+	if cost != 0 {
+		b.UnrealizedGainPercent = gain * 100 / cost
+	}
+}
+
+// HasCostBasis reports whether the purchase cost of the balance is known
+func (b Balance) HasCostBasis() bool {
+	return b.UnrealizedGain != nil
+}
+
+func (b Balance) Description() string {
+	if b.IsCash() {
+		return fmt.Sprintf("%s: cash, total value %s %f (%s)",
+			b.AssetName,
+			b.UnitsCurrency,
+			b.UnitsValue,
+			b.SourceAccount,
+		)
+	}
+
+	description := fmt.Sprintf("%s %s: %f units of %s, total value %s %f (%s)",
+		b.AssetSymbol,
+		b.AssetName,
+		b.UnitsAmount,
+		b.AssetTypeFull(),
+		b.UnitsCurrency,
+		b.UnitsValue,
+		b.SourceAccount,
+	)
+
+	if b.HasCostBasis() {
+		description += fmt.Sprintf(", cost basis %f, unrealized gain %f (%+.2f%%)",
+			b.CostBasis, *b.UnrealizedGain, b.UnrealizedGainPercent)
+	}
+
+	if b.Sector != "" {
+		description += fmt.Sprintf(", sector %s (%s), issuer group %s", b.Sector, b.SubIndustry, b.IssuerGroup)
+	}
+
+	if len(b.Tags) > 0 {
+		description += ", tags " + strings.Join(b.Tags, ", ")
+	}
+
+	if b.MaturityDate != "" {
+		description += fmt.Sprintf(", coupon %.3f%%, matures %s (%.2f years), current yield %.2f%%",
+			b.CouponRate, b.MaturityDate, b.YearsToMaturity, b.CurrentYield)
+	}
+
+	return description
+}
+
+// CurrencyTotal sums balance values sharing the same currency
+type CurrencyTotal struct {
+	Currency        string  `json:"currency"         jsonschema:"description:Currency code of the totals"`
+	SecuritiesValue float64 `json:"securities_value" jsonschema:"description:Total value of non-cash holdings in this currency"`
+	CashValue       float64 `json:"cash_value"       jsonschema:"description:Total value of cash balances in this currency"`
+	TotalValue      float64 `json:"total_value"      jsonschema:"description:Sum of securities and cash values in this currency"`
+}
+
+// Totals sums balance values per currency, sorted by currency code
+func Totals(balances []Balance) []CurrencyTotal {
+	byCurrency := make(map[string]*CurrencyTotal)
+
+	var totals []CurrencyTotal
+
+	for _, b := range balances {
+		t, ok := byCurrency[b.UnitsCurrency]
+		if !ok {
+			t = &CurrencyTotal{Currency: b.UnitsCurrency}
+			byCurrency[b.UnitsCurrency] = t
+		}
+
+		if b.IsCash() {
+			t.CashValue += b.UnitsValue
+		} else {
+			t.SecuritiesValue += b.UnitsValue
+		}
+
+		t.TotalValue += b.UnitsValue
+	}
+
+	for _, t := range byCurrency {
+		totals = append(totals, *t)
+	}
+
+	slices.SortFunc(totals, func(a, b CurrencyTotal) int { return strings.Compare(a.Currency, b.Currency) })
+
+	return totals
+}
+
+// AccountGain sums unrealized gains of an account's balances with known cost basis in one currency
+type AccountGain struct {
+	Account               string  `json:"account"                 jsonschema:"description:Account name"`
+	Currency              string  `json:"currency"                jsonschema:"description:Currency of the values"`
+	CostBasis             float64 `json:"cost_basis"              jsonschema:"description:Total purchase cost of holdings with known cost basis"`
+	Value                 float64 `json:"value"                   jsonschema:"description:Current value of the same holdings"`
+	UnrealizedGain        float64 `json:"unrealized_gain"         jsonschema:"description:Value minus cost basis"`
+	UnrealizedGainPercent float64 `json:"unrealized_gain_percent" jsonschema:"description:Unrealized gain as a percentage of cost basis"`
+	Holdings              int     `json:"holdings"                jsonschema:"description:Number of holdings with known cost basis"`
+}
+
+// Gains sums unrealized gains per account and currency of balances with known cost basis,
+// sorted by account and currency. Returns nil when no balance has a cost basis.
+func Gains(balances []Balance) []AccountGain {
+	var gains []AccountGain
+
+	index := make(map[[2]string]int)
+
+	for _, b := range balances {
+		if !b.HasCostBasis() {
+			continue
+		}
+
+		k := [2]string{b.SourceAccount, b.UnitsCurrency}
+
+		i, ok := index[k]
+		if !ok {
+			i = len(gains)
+			index[k] = i
+
+			gains = append(gains, AccountGain{Account: b.SourceAccount, Currency: b.UnitsCurrency})
+		}
+
+		g := &gains[i]
+		g.CostBasis += b.CostBasis
+		g.Value += b.UnitsValue
+		g.UnrealizedGain += *b.UnrealizedGain
+		g.Holdings++
+
+		if g.CostBasis != 0 {
+			g.UnrealizedGainPercent = g.UnrealizedGain * 100 / g.CostBasis
+		}
+	}
+
+	slices.SortFunc(gains, func(a, b AccountGain) int {
+		return cmp.Or(cmp.Compare(a.Account, b.Account), cmp.Compare(a.Currency, b.Currency))
+	})
+
+	return gains
+}
+
+// Snapshot is a set of balances observed on a single day
+type Snapshot struct {
+	Date     time.Time `json:"date"`
+	Balances []Balance `json:"balances"`
+
+	// Accounts lists the accounts fetched into the snapshot, including those without any holdings,
+	// so an emptied account can be told apart from one that was not fetched
+	Accounts []string `json:"accounts,omitempty"`
+}
+
+// FetchedAccounts returns the sorted accounts fetched into the snapshot. Accounts of its balances are
+// included, as snapshots recorded before fetched accounts were tracked only list them through balances.
+func (s Snapshot) FetchedAccounts() []string {
+	accounts := slices.Clone(s.Accounts)
+	for _, b := range s.Balances {
+		accounts = append(accounts, b.SourceAccount)
+	}
+
+	slices.Sort(accounts)
+
+	return slices.Compact(accounts)
+}
+
+// DateString returns the snapshot date formatted as YYYY-MM-DD
+func (s Snapshot) DateString() string {
+	return s.Date.Format(time.DateOnly)
+}
+
+// Today returns the current date truncated to midnight in local time
+func Today() time.Time {
+	return DateOf(time.Now())
+}
+
+// DateOf truncates t to midnight in its own location
+func DateOf(t time.Time) time.Time {
+	y, m, d := t.Date()
+
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+// ParseDate parses a YYYY-MM-DD date in local time
+func ParseDate(s string) (time.Time, error) {
+	return time.ParseInLocation(time.DateOnly, s, time.Local)
+}
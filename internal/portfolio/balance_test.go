@@ -0,0 +1,71 @@
+package portfolio
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBalance_Description(t *testing.T) {
+	stock := Balance{SourceAccount: "personal", AssetSymbol: "BBCA", AssetName: "Bank Central Asia", AssetType: "equity", UnitsAmount: 100, UnitsValue: 950000, UnitsCurrency: "IDR"}
+	assert.Equal(t, "BBCA Bank Central Asia: 100.000000 units of equity, total value IDR 950000.000000 (personal)", stock.Description())
+
+	cash := Balance{SourceAccount: "personal", AssetSymbol: "IDR", AssetName: "RDN BCA 123", AssetType: AssetTypeCash, UnitsAmount: 5000, UnitsValue: 5000, UnitsCurrency: "IDR"}
+	assert.Equal(t, "RDN BCA 123: cash, total value IDR 5000.000000 (personal)", cash.Description())
+}
+
+func TestBalance_SetCostBasis(t *testing.T) {
+	stock := Balance{SourceAccount: "personal", AssetSymbol: "BBCA", AssetName: "Bank Central Asia", AssetType: "equity", UnitsAmount: 100, UnitsValue: 950000, UnitsCurrency: "IDR"}
+	assert.False(t, stock.HasCostBasis())
+
+	stock.SetCostBasis(1000000)
+	assert.True(t, stock.HasCostBasis())
+	require.NotNil(t, stock.UnrealizedGain)
+	assert.Equal(t, -50000.0, *stock.UnrealizedGain)
+	assert.Equal(t, -5.0, stock.UnrealizedGainPercent)
+	assert.Equal(t, "BBCA Bank Central Asia: 100.000000 units of equity, total value IDR 950000.000000 (personal), cost basis 1000000.000000, unrealized gain -50000.000000 (-5.00%)", stock.Description())
+
+	// A gain of exactly zero is still reported
+	stock.SetCostBasis(950000)
+
+	data, err := json.Marshal(stock)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"unrealized_gain":0`)
+}
+
+func TestGains(t *testing.T) {
+	balances := []Balance{
+		{SourceAccount: "personal", AssetSymbol: "BBCA", UnitsValue: 1200, UnitsCurrency: "IDR"},
+		{SourceAccount: "personal", AssetSymbol: "TLKM", UnitsValue: 300, UnitsCurrency: "IDR"},
+		{SourceAccount: "personal", AssetSymbol: "GOTO", UnitsValue: 50, UnitsCurrency: "IDR"},
+		{SourceAccount: "broker", AssetSymbol: "AAPL", UnitsValue: 220, UnitsCurrency: "USD"},
+	}
+	balances[0].SetCostBasis(1000)
+	balances[1].SetCostBasis(400)
+	balances[3].SetCostBasis(200)
+
+	assert.Equal(t, []AccountGain{
+		{Account: "broker", Currency: "USD", CostBasis: 200, Value: 220, UnrealizedGain: 20, UnrealizedGainPercent: 10, Holdings: 1},
+		{Account: "personal", Currency: "IDR", CostBasis: 1400, Value: 1500, UnrealizedGain: 100, UnrealizedGainPercent: 100 * 100 / 1400.0, Holdings: 2},
+	}, Gains(balances))
+
+	assert.Nil(t, Gains(balances[2:3]))
+}
+
+func TestTotals(t *testing.T) {
+	balances := []Balance{
+		{AssetType: "equity", UnitsValue: 1000, UnitsCurrency: "IDR"},
+		{AssetType: AssetTypeCash, UnitsValue: 500, UnitsCurrency: "IDR"},
+		{AssetType: "equity", UnitsValue: 10, UnitsCurrency: "USD"},
+		{AssetType: "mutual_fund", UnitsValue: 2000, UnitsCurrency: "IDR"},
+	}
+
+	assert.Equal(t, []CurrencyTotal{
+		{Currency: "IDR", SecuritiesValue: 3000, CashValue: 500, TotalValue: 3500},
+		{Currency: "USD", SecuritiesValue: 10, TotalValue: 10},
+	}, Totals(balances))
+
+	assert.Nil(t, Totals(nil))
+}
@@ -0,0 +1,2 @@
+// Package portfolio contains portfolio data types shared across Portosync components
+package portfolio
@@ -0,0 +1,231 @@
+package portfolio
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Dimensions to group balances by
+const (
+	GroupByAsset     = "asset"
+	GroupByAccount   = "account"
+	GroupByAssetType = "asset_type"
+	GroupByCurrency  = "currency"
+	GroupBySector    = "sector"
+	GroupByIssuer    = "issuer_group"
+)
+
+// groupKeys returns the group key of a balance per dimension. Values in different currencies
+// are never summed, so every group is also split by currency.
+var groupKeys = map[string]func(b Balance) string{
+	GroupByAsset:     func(b Balance) string { return b.AssetSymbol },
+	GroupByAccount:   func(b Balance) string { return b.SourceAccount },
+	GroupByAssetType: func(b Balance) string { return b.AssetType },
+	GroupByCurrency:  func(b Balance) string { return b.UnitsCurrency },
+	GroupBySector:    func(b Balance) string { return classification(b, b.Sector) },
+	GroupByIssuer:    func(b Balance) string { return classification(b, b.IssuerGroup) },
+}
+
+// classification returns the classification of a balance, cash for cash balances
+// and unclassified when the symbol is not classified
+func classification(b Balance, value string) string {
+	if b.IsCash() {
+		return AssetTypeCash
+	}
+
+	return cmp.Or(value, Unclassified)
+}
+
+// GroupDimensions lists supported group_by dimensions
+func GroupDimensions() []string {
+	dimensions := make([]string, 0, len(groupKeys))
+	for d := range groupKeys {
+		dimensions = append(dimensions, d)
+	}
+
+	slices.Sort(dimensions)
+
+	return dimensions
+}
+
+// Group consolidates balances sharing the same key and currency
+type Group struct {
+	Key         string         `json:"key"                    jsonschema:"description:Value of the group_by dimension, e.g. the asset symbol, account name, asset type, currency, sector or issuer group"`
+	AssetName   string         `json:"asset_name,omitempty"   jsonschema:"description:Asset name, when grouping by asset"`
+	AssetType   string         `json:"asset_type,omitempty"   jsonschema:"description:Asset type, when grouping by asset"`
+	Currency    string         `json:"currency"               jsonschema:"description:Currency of the consolidated value"`
+	UnitsAmount float64        `json:"units_amount,omitempty" jsonschema:"description:Total units held, when grouping by asset"`
+	UnitsValue  float64        `json:"units_value"            jsonschema:"description:Total value of the grouped balances"`
+	Weight      float64        `json:"weight"                 jsonschema:"description:Percentage of the total value in the same currency"`
+	Holdings    int            `json:"holdings"               jsonschema:"description:Number of balances consolidated into the group"`
+	Accounts    []AccountShare `json:"accounts"               jsonschema:"description:Breakdown of the group per account"`
+
+	CostBasis             float64  `json:"cost_basis,omitempty"              jsonschema:"description:Total purchase cost of grouped holdings with known cost basis"`
+	UnrealizedGain        *float64 `json:"unrealized_gain,omitempty"         jsonschema:"description:Value minus cost basis of grouped holdings with known cost basis, present when any holding has a known cost basis"`
+	UnrealizedGainPercent float64  `json:"unrealized_gain_percent,omitempty" jsonschema:"description:Unrealized gain as a percentage of cost basis"`
+}
+
+// AccountShare is the part of a group held in one account
+type AccountShare struct {
+	Account     string  `json:"account"                jsonschema:"description:Account name"`
+	UnitsAmount float64 `json:"units_amount,omitempty" jsonschema:"description:Units held in the account, when grouping by asset"`
+	UnitsValue  float64 `json:"units_value"            jsonschema:"description:Value held in the account"`
+}
+
+// Description returns a one-line description of the group
+func (g Group) Description() string {
+	var b strings.Builder
+
+	b.WriteString(g.Key)
+
+	if g.AssetName != "" && g.AssetName != g.Key {
+		b.WriteString(" " + g.AssetName)
+	}
+
+	if g.AssetType != "" {
+		fmt.Fprintf(&b, " (%s)", g.AssetType)
+	}
+
+	if g.UnitsAmount != 0 {
+		fmt.Fprintf(&b, ": %f units", g.UnitsAmount)
+	} else {
+		b.WriteString(":")
+	}
+
+	fmt.Fprintf(&b, " total value %s %f (%.2f%%) in %d holdings", g.Currency, g.UnitsValue, g.Weight, g.Holdings)
+
+	if g.UnrealizedGain != nil {
+		fmt.Fprintf(&b, ", cost basis %f, unrealized gain %f (%+.2f%%)", g.CostBasis, *g.UnrealizedGain, g.UnrealizedGainPercent)
+	}
+
+	shares := make([]string, 0, len(g.Accounts))
+	for _, a := range g.Accounts {
+		shares = append(shares, fmt.Sprintf("%s %f", a.Account, a.UnitsValue))
+	}
+
+	fmt.Fprintf(&b, ", per account: %s", strings.Join(shares, ", "))
+
+	return b.String()
+}
+
+// GroupBalances consolidates balances by the dimension. Groups are ordered by currency,
+// then by value descending; account breakdowns are ordered by value descending.
+func GroupBalances(balances []Balance, by string) ([]Group, error) {
+	key, ok := groupKeys[by]
+	if !ok {
+		return nil, fmt.Errorf("unknown group_by %q, supported dimensions are %s", by, strings.Join(GroupDimensions(), ", "))
+	}
+
+	var groups []Group
+
+	index := make(map[[2]string]int)
+
+	for _, b := range balances {
+		k := [2]string{key(b), b.UnitsCurrency}
+
+		i, ok := index[k]
+		if !ok {
+			i = len(groups)
+			index[k] = i
+
+			groups = append(groups, Group{Key: k[0], Currency: b.UnitsCurrency})
+
+			if by == GroupByAsset {
+				groups[i].AssetName = b.AssetName
+				groups[i].AssetType = b.AssetType
+			}
+		}
+
+		g := &groups[i]
+		g.UnitsValue += b.UnitsValue
+		g.Holdings++
+
+		if b.HasCostBasis() {
+			g.CostBasis += b.CostBasis
+			gain := *b.UnrealizedGain
+			if g.UnrealizedGain != nil {
+				gain += *g.UnrealizedGain
+			}
+
+			g.UnrealizedGain = &gain
+
+			if g.CostBasis != 0 {
+				g.UnrealizedGainPercent = gain * 100 / g.CostBasis
+			}
+		}
+
+		share := AccountShare{Account: b.SourceAccount, UnitsValue: b.UnitsValue}
+
+		if by == GroupByAsset {
+			g.UnitsAmount += b.UnitsAmount
+			share.UnitsAmount = b.UnitsAmount
+		}
+
+		j := slices.IndexFunc(g.Accounts, func(a AccountShare) bool { return a.Account == b.SourceAccount })
+		if j < 0 {
+			g.Accounts = append(g.Accounts, share)
+		} else {
+			g.Accounts[j].UnitsAmount += share.UnitsAmount
+			g.Accounts[j].UnitsValue += share.UnitsValue
+		}
+	}
+
+	currencyTotals := make(map[string]float64)
+	for _, t := range Totals(balances) {
+		currencyTotals[t.Currency] = t.TotalValue
+	}
+
+	for i := range groups {
+		g := &groups[i]
+
+		if total := currencyTotals[g.Currency]; total != 0 {
+			g.Weight = g.UnitsValue * 100 / total
+		}
+
+		slices.SortFunc(g.Accounts, func(a, b AccountShare) int {
+			return cmp.Or(cmp.Compare(b.UnitsValue, a.UnitsValue), cmp.Compare(a.Account, b.Account))
+		})
+	}
+
+	_ = SortGroups(groups, "") // the default order is always supported
+
+	return groups, nil
+}
+
+// SortGroups orders groups in place using a sort order of Sort. An empty order sorts by value descending.
+// Value and amount orders apply within each currency, as values in different currencies are not comparable;
+// the account and symbol orders sort groups by key.
+func SortGroups(groups []Group, order string) error {
+	var primary func(a, b Group) int
+
+	switch order {
+	case "", SortValueDesc:
+		primary = func(a, b Group) int {
+			return cmp.Or(cmp.Compare(a.Currency, b.Currency), cmp.Compare(b.UnitsValue, a.UnitsValue))
+		}
+	case SortValueAsc:
+		primary = func(a, b Group) int {
+			return cmp.Or(cmp.Compare(a.Currency, b.Currency), cmp.Compare(a.UnitsValue, b.UnitsValue))
+		}
+	case SortAmountDesc:
+		primary = func(a, b Group) int {
+			return cmp.Or(cmp.Compare(a.Currency, b.Currency), cmp.Compare(b.UnitsAmount, a.UnitsAmount))
+		}
+	case SortAccount, SortSymbol:
+		primary = func(a, b Group) int { return 0 }
+	default:
+		return fmt.Errorf("unknown sort order %q, supported orders are %s", order, strings.Join(SortOrders, ", "))
+	}
+
+	slices.SortStableFunc(groups, func(a, b Group) int {
+		return cmp.Or(
+			primary(a, b),
+			cmp.Compare(a.Key, b.Key),
+			cmp.Compare(a.Currency, b.Currency),
+		)
+	})
+
+	return nil
+}
@@ -0,0 +1,120 @@
+package portfolio
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroupBalances(t *testing.T) {
+	balances := []Balance{
+		{SourceAccount: "personal", AssetSymbol: "BBCA", AssetName: "Bank Central Asia", AssetType: "equity", UnitsCurrency: "IDR", UnitsAmount: 100, UnitsValue: 900},
+		{SourceAccount: "business", AssetSymbol: "BBCA", AssetName: "Bank Central Asia", AssetType: "equity", UnitsCurrency: "IDR", UnitsAmount: 200, UnitsValue: 1800},
+		{SourceAccount: "personal", AssetSymbol: "BBCA", AssetName: "Bank Central Asia", AssetType: "equity", UnitsCurrency: "IDR", UnitsAmount: 10, UnitsValue: 90, SubAccount: "2"},
+		{SourceAccount: "personal", AssetSymbol: "IDR", AssetType: "cash", UnitsCurrency: "IDR", UnitsAmount: 210, UnitsValue: 210},
+		{SourceAccount: "broker", AssetSymbol: "AAPL", AssetType: "equity", UnitsCurrency: "USD", UnitsAmount: 1, UnitsValue: 200},
+	}
+
+	groups, err := GroupBalances(balances, GroupByAsset)
+	require.NoError(t, err)
+	assert.Equal(t, []Group{
+		{
+			Key: "BBCA", AssetName: "Bank Central Asia", AssetType: "equity", Currency: "IDR",
+			UnitsAmount: 310, UnitsValue: 2790, Weight: 93, Holdings: 3,
+			Accounts: []AccountShare{
+				{Account: "business", UnitsAmount: 200, UnitsValue: 1800},
+				{Account: "personal", UnitsAmount: 110, UnitsValue: 990},
+			},
+		},
+		{
+			Key: "IDR", AssetType: "cash", Currency: "IDR", UnitsAmount: 210, UnitsValue: 210, Weight: 7, Holdings: 1,
+			Accounts: []AccountShare{{Account: "personal", UnitsAmount: 210, UnitsValue: 210}},
+		},
+		{
+			Key: "AAPL", AssetType: "equity", Currency: "USD", UnitsAmount: 1, UnitsValue: 200, Weight: 100, Holdings: 1,
+			Accounts: []AccountShare{{Account: "broker", UnitsAmount: 1, UnitsValue: 200}},
+		},
+	}, groups)
+
+	groups, err = GroupBalances(balances, GroupByAssetType)
+	require.NoError(t, err)
+	require.Len(t, groups, 3)
+	assert.Equal(t, "equity", groups[0].Key)
+	assert.Equal(t, 2790.0, groups[0].UnitsValue)
+	assert.Zero(t, groups[0].UnitsAmount, "units of different assets are not summed")
+	assert.Equal(t, []AccountShare{{Account: "business", UnitsValue: 1800}, {Account: "personal", UnitsValue: 990}}, groups[0].Accounts)
+
+	groups, err = GroupBalances(balances, GroupByAccount)
+	require.NoError(t, err)
+	require.Len(t, groups, 3)
+	assert.Equal(t, "business", groups[0].Key)
+	assert.Equal(t, "personal", groups[1].Key)
+	assert.Equal(t, 1200.0, groups[1].UnitsValue)
+
+	groups, err = GroupBalances(balances, GroupByCurrency)
+	require.NoError(t, err)
+	require.Len(t, groups, 2)
+	assert.Equal(t, 3000.0, groups[0].UnitsValue)
+	assert.Equal(t, 4, groups[0].Holdings)
+
+	balances[0].Sector, balances[1].Sector, balances[2].Sector = "Financials", "Financials", "Financials"
+	balances[0].IssuerGroup, balances[1].IssuerGroup, balances[2].IssuerGroup = "Djarum", "Djarum", "Djarum"
+
+	groups, err = GroupBalances(balances, GroupBySector)
+	require.NoError(t, err)
+	require.Len(t, groups, 3)
+	assert.Equal(t, "Financials", groups[0].Key)
+	assert.Equal(t, 2790.0, groups[0].UnitsValue)
+	assert.Equal(t, AssetTypeCash, groups[1].Key)
+	assert.Equal(t, Unclassified, groups[2].Key)
+
+	assert.Equal(t, "Djarum", mustGroup(t, balances, GroupByIssuer)[0].Key)
+
+	assert.Equal(t,
+		"BBCA Bank Central Asia (equity): 310.000000 units total value IDR 2790.000000 (93.00%) in 3 holdings, per account: business 1800.000000, personal 990.000000",
+		mustGroup(t, balances, GroupByAsset)[0].Description())
+
+	_, err = GroupBalances(balances, "planet")
+	assert.ErrorContains(t, err, "account, asset, asset_type, currency, issuer_group, sector")
+}
+
+func TestSortGroups(t *testing.T) {
+	groups := []Group{
+		{Key: "BBCA", Currency: "IDR", UnitsAmount: 300, UnitsValue: 2700},
+		{Key: "AAPL", Currency: "USD", UnitsAmount: 1, UnitsValue: 200},
+		{Key: "TLKM", Currency: "IDR", UnitsAmount: 500, UnitsValue: 1500},
+	}
+
+	keys := func() []string {
+		var keys []string
+		for _, g := range groups {
+			keys = append(keys, g.Key)
+		}
+
+		return keys
+	}
+
+	require.NoError(t, SortGroups(groups, SortValueAsc))
+	assert.Equal(t, []string{"TLKM", "BBCA", "AAPL"}, keys(), "values are compared within each currency")
+
+	require.NoError(t, SortGroups(groups, ""))
+	assert.Equal(t, []string{"BBCA", "TLKM", "AAPL"}, keys())
+
+	require.NoError(t, SortGroups(groups, SortAmountDesc))
+	assert.Equal(t, []string{"TLKM", "BBCA", "AAPL"}, keys())
+
+	require.NoError(t, SortGroups(groups, SortSymbol))
+	assert.Equal(t, []string{"AAPL", "BBCA", "TLKM"}, keys())
+
+	assert.ErrorContains(t, SortGroups(groups, "random"), "unknown sort order")
+}
+
+func mustGroup(t *testing.T, balances []Balance, by string) []Group {
+	t.Helper()
+
+	groups, err := GroupBalances(balances, by)
+	require.NoError(t, err)
+
+	return groups
+}
@@ -0,0 +1,126 @@
+package portfolio
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Sort orders of balances
+const (
+	SortAccount    = "account"
+	SortValueDesc  = "value_desc"
+	SortValueAsc   = "value_asc"
+	SortAmountDesc = "amount_desc"
+	SortSymbol     = "symbol"
+)
+
+// SortOrders lists supported sort orders, the first one being the default
+var SortOrders = []string{SortAccount, SortValueDesc, SortValueAsc, SortAmountDesc, SortSymbol}
+
+// Filter selects balances, zero fields match everything
+type Filter struct {
+	AssetTypes []string // matched case-insensitively against AssetType
+	Symbols    []string // matched case-insensitively against AssetSymbol
+	Currency   string   // matched case-insensitively against UnitsCurrency
+	MinValue   float64  // minimum UnitsValue, in the balance's own currency
+	Tags       []string // matched case-insensitively against Tags, any one tag suffices
+}
+
+// Active reports whether the filter excludes any balance
+func (f Filter) Active() bool {
+	return len(f.AssetTypes) > 0 || len(f.Symbols) > 0 || f.Currency != "" || f.MinValue > 0 || len(f.Tags) > 0
+}
+
+// Match reports whether the balance passes the filter
+func (f Filter) Match(b Balance) bool {
+	if len(f.AssetTypes) > 0 && !containsFold(f.AssetTypes, b.AssetType) {
+		return false
+	}
+
+	if len(f.Symbols) > 0 && !containsFold(f.Symbols, b.AssetSymbol) {
+		return false
+	}
+
+	if f.Currency != "" && !strings.EqualFold(f.Currency, b.UnitsCurrency) {
+		return false
+	}
+
+	if len(f.Tags) > 0 && !slices.ContainsFunc(b.Tags, func(tag string) bool { return containsFold(f.Tags, tag) }) {
+		return false
+	}
+
+	return b.UnitsValue >= f.MinValue
+}
+
+// Apply returns the balances passing the filter
+func (f Filter) Apply(balances []Balance) []Balance {
+	var matched []Balance
+
+	for _, b := range balances {
+		if f.Match(b) {
+			matched = append(matched, b)
+		}
+	}
+
+	return matched
+}
+
+func containsFold(values []string, s string) bool {
+	return slices.ContainsFunc(values, func(v string) bool {
+		return strings.EqualFold(strings.TrimSpace(v), s)
+	})
+}
+
+// Sort orders balances in place. An empty order sorts by account. Values in different currencies are not
+// comparable, so value and amount orders sort by currency first.
+// Ties are broken by account, asset type, symbol and sub-account so the order is stable across fetches.
+func Sort(balances []Balance, order string) error {
+	var primary func(a, b Balance) int
+
+	switch order {
+	case "", SortAccount:
+		primary = func(a, b Balance) int { return 0 }
+	case SortValueDesc:
+		primary = func(a, b Balance) int {
+			return cmp.Or(cmp.Compare(a.UnitsCurrency, b.UnitsCurrency), cmp.Compare(b.UnitsValue, a.UnitsValue))
+		}
+	case SortValueAsc:
+		primary = func(a, b Balance) int {
+			return cmp.Or(cmp.Compare(a.UnitsCurrency, b.UnitsCurrency), cmp.Compare(a.UnitsValue, b.UnitsValue))
+		}
+	case SortAmountDesc:
+		primary = func(a, b Balance) int {
+			return cmp.Or(cmp.Compare(a.UnitsCurrency, b.UnitsCurrency), cmp.Compare(b.UnitsAmount, a.UnitsAmount))
+		}
+	case SortSymbol:
+		primary = func(a, b Balance) int { return cmp.Compare(a.AssetSymbol, b.AssetSymbol) }
+	default:
+		return fmt.Errorf("unknown sort order %q, supported orders are %s", order, strings.Join(SortOrders, ", "))
+	}
+
+	slices.SortStableFunc(balances, func(a, b Balance) int {
+		return cmp.Or(
+			primary(a, b),
+			cmp.Compare(a.SourceAccount, b.SourceAccount),
+			cmp.Compare(a.AssetType, b.AssetType),
+			cmp.Compare(a.AssetSymbol, b.AssetSymbol),
+			cmp.Compare(a.SubAccount, b.SubAccount),
+		)
+	})
+
+	return nil
+}
+
+// Page returns at most limit items starting at offset. A limit of zero or less means no limit.
+func Page[T any](items []T, offset, limit int) []T {
+	offset = min(max(offset, 0), len(items))
+	items = items[offset:]
+
+	if limit > 0 && limit < len(items) {
+		items = items[:limit]
+	}
+
+	return items
+}
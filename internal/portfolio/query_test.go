@@ -0,0 +1,113 @@
+package portfolio
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func symbols(balances []Balance) []string {
+	var result []string
+	for _, b := range balances {
+		result = append(result, b.AssetSymbol)
+	}
+
+	return result
+}
+
+func TestFilter_Apply(t *testing.T) {
+	balances := []Balance{
+		{SourceAccount: "personal", AssetSymbol: "BBCA", AssetType: "equity", UnitsCurrency: "IDR", UnitsValue: 9000, Tags: []string{"core", "dividend"}},
+		{SourceAccount: "personal", AssetSymbol: "TLKM", AssetType: "equity", UnitsCurrency: "IDR", UnitsValue: 300, Tags: []string{"dividend"}},
+		{SourceAccount: "personal", AssetSymbol: "IDR", AssetType: "cash", UnitsCurrency: "IDR", UnitsValue: 500},
+		{SourceAccount: "broker", AssetSymbol: "AAPL", AssetType: "equity", UnitsCurrency: "USD", UnitsValue: 2000},
+	}
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   []string
+	}{
+		{name: "empty", filter: Filter{}, want: []string{"BBCA", "TLKM", "IDR", "AAPL"}},
+		{name: "asset types", filter: Filter{AssetTypes: []string{"Cash"}}, want: []string{"IDR"}},
+		{name: "symbols", filter: Filter{Symbols: []string{"bbca", "aapl"}}, want: []string{"BBCA", "AAPL"}},
+		{name: "currency", filter: Filter{Currency: "usd"}, want: []string{"AAPL"}},
+		{name: "min value", filter: Filter{MinValue: 500}, want: []string{"BBCA", "IDR", "AAPL"}},
+		{name: "combined", filter: Filter{AssetTypes: []string{"equity"}, Currency: "IDR", MinValue: 1000}, want: []string{"BBCA"}},
+		{name: "tags", filter: Filter{Tags: []string{"Core", "growth"}}, want: []string{"BBCA"}},
+		{name: "none", filter: Filter{Symbols: []string{"GOTO"}}, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, symbols(tt.filter.Apply(balances)))
+			assert.Equal(t, tt.name != "empty", tt.filter.Active())
+		})
+	}
+}
+
+func TestSort(t *testing.T) {
+	balances := func() []Balance {
+		return []Balance{
+			{SourceAccount: "personal", AssetSymbol: "TLKM", AssetType: "equity", UnitsAmount: 100, UnitsValue: 300},
+			{SourceAccount: "broker", AssetSymbol: "BBCA", AssetType: "equity", UnitsAmount: 10, UnitsValue: 9000},
+			{SourceAccount: "personal", AssetSymbol: "BBCA", AssetType: "equity", UnitsAmount: 50, UnitsValue: 9000},
+			{SourceAccount: "personal", AssetSymbol: "IDR", AssetType: "cash", UnitsAmount: 500, UnitsValue: 500},
+		}
+	}
+
+	tests := []struct {
+		order string
+		want  []string
+	}{
+		{order: "", want: []string{"BBCA", "IDR", "BBCA", "TLKM"}},
+		{order: SortValueDesc, want: []string{"BBCA", "BBCA", "IDR", "TLKM"}},
+		{order: SortValueAsc, want: []string{"TLKM", "IDR", "BBCA", "BBCA"}},
+		{order: SortAmountDesc, want: []string{"IDR", "TLKM", "BBCA", "BBCA"}},
+		{order: SortSymbol, want: []string{"BBCA", "BBCA", "IDR", "TLKM"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.order, func(t *testing.T) {
+			b := balances()
+			require.NoError(t, Sort(b, tt.order))
+			assert.Equal(t, tt.want, symbols(b))
+		})
+	}
+
+	b := balances()
+	require.NoError(t, Sort(b, SortValueDesc))
+	assert.Equal(t, "broker", b[0].SourceAccount, "ties broken by account")
+
+	assert.Error(t, Sort(balances(), "random"))
+}
+
+func TestSort_Currencies(t *testing.T) {
+	b := []Balance{
+		{AssetSymbol: "BBCA", UnitsAmount: 100, UnitsValue: 1_000_000, UnitsCurrency: "IDR"},
+		{AssetSymbol: "VOO", UnitsAmount: 10, UnitsValue: 5_000, UnitsCurrency: "USD"},
+		{AssetSymbol: "TLKM", UnitsAmount: 1000, UnitsValue: 3_000_000, UnitsCurrency: "IDR"},
+		{AssetSymbol: "AAPL", UnitsAmount: 20, UnitsValue: 4_000, UnitsCurrency: "USD"},
+	}
+
+	require.NoError(t, Sort(b, SortValueDesc))
+	assert.Equal(t, []string{"TLKM", "BBCA", "VOO", "AAPL"}, symbols(b))
+
+	require.NoError(t, Sort(b, SortValueAsc))
+	assert.Equal(t, []string{"BBCA", "TLKM", "AAPL", "VOO"}, symbols(b))
+
+	require.NoError(t, Sort(b, SortAmountDesc))
+	assert.Equal(t, []string{"TLKM", "BBCA", "AAPL", "VOO"}, symbols(b))
+}
+
+func TestPage(t *testing.T) {
+	balances := []Balance{{AssetSymbol: "A"}, {AssetSymbol: "B"}, {AssetSymbol: "C"}}
+
+	assert.Equal(t, []string{"A", "B", "C"}, symbols(Page(balances, 0, 0)))
+	assert.Equal(t, []string{"A", "B"}, symbols(Page(balances, 0, 2)))
+	assert.Equal(t, []string{"B", "C"}, symbols(Page(balances, 1, 5)))
+	assert.Empty(t, Page(balances, 3, 1))
+	assert.Empty(t, Page(balances, 10, 0))
+	assert.Equal(t, []string{"A"}, symbols(Page(balances, -1, 1)))
+}
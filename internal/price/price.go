@@ -0,0 +1,172 @@
+// Package price keeps a local history of asset prices by symbol and date
+package price
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chickenzord/portosync/internal/datafile"
+	"github.com/chickenzord/portosync/internal/portfolio"
+)
+
+// Price sources. Imported prices are authoritative and replace prices implied by snapshots.
+const (
+	SourceImported = "imported"
+	SourceSnapshot = "snapshot"
+)
+
+// Column names recognised as prices when importing, in order of preference
+var priceColumns = []string{"price", "close", "nav", "value"}
+
+// defaultCurrency is used for imported prices without a currency column
+const defaultCurrency = "IDR"
+
+// Price is the price of one unit of an asset at a date
+type Price struct {
+	Symbol   string    `json:"symbol"   jsonschema:"description:Asset symbol or fund code"`
+	Date     time.Time `json:"date"     jsonschema:"description:Price date"`
+	Price    float64   `json:"price"    jsonschema:"description:Price of one unit"`
+	Currency string    `json:"currency" jsonschema:"description:Currency of the price"`
+	Source   string    `json:"source"   jsonschema:"description:imported when read from a CSV file such as fund NAVs or closing prices, snapshot when implied by the value per unit of a stored snapshot"`
+}
+
+// FromBalances returns the prices implied by balances at a date, as value per unit.
+// Holdings of the same symbol in several accounts are combined. Cash is skipped.
+func FromBalances(date time.Time, balances []portfolio.Balance) []Price {
+	type key struct{ symbol, currency string }
+
+	units := make(map[key]float64)
+	values := make(map[key]float64)
+
+	for _, b := range balances {
+		if b.IsCash() || b.AssetSymbol == "" || b.UnitsAmount <= 0 || b.UnitsValue <= 0 {
+			continue
+		}
+
+		k := key{strings.ToUpper(b.AssetSymbol), b.UnitsCurrency}
+		units[k] += b.UnitsAmount
+		values[k] += b.UnitsValue
+	}
+
+	prices := make([]Price, 0, len(units))
+
+	for k, u := range units {
+		prices = append(prices, Price{
+			Symbol:   k.symbol,
+			Date:     portfolio.DateOf(date),
+			Price:    values[k] / u,
+			Currency: k.currency,
+			Source:   SourceSnapshot,
+		})
+	}
+
+	Sort(prices)
+
+	return prices
+}
+
+// Load reads imported prices from a CSV file, see Read
+func Load(path, symbol string) ([]Price, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	prices, err := Read(f, symbol)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return prices, nil
+}
+
+// Read reads imported prices from CSV with header columns date, symbol, a price column
+// (price, close, nav or value) and optionally currency, which defaults to IDR.
+// The symbol column may be omitted when symbol is given, e.g. for the NAV history of a single fund.
+func Read(r io.Reader, symbol string) ([]Price, error) {
+	columns, records, err := datafile.ReadCSV(r, ',')
+	if err != nil {
+		return nil, err
+	}
+
+	if len(columns) == 0 {
+		return nil, nil
+	}
+
+	if err := datafile.RequireColumns(columns, "date"); err != nil {
+		return nil, err
+	}
+
+	if !slices.Contains(columns, "symbol") && symbol == "" {
+		return nil, errors.New(`missing column "symbol", or pass the symbol of all rows`)
+	}
+
+	priceColumn := ""
+
+	for _, c := range priceColumns {
+		if slices.Contains(columns, c) {
+			priceColumn = c
+
+			break
+		}
+	}
+
+	if priceColumn == "" {
+		return nil, fmt.Errorf("missing price column, expected one of %s", strings.Join(priceColumns, ", "))
+	}
+
+	var prices []Price
+
+	for i, record := range records {
+		if record.Get("date") == "" {
+			continue
+		}
+
+		date, err := portfolio.ParseDate(record.Get("date"))
+		if err != nil {
+			return nil, fmt.Errorf("row %d: invalid date: %w", i+2, err)
+		}
+
+		value, err := strconv.ParseFloat(strings.ReplaceAll(record.Get(priceColumn), ",", ""), 64)
+		if err != nil {
+			return nil, fmt.Errorf("row %d: invalid %s: %w", i+2, priceColumn, err)
+		}
+
+		if value <= 0 {
+			return nil, fmt.Errorf("row %d: %s must be positive", i+2, priceColumn)
+		}
+
+		p := Price{
+			Symbol:   strings.ToUpper(cmp.Or(record.Get("symbol"), symbol)),
+			Date:     date,
+			Price:    value,
+			Currency: strings.ToUpper(cmp.Or(record.Get("currency"), defaultCurrency)),
+			Source:   SourceImported,
+		}
+
+		if p.Symbol == "" {
+			return nil, fmt.Errorf("row %d: missing symbol", i+2)
+		}
+
+		prices = append(prices, p)
+	}
+
+	Sort(prices)
+
+	return prices, nil
+}
+
+// Sort orders prices by symbol and date
+func Sort(prices []Price) {
+	slices.SortStableFunc(prices, func(a, b Price) int {
+		return cmp.Or(cmp.Compare(a.Symbol, b.Symbol), a.Date.Compare(b.Date))
+	})
+}
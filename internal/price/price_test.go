@@ -0,0 +1,108 @@
+package price
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chickenzord/portosync/internal/portfolio"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func date(s string) time.Time {
+	d, err := portfolio.ParseDate(s)
+	if err != nil {
+		panic(err)
+	}
+
+	return d
+}
+
+func TestFromBalances(t *testing.T) {
+	prices := FromBalances(date("2024-01-02"), []portfolio.Balance{
+		{SourceAccount: "personal", AssetSymbol: "BBCA", AssetType: "equity", UnitsAmount: 100, UnitsValue: 1000000, UnitsCurrency: "IDR"},
+		{SourceAccount: "business", AssetSymbol: "bbca", AssetType: "equity", UnitsAmount: 300, UnitsValue: 3000000, UnitsCurrency: "IDR"},
+		{SourceAccount: "personal", AssetSymbol: "IDR", AssetType: portfolio.AssetTypeCash, UnitsAmount: 500, UnitsValue: 500, UnitsCurrency: "IDR"},
+		{SourceAccount: "personal", AssetSymbol: "EMPTY", AssetType: "equity", UnitsCurrency: "IDR"},
+	})
+
+	assert.Equal(t, []Price{
+		{Symbol: "BBCA", Date: date("2024-01-02"), Price: 10000, Currency: "IDR", Source: SourceSnapshot},
+	}, prices)
+}
+
+func TestRead(t *testing.T) {
+	prices, err := Read(strings.NewReader("\ufeffDate,Symbol,Close,Currency\n2024-01-03,bbca,\"9,950\",\n2024-01-02,BBCA,9900,idr\n,,,\n"), "")
+	require.NoError(t, err)
+	assert.Equal(t, []Price{
+		{Symbol: "BBCA", Date: date("2024-01-02"), Price: 9900, Currency: "IDR", Source: SourceImported},
+		{Symbol: "BBCA", Date: date("2024-01-03"), Price: 9950, Currency: "IDR", Source: SourceImported},
+	}, prices)
+
+	prices, err = Read(strings.NewReader("date,nav\n2024-01-02,1523.45\n"), "scmmf")
+	require.NoError(t, err)
+	require.Len(t, prices, 1)
+	assert.Equal(t, "SCMMF", prices[0].Symbol)
+	assert.Equal(t, 1523.45, prices[0].Price)
+
+	for _, input := range []string{
+		"symbol,price\nBBCA,1\n",
+		"date,price\n2024-01-02,1\n",
+		"date,symbol,volume\n2024-01-02,BBCA,1\n",
+		"date,symbol,price\n02/01/2024,BBCA,1\n",
+		"date,symbol,price\n2024-01-02,BBCA,abc\n",
+		"date,symbol,price\n2024-01-02,BBCA,0\n",
+	} {
+		_, err := Read(strings.NewReader(input), "")
+		assert.Error(t, err, input)
+	}
+}
+
+func TestStore(t *testing.T) {
+	store, err := NewStore(t.TempDir())
+	require.NoError(t, err)
+
+	n, err := store.Put([]Price{
+		{Symbol: "BBCA", Date: date("2024-01-02"), Price: 9900, Currency: "IDR", Source: SourceImported},
+		{Symbol: "BBCA", Date: date("2024-01-04"), Price: 10000, Currency: "IDR", Source: SourceSnapshot},
+		{Symbol: "BBCA", Date: date("2024-01-03"), Price: 9950, Currency: "IDR", Source: SourceSnapshot},
+		{Symbol: "SCMMF", Date: date("2024-01-02"), Price: 1500, Currency: "IDR", Source: SourceSnapshot},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 4, n)
+
+	// Snapshot prices do not replace imported prices, imported prices replace snapshot prices
+	n, err = store.Put([]Price{
+		{Symbol: "BBCA", Date: date("2024-01-02"), Price: 9000, Currency: "IDR", Source: SourceSnapshot},
+		{Symbol: "BBCA", Date: date("2024-01-03"), Price: 9925, Currency: "IDR", Source: SourceImported},
+		{Symbol: "SCMMF", Date: date("2024-01-02"), Price: 1500, Currency: "IDR", Source: SourceSnapshot},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	history, err := store.History("bbca", time.Time{}, time.Time{})
+	require.NoError(t, err)
+	assert.Equal(t, []Price{
+		{Symbol: "BBCA", Date: date("2024-01-02"), Price: 9900, Currency: "IDR", Source: SourceImported},
+		{Symbol: "BBCA", Date: date("2024-01-03"), Price: 9925, Currency: "IDR", Source: SourceImported},
+		{Symbol: "BBCA", Date: date("2024-01-04"), Price: 10000, Currency: "IDR", Source: SourceSnapshot},
+	}, history)
+
+	history, err = store.History("BBCA", date("2024-01-03"), date("2024-01-03"))
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+
+	p, ok, err := store.At("BBCA", date("2024-01-10"))
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, 10000.0, p.Price)
+
+	_, ok, err = store.At("BBCA", date("2024-01-01"))
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	symbols, err := store.Symbols()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"BBCA", "SCMMF"}, symbols)
+}
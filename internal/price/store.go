@@ -0,0 +1,198 @@
+package price
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chickenzord/portosync/internal/datafile"
+	"github.com/chickenzord/portosync/internal/portfolio"
+)
+
+const fileExt = ".json"
+
+// record is a price as stored on disk, dated by local calendar date
+type record struct {
+	Date     string  `json:"date"`
+	Price    float64 `json:"price"`
+	Currency string  `json:"currency"`
+	Source   string  `json:"source"`
+}
+
+// Store keeps one JSON file of prices per symbol inside a directory, one price per date
+type Store struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewStore creates a price store in dir, creating the directory if needed
+func NewStore(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("cannot create price dir: %w", err)
+	}
+
+	return &Store{dir: dir}, nil
+}
+
+// path returns the file of a symbol, replacing characters that are unsafe in file names
+func (s *Store) path(symbol string) string {
+	name := strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == os.PathSeparator || r == ':' {
+			return '_'
+		}
+
+		return r
+	}, strings.ToUpper(symbol))
+
+	return filepath.Join(s.dir, name+fileExt)
+}
+
+// Put stores prices, returning the number of symbol and date pairs added or changed.
+// A price replaces the stored price of the same symbol and date,
+// except that prices implied by snapshots never replace imported prices.
+func (s *Store) Put(prices []Price) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	bySymbol := make(map[string][]Price)
+	for _, p := range prices {
+		symbol := strings.ToUpper(p.Symbol)
+		bySymbol[symbol] = append(bySymbol[symbol], p)
+	}
+
+	changed := 0
+
+	for symbol, incoming := range bySymbol {
+		records, err := s.read(symbol)
+		if err != nil {
+			return changed, err
+		}
+
+		dates := make(map[string]bool)
+
+		for _, p := range incoming {
+			r := record{
+				Date:     p.Date.Format(time.DateOnly),
+				Price:    p.Price,
+				Currency: p.Currency,
+				Source:   p.Source,
+			}
+
+			i, found := slices.BinarySearchFunc(records, r.Date, func(r record, date string) int { return strings.Compare(r.Date, date) })
+
+			switch {
+			case !found:
+				records = slices.Insert(records, i, r)
+			case records[i] == r || (records[i].Source == SourceImported && r.Source != SourceImported):
+				continue
+			default:
+				records[i] = r
+			}
+
+			dates[r.Date] = true
+		}
+
+		if len(dates) == 0 {
+			continue
+		}
+
+		if err := s.write(symbol, records); err != nil {
+			return changed, err
+		}
+
+		changed += len(dates)
+	}
+
+	return changed, nil
+}
+
+// History returns prices of a symbol between from and to (inclusive) in ascending date order.
+// A zero from or to leaves that side of the range open.
+func (s *Store) History(symbol string, from, to time.Time) ([]Price, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	records, err := s.read(symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	var prices []Price
+
+	for _, r := range records {
+		p, err := r.price(symbol)
+		if err != nil {
+			return nil, err
+		}
+
+		if (!from.IsZero() && p.Date.Before(portfolio.DateOf(from))) || (!to.IsZero() && p.Date.After(portfolio.DateOf(to))) {
+			continue
+		}
+
+		prices = append(prices, p)
+	}
+
+	return prices, nil
+}
+
+// At returns the latest price of a symbol on or before date
+func (s *Store) At(symbol string, date time.Time) (Price, bool, error) {
+	prices, err := s.History(symbol, time.Time{}, date)
+	if err != nil || len(prices) == 0 {
+		return Price{}, false, err
+	}
+
+	return prices[len(prices)-1], true, nil
+}
+
+// Symbols returns all symbols with stored prices in ascending order
+func (s *Store) Symbols() ([]string, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var symbols []string
+
+	for _, entry := range entries {
+		if name, ok := strings.CutSuffix(entry.Name(), fileExt); ok && !entry.IsDir() {
+			symbols = append(symbols, name)
+		}
+	}
+
+	slices.Sort(symbols)
+
+	return symbols, nil
+}
+
+func (r record) price(symbol string) (Price, error) {
+	date, err := portfolio.ParseDate(r.Date)
+	if err != nil {
+		return Price{}, fmt.Errorf("invalid price date of %s: %w", symbol, err)
+	}
+
+	return Price{
+		Symbol:   strings.ToUpper(symbol),
+		Date:     date,
+		Price:    r.Price,
+		Currency: r.Currency,
+		Source:   r.Source,
+	}, nil
+}
+
+func (s *Store) read(symbol string) ([]record, error) {
+	var records []record
+	if _, err := datafile.ReadJSON(s.path(symbol), &records); err != nil {
+		return nil, fmt.Errorf("cannot read prices of %s: %w", symbol, err)
+	}
+
+	return records, nil
+}
+
+func (s *Store) write(symbol string, records []record) error {
+	return datafile.WriteJSON(s.path(symbol), records)
+}
@@ -0,0 +1,295 @@
+// Package rebalance compares portfolio allocation against target weights and suggests trades
+package rebalance
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/chickenzord/portosync/internal/portfolio"
+)
+
+const (
+	// DefaultTolerance is the allowed deviation in percentage points before suggesting a trade
+	DefaultTolerance = 5.0
+
+	// IDXLotSize is the number of shares in one lot of IDX equities
+	IDXLotSize = 100
+
+	// OtherKey is the target key of holdings not matched by any target
+	OtherKey = "other"
+
+	symbolPrefix = "symbol:"
+)
+
+// Target is a desired weight of an asset type, asset type and sub-type, or a single symbol
+type Target struct {
+	AssetType    string
+	AssetSubType string
+	Symbol       string
+	Weight       float64 // percent of total value
+}
+
+// Key returns the target in its configuration syntax: "equity", "mutual_fund/money_market_fund" or "symbol:BBCA"
+func (t Target) Key() string {
+	switch {
+	case t.Symbol != "":
+		return symbolPrefix + t.Symbol
+	case t.AssetSubType != "":
+		return t.AssetType + "/" + t.AssetSubType
+	default:
+		return t.AssetType
+	}
+}
+
+// specificity ranks targets so that holdings match the most specific one
+func (t Target) specificity() int {
+	switch {
+	case t.Symbol != "":
+		return 3
+	case t.AssetSubType != "":
+		return 2
+	default:
+		return 1
+	}
+}
+
+// Matches reports whether the balance belongs to the target
+func (t Target) Matches(b portfolio.Balance) bool {
+	switch {
+	case t.Symbol != "":
+		return strings.EqualFold(t.Symbol, b.AssetSymbol)
+	case t.AssetSubType != "":
+		return strings.EqualFold(t.AssetType, b.AssetType) && strings.EqualFold(t.AssetSubType, b.AssetSubType)
+	default:
+		return strings.EqualFold(t.AssetType, b.AssetType)
+	}
+}
+
+// ParseTargets parses target weights keyed by "asset_type", "asset_type/sub_type" or "symbol:SYMBOL".
+// Weights are in percent and must not sum to more than 100.
+func ParseTargets(weights map[string]string) ([]Target, error) {
+	targets := make([]Target, 0, len(weights))
+
+	var sum float64
+
+	for key, value := range weights {
+		weight, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(value), "%"), 64)
+		if err != nil || weight < 0 {
+			return nil, fmt.Errorf("invalid target weight %q for %s", value, key)
+		}
+
+		t := Target{Weight: weight}
+
+		if symbol, ok := strings.CutPrefix(key, symbolPrefix); ok {
+			t.Symbol = strings.ToUpper(strings.TrimSpace(symbol))
+		} else {
+			assetType, subType, _ := strings.Cut(key, "/")
+			t.AssetType = strings.TrimSpace(assetType)
+			t.AssetSubType = strings.TrimSpace(subType)
+		}
+
+		if t.Key() == "" || t.Key() == symbolPrefix {
+			return nil, fmt.Errorf("invalid target %q", key)
+		}
+
+		sum += weight
+		targets = append(targets, t)
+	}
+
+	if sum > 100+1e-9 {
+		return nil, fmt.Errorf("target weights sum to %g%%, must not exceed 100%%", sum)
+	}
+
+	slices.SortFunc(targets, func(a, b Target) int { return cmp.Compare(a.Key(), b.Key()) })
+
+	return targets, nil
+}
+
+// ParseTargetString parses targets in the format "equity=60,bond=30,symbol:BBCA=10"
+func ParseTargetString(s string) ([]Target, error) {
+	weights := make(map[string]string)
+
+	for entry := range strings.SplitSeq(s, ",") {
+		key, value, ok := strings.Cut(entry, "=")
+		if !ok {
+			if strings.TrimSpace(entry) != "" {
+				return nil, fmt.Errorf("invalid target %q, expected key=weight", entry)
+			}
+
+			continue
+		}
+
+		weights[strings.TrimSpace(key)] = value
+	}
+
+	return ParseTargets(weights)
+}
+
+// Suggestion is the trade needed to bring one target back to its weight
+type Suggestion struct {
+	Target          string  `json:"target"           jsonschema:"description:Target key: asset type, asset_type/sub_type, symbol:SYMBOL or other for unmatched holdings"`
+	TargetWeight    float64 `json:"target_weight"    jsonschema:"description:Target weight in percent of total value"`
+	CurrentWeight   float64 `json:"current_weight"   jsonschema:"description:Current weight in percent of total value"`
+	Deviation       float64 `json:"deviation"        jsonschema:"description:Current weight minus target weight in percentage points"`
+	CurrentValue    float64 `json:"current_value"    jsonschema:"description:Current value of matched holdings"`
+	TargetValue     float64 `json:"target_value"     jsonschema:"description:Value matching the target weight"`
+	Action          string  `json:"action"           jsonschema:"description:buy or sell, hold when within the tolerance band, or below_lot when the trade of an IDX equity symbol is smaller than one lot"`
+	Amount          float64 `json:"amount"           jsonschema:"description:Value to buy (positive) or sell (negative). Rounded down to whole lots for IDX equity symbol targets only, asset type targets are not rounded"`
+	Lots            int     `json:"lots,omitempty"   jsonschema:"description:Number of IDX lots (100 shares) to trade, for equity symbol targets only"`
+	Shares          float64 `json:"shares,omitempty" jsonschema:"description:Number of shares to trade, for equity symbol targets only"`
+	WithinTolerance bool    `json:"within_tolerance" jsonschema:"description:Whether the deviation is within the tolerance band"`
+	Holdings        int     `json:"holdings"         jsonschema:"description:Number of holdings matched by the target"`
+}
+
+// Plan is the result of comparing balances in a single currency against targets
+type Plan struct {
+	Currency    string       `json:"currency"    jsonschema:"description:Currency of all values in the plan"`
+	TotalValue  float64      `json:"total_value" jsonschema:"description:Total value of holdings in the currency"`
+	Tolerance   float64      `json:"tolerance"   jsonschema:"description:Tolerance band in percentage points"`
+	Suggestions []Suggestion `json:"suggestions" jsonschema:"description:Comparison and suggested trade per target"`
+}
+
+// Quote is the last known price of a symbol, used to round the trade of a symbol target without holdings
+type Quote struct {
+	Price     float64 // per share
+	Currency  string
+	AssetType string
+}
+
+// QuoteFunc returns the quote of a symbol, reporting false when its price is unknown
+type QuoteFunc func(symbol string) (Quote, bool)
+
+// Suggest compares balances in the given currency against targets.
+// Each balance counts towards its most specific matching target, unmatched balances
+// are grouped into the "other" target with the weight left over by all targets.
+// Symbol targets without holdings are rounded to lots using quote, which may be nil.
+func Suggest(balances []portfolio.Balance, targets []Target, currency string, tolerance float64, quote QuoteFunc) Plan {
+	plan := Plan{Currency: currency, Tolerance: tolerance}
+
+	groups := make([][]portfolio.Balance, len(targets))
+
+	var other []portfolio.Balance
+
+	for _, b := range balances {
+		if b.UnitsCurrency != currency {
+			continue
+		}
+
+		plan.TotalValue += b.UnitsValue
+
+		best := -1
+
+		for i, t := range targets {
+			if t.Matches(b) && (best < 0 || t.specificity() > targets[best].specificity()) {
+				best = i
+			}
+		}
+
+		if best < 0 {
+			other = append(other, b)
+		} else {
+			groups[best] = append(groups[best], b)
+		}
+	}
+
+	remaining := 100.0
+
+	for i, t := range targets {
+		remaining -= t.Weight
+		plan.Suggestions = append(plan.Suggestions, suggest(t, groups[i], plan.TotalValue, tolerance, quote))
+	}
+
+	if len(other) > 0 || remaining > 1e-9 {
+		plan.Suggestions = append(plan.Suggestions, suggest(Target{AssetType: OtherKey, Weight: math.Max(remaining, 0)}, other, plan.TotalValue, tolerance, nil))
+	}
+
+	return plan
+}
+
+func suggest(t Target, holdings []portfolio.Balance, total, tolerance float64, quote QuoteFunc) Suggestion {
+	s := Suggestion{
+		Target:       t.Key(),
+		TargetWeight: t.Weight,
+		TargetValue:  total * t.Weight / 100,
+		Holdings:     len(holdings),
+		Action:       "hold",
+	}
+
+	var units float64
+
+	for _, b := range holdings {
+		s.CurrentValue += b.UnitsValue
+		units += b.UnitsAmount
+	}
+
+	if total > 0 {
+		s.CurrentWeight = s.CurrentValue / total * 100
+	}
+
+	s.Deviation = s.CurrentWeight - s.TargetWeight
+	s.WithinTolerance = math.Abs(s.Deviation) <= tolerance
+
+	if s.WithinTolerance {
+		return s
+	}
+
+	s.Amount = s.TargetValue - s.CurrentValue
+
+	// IDX equities can only be traded in whole lots, so round the trade of a symbol down to lots.
+	// Asset type targets span several symbols of different lot prices and are not rounded.
+	var (
+		price float64
+		lots  bool
+	)
+
+	if t.Symbol != "" && units > 0 {
+		price, lots = s.CurrentValue/units, isIDXEquity(holdings)
+	} else if t.Symbol != "" && len(holdings) == 0 && quote != nil {
+		// Not held yet, which is when the lots to buy matter most
+		if q, ok := quote(t.Symbol); ok && q.Price > 0 {
+			price, lots = q.Price, isIDXAsset(q.AssetType, q.Currency)
+		}
+	}
+
+	if lots {
+		s.Lots = int(math.Abs(s.Amount) / (price * IDXLotSize))
+		s.Shares = float64(s.Lots * IDXLotSize)
+		s.Amount = math.Copysign(s.Shares*price, s.Amount)
+
+		if s.Lots == 0 {
+			// Outside the tolerance band, but no whole lot can be traded
+			s.Amount = 0
+			s.Action = "below_lot"
+
+			return s
+		}
+	}
+
+	switch {
+	case s.Amount > 0:
+		s.Action = "buy"
+	case s.Amount < 0:
+		s.Action = "sell"
+	}
+
+	return s
+}
+
+// isIDXEquity reports whether holdings are IDR equities, which trade in lots on IDX
+func isIDXEquity(holdings []portfolio.Balance) bool {
+	for _, b := range holdings {
+		if !isIDXAsset(b.AssetType, b.UnitsCurrency) {
+			return false
+		}
+	}
+
+	return len(holdings) > 0
+}
+
+func isIDXAsset(assetType, currency string) bool {
+	return strings.EqualFold(assetType, "equity") && strings.EqualFold(currency, "IDR")
+}
@@ -0,0 +1,148 @@
+package rebalance
+
+import (
+	"testing"
+
+	"github.com/chickenzord/portosync/internal/portfolio"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTargetString(t *testing.T) {
+	targets, err := ParseTargetString("equity=50, mutual_fund/money_market_fund=20%,symbol:bbca=10")
+	require.NoError(t, err)
+
+	assert.Equal(t, []Target{
+		{AssetType: "equity", Weight: 50},
+		{AssetType: "mutual_fund", AssetSubType: "money_market_fund", Weight: 20},
+		{Symbol: "BBCA", Weight: 10},
+	}, targets)
+
+	_, err = ParseTargetString("equity=70,bond=40")
+	assert.ErrorContains(t, err, "must not exceed 100%")
+
+	_, err = ParseTargetString("equity=abc")
+	assert.Error(t, err)
+
+	_, err = ParseTargetString("equity")
+	assert.Error(t, err)
+
+	_, err = ParseTargetString("symbol:=10")
+	assert.Error(t, err)
+
+	targets, err = ParseTargetString("")
+	assert.NoError(t, err)
+	assert.Empty(t, targets)
+}
+
+func TestSuggest(t *testing.T) {
+	balances := []portfolio.Balance{
+		{AssetSymbol: "BBCA", AssetType: "equity", UnitsAmount: 1000, UnitsValue: 10_000_000, UnitsCurrency: "IDR"},
+		{AssetSymbol: "TLKM", AssetType: "equity", UnitsAmount: 10000, UnitsValue: 30_000_000, UnitsCurrency: "IDR"},
+		{AssetSymbol: "FR0091", AssetType: "bond", UnitsAmount: 1, UnitsValue: 40_000_000, UnitsCurrency: "IDR"},
+		{AssetSymbol: "IDR", AssetType: "cash", UnitsAmount: 20_000_000, UnitsValue: 20_000_000, UnitsCurrency: "IDR"},
+		{AssetSymbol: "VOO", AssetType: "equity", UnitsAmount: 1, UnitsValue: 500, UnitsCurrency: "USD"},
+	}
+
+	targets := []Target{
+		{AssetType: "equity", Weight: 30},
+		{AssetType: "bond", Weight: 42},
+		{Symbol: "BBCA", Weight: 20},
+	}
+
+	plan := Suggest(balances, targets, "IDR", 5, nil)
+
+	assert.Equal(t, "IDR", plan.Currency)
+	assert.Equal(t, 100_000_000.0, plan.TotalValue)
+	require.Len(t, plan.Suggestions, 4)
+
+	// BBCA matches its symbol target instead of the equity target
+	equity := plan.Suggestions[0]
+	assert.Equal(t, "equity", equity.Target)
+	assert.Equal(t, 1, equity.Holdings)
+	assert.Equal(t, 30.0, equity.CurrentWeight)
+	assert.True(t, equity.WithinTolerance)
+	assert.Equal(t, "hold", equity.Action)
+	assert.Zero(t, equity.Amount)
+
+	bond := plan.Suggestions[1]
+	assert.Equal(t, "bond", bond.Target)
+	assert.InDelta(t, -2.0, bond.Deviation, 1e-9)
+	assert.True(t, bond.WithinTolerance)
+
+	// 10M below target at 10,000 per share is 10 lots
+	bbca := plan.Suggestions[2]
+	assert.Equal(t, "symbol:BBCA", bbca.Target)
+	assert.False(t, bbca.WithinTolerance)
+	assert.Equal(t, "buy", bbca.Action)
+	assert.Equal(t, 10, bbca.Lots)
+	assert.Equal(t, 1000.0, bbca.Shares)
+	assert.InDelta(t, 10_000_000, bbca.Amount, 1e-6)
+
+	// Cash is not targeted, so it falls into other with the remaining 8%
+	other := plan.Suggestions[3]
+	assert.Equal(t, OtherKey, other.Target)
+	assert.InDelta(t, 8.0, other.TargetWeight, 1e-9)
+	assert.Equal(t, 20.0, other.CurrentWeight)
+	assert.Equal(t, "sell", other.Action)
+	assert.InDelta(t, -12_000_000, other.Amount, 1e-6)
+	assert.Zero(t, other.Lots)
+}
+
+func TestSuggest_LotRounding(t *testing.T) {
+	balances := []portfolio.Balance{
+		{AssetSymbol: "BBCA", AssetType: "equity", UnitsAmount: 250, UnitsValue: 2_500_000, UnitsCurrency: "IDR"},
+		{AssetSymbol: "IDR", AssetType: "cash", UnitsAmount: 7_450_000, UnitsValue: 7_450_000, UnitsCurrency: "IDR"},
+	}
+
+	// Target value 2,985,000 is 485,000 short, less than one lot worth 1,000,000
+	plan := Suggest(balances, []Target{{Symbol: "BBCA", Weight: 30}}, "IDR", 0, nil)
+	require.NotEmpty(t, plan.Suggestions)
+
+	bbca := plan.Suggestions[0]
+	assert.False(t, bbca.WithinTolerance)
+	assert.Equal(t, 0, bbca.Lots)
+	assert.Zero(t, bbca.Amount)
+	assert.Equal(t, "below_lot", bbca.Action)
+}
+
+func TestSuggest_LotRoundingNotHeld(t *testing.T) {
+	balances := []portfolio.Balance{
+		{AssetSymbol: "BBCA", AssetType: "Equity", UnitsAmount: 500, UnitsValue: 5_000_000, UnitsCurrency: "IDR"},
+		{AssetSymbol: "IDR", AssetType: "cash", UnitsAmount: 5_000_000, UnitsValue: 5_000_000, UnitsCurrency: "IDR"},
+	}
+	targets := []Target{{Symbol: "BBCA", Weight: 40}, {Symbol: "TLKM", Weight: 25}, {Symbol: "ASII", Weight: 1}}
+	quote := func(symbol string) (Quote, bool) {
+		switch symbol {
+		case "TLKM":
+			return Quote{Price: 3000, Currency: "IDR", AssetType: "equity"}, true
+		case "ASII":
+			return Quote{Price: 5000, Currency: "IDR", AssetType: "equity"}, true
+		}
+
+		return Quote{}, false
+	}
+
+	plan := Suggest(balances, targets, "IDR", 0, quote)
+	require.Len(t, plan.Suggestions, 4)
+
+	// Asset types compare case-insensitively, 1,000,000 above target is one lot
+	bbca := plan.Suggestions[0]
+	assert.Equal(t, "symbol:BBCA", bbca.Target)
+	assert.Equal(t, "sell", bbca.Action)
+	assert.Equal(t, 1, bbca.Lots)
+
+	// 2,500,000 to buy at 300,000 per lot is 8 lots
+	tlkm := plan.Suggestions[1]
+	assert.Equal(t, "symbol:TLKM", tlkm.Target)
+	assert.Equal(t, "buy", tlkm.Action)
+	assert.Equal(t, 8, tlkm.Lots)
+	assert.Equal(t, 800.0, tlkm.Shares)
+	assert.InDelta(t, 2_400_000, tlkm.Amount, 1e-6)
+
+	// 100,000 to buy is less than one lot worth 500,000
+	asii := plan.Suggestions[2]
+	assert.Equal(t, "symbol:ASII", asii.Target)
+	assert.Equal(t, "below_lot", asii.Action)
+	assert.Zero(t, asii.Amount)
+}
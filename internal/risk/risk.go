@@ -0,0 +1,235 @@
+// Package risk measures how concentrated a portfolio is in single holdings, sectors, issuers and currencies
+package risk
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/chickenzord/portosync/internal/portfolio"
+)
+
+const (
+	// DefaultTopN is the number of largest positions summed into the top-N share
+	DefaultTopN = 5
+
+	// DefaultMaxPosition is the share in percent of holdings value a single position may reach before a warning
+	DefaultMaxPosition = 20.0
+
+	// DefaultMaxSector is the share in percent of holdings value a single sector or issuer may reach before a warning
+	DefaultMaxSector = 40.0
+
+	// Unclassified is the sector and issuer of holdings missing from the classification
+	Unclassified = portfolio.Unclassified
+)
+
+// Options configures a report
+type Options struct {
+	Currency    string             // currency of the report, balances in other currencies are converted using Rates
+	Rates       map[string]float64 // value of one unit of a currency in Currency
+	TopN        int
+	MaxPosition float64
+	MaxSector   float64
+
+	// Classify returns the sector and issuer of a balance, reporting false when unknown.
+	// Sector and issuer exposure is left out when nil.
+	Classify func(b portfolio.Balance) (sector, issuer string, ok bool)
+}
+
+// Position is a holding consolidated across accounts
+type Position struct {
+	Symbol   string   `json:"symbol"   jsonschema:"description:Asset symbol or fund code"`
+	Name     string   `json:"name"     jsonschema:"description:Asset name"`
+	Value    float64  `json:"value"    jsonschema:"description:Value in the report currency"`
+	Share    float64  `json:"share"    jsonschema:"description:Share of holdings value in percent"`
+	Accounts []string `json:"accounts" jsonschema:"description:Accounts holding the asset"`
+}
+
+// Exposure is the value held in a sector, issuer or currency
+type Exposure struct {
+	Key      string  `json:"key"      jsonschema:"description:Sector, issuer or currency"`
+	Value    float64 `json:"value"    jsonschema:"description:Value in the report currency"`
+	Share    float64 `json:"share"    jsonschema:"description:Share of the total value in percent"`
+	Holdings int     `json:"holdings" jsonschema:"description:Number of distinct symbols held"`
+}
+
+// Report holds concentration metrics of holdings, excluding cash, and currency exposure including cash
+type Report struct {
+	Currency           string     `json:"currency"            jsonschema:"description:Currency all values are converted to"`
+	HoldingsValue      float64    `json:"holdings_value"      jsonschema:"description:Value of all positions, excluding cash"`
+	Positions          int        `json:"positions"           jsonschema:"description:Number of positions, counting a symbol held in several accounts once"`
+	Largest            *Position  `json:"largest,omitempty"   jsonschema:"description:Largest position"`
+	Top                []Position `json:"top"                 jsonschema:"description:Largest positions, largest first"`
+	TopShare           float64    `json:"top_share"           jsonschema:"description:Combined share of the largest positions in percent"`
+	Herfindahl         float64    `json:"herfindahl"          jsonschema:"description:Herfindahl-Hirschman index of position shares, from 0 for many small positions to 10000 for a single position"`
+	EffectivePositions float64    `json:"effective_positions" jsonschema:"description:Number of equally sized positions with the same concentration, 10000 divided by the Herfindahl index"`
+	Sectors            []Exposure `json:"sectors,omitempty"   jsonschema:"description:Exposure per sector, when a classification is configured"`
+	Issuers            []Exposure `json:"issuers,omitempty"   jsonschema:"description:Exposure per issuer group, when a classification is configured"`
+	Currencies         []Exposure `json:"currencies"          jsonschema:"description:Exposure per currency including cash"`
+	Unconverted        []string   `json:"unconverted"         jsonschema:"description:Currencies without an exchange rate, left out of all metrics"`
+	Warnings           []string   `json:"warnings"            jsonschema:"description:Positions, sectors and issuers above the concentration limits"`
+}
+
+// Compute builds the report of balances
+func Compute(balances []portfolio.Balance, opts Options) Report {
+	opts.TopN = cmp.Or(opts.TopN, DefaultTopN)
+	opts.MaxPosition = cmp.Or(opts.MaxPosition, DefaultMaxPosition)
+	opts.MaxSector = cmp.Or(opts.MaxSector, DefaultMaxSector)
+
+	report := Report{Currency: opts.Currency}
+
+	var (
+		positions []*Position
+		total     float64
+	)
+
+	sectors := newExposures()
+	issuers := newExposures()
+	currencies := newExposures()
+
+	for _, b := range balances {
+		value, ok := convert(b, opts)
+		if !ok {
+			if !slices.Contains(report.Unconverted, b.UnitsCurrency) {
+				report.Unconverted = append(report.Unconverted, b.UnitsCurrency)
+			}
+
+			continue
+		}
+
+		total += value
+		currencies.add(b.UnitsCurrency, b.AssetSymbol, value)
+
+		if b.IsCash() {
+			continue
+		}
+
+		report.HoldingsValue += value
+
+		i := slices.IndexFunc(positions, func(p *Position) bool { return p.Symbol == b.AssetSymbol })
+		if i < 0 {
+			positions = append(positions, &Position{Symbol: b.AssetSymbol, Name: b.AssetName})
+			i = len(positions) - 1
+		}
+
+		p := positions[i]
+		p.Value += value
+
+		if !slices.Contains(p.Accounts, b.SourceAccount) {
+			p.Accounts = append(p.Accounts, b.SourceAccount)
+		}
+
+		if opts.Classify != nil {
+			sector, issuer, ok := opts.Classify(b)
+			if !ok {
+				sector, issuer = Unclassified, Unclassified
+			}
+
+			sectors.add(cmp.Or(sector, Unclassified), b.AssetSymbol, value)
+			issuers.add(cmp.Or(issuer, Unclassified), b.AssetSymbol, value)
+		}
+	}
+
+	slices.SortFunc(positions, func(a, b *Position) int {
+		return cmp.Or(cmp.Compare(b.Value, a.Value), cmp.Compare(a.Symbol, b.Symbol))
+	})
+
+	report.Positions = len(positions)
+
+	for _, p := range positions {
+		p.Share = share(p.Value, report.HoldingsValue)
+		slices.Sort(p.Accounts)
+
+		report.Herfindahl += p.Share * p.Share
+	}
+
+	if report.Herfindahl > 0 {
+		report.EffectivePositions = 10000 / report.Herfindahl
+	}
+
+	for i, p := range positions {
+		if i < opts.TopN {
+			report.Top = append(report.Top, *p)
+			report.TopShare += p.Share
+		}
+
+		if p.Share > opts.MaxPosition {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("%s is %.1f%% of holdings, above the %.0f%% position limit", p.Symbol, p.Share, opts.MaxPosition))
+		}
+	}
+
+	if len(report.Top) > 0 {
+		report.Largest = &report.Top[0]
+	}
+
+	if opts.Classify != nil {
+		report.Sectors = sectors.list(report.HoldingsValue)
+		report.Issuers = issuers.list(report.HoldingsValue)
+
+		for _, group := range []struct {
+			name      string
+			exposures []Exposure
+		}{{"sector", report.Sectors}, {"issuer", report.Issuers}} {
+			for _, e := range group.exposures {
+				if e.Key != Unclassified && e.Share > opts.MaxSector {
+					report.Warnings = append(report.Warnings, fmt.Sprintf("%s %s is %.1f%% of holdings, above the %.0f%% %s limit", group.name, e.Key, e.Share, opts.MaxSector, group.name))
+				}
+			}
+		}
+	}
+
+	report.Currencies = currencies.list(total)
+	slices.Sort(report.Unconverted)
+
+	return report
+}
+
+// convert returns the value of a balance in the report currency
+func convert(b portfolio.Balance, opts Options) (float64, bool) {
+	if strings.EqualFold(b.UnitsCurrency, opts.Currency) {
+		return b.UnitsValue, true
+	}
+
+	rate, ok := opts.Rates[strings.ToUpper(b.UnitsCurrency)]
+
+	return b.UnitsValue * rate, ok && rate > 0
+}
+
+func share(value, total float64) float64 {
+	if total == 0 {
+		return 0
+	}
+
+	return value * 100 / total
+}
+
+// exposures sums values per key, counting each symbol once
+type exposures struct {
+	values  map[string]float64
+	symbols map[string][]string
+}
+
+func newExposures() exposures {
+	return exposures{values: make(map[string]float64), symbols: make(map[string][]string)}
+}
+
+func (e exposures) add(key, symbol string, value float64) {
+	e.values[key] += value
+
+	if !slices.Contains(e.symbols[key], symbol) {
+		e.symbols[key] = append(e.symbols[key], symbol)
+	}
+}
+
+func (e exposures) list(total float64) []Exposure {
+	list := make([]Exposure, 0, len(e.values))
+
+	for key, value := range e.values {
+		list = append(list, Exposure{Key: key, Value: value, Share: share(value, total), Holdings: len(e.symbols[key])})
+	}
+
+	slices.SortFunc(list, func(a, b Exposure) int { return cmp.Or(cmp.Compare(b.Value, a.Value), cmp.Compare(a.Key, b.Key)) })
+
+	return list
+}
@@ -0,0 +1,73 @@
+package risk
+
+import (
+	"testing"
+
+	"github.com/chickenzord/portosync/internal/portfolio"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompute(t *testing.T) {
+	balances := []portfolio.Balance{
+		{SourceAccount: "personal", AssetSymbol: "BBCA", AssetName: "Bank Central Asia", AssetType: "equity", UnitsValue: 4000, UnitsCurrency: "IDR"},
+		{SourceAccount: "business", AssetSymbol: "BBCA", AssetName: "Bank Central Asia", AssetType: "equity", UnitsValue: 2000, UnitsCurrency: "IDR"},
+		{SourceAccount: "personal", AssetSymbol: "TLKM", AssetType: "equity", UnitsValue: 2000, UnitsCurrency: "IDR"},
+		{SourceAccount: "personal", AssetSymbol: "AAPL", AssetType: "equity", UnitsValue: 1, UnitsCurrency: "USD"},
+		{SourceAccount: "personal", AssetSymbol: "IDR", AssetType: portfolio.AssetTypeCash, UnitsValue: 2000, UnitsCurrency: "IDR"},
+		{SourceAccount: "personal", AssetSymbol: "SGX", AssetType: "equity", UnitsValue: 1, UnitsCurrency: "SGD"},
+	}
+
+	sectors := map[string][2]string{
+		"BBCA": {"Financials", "Djarum Group"},
+		"TLKM": {"Infrastructures", "Telkom Indonesia"},
+	}
+
+	report := Compute(balances, Options{
+		Currency: "IDR",
+		Rates:    map[string]float64{"USD": 2000},
+		TopN:     2,
+		Classify: func(b portfolio.Balance) (string, string, bool) {
+			class, ok := sectors[b.AssetSymbol]
+
+			return class[0], class[1], ok
+		},
+	})
+
+	assert.Equal(t, 10000.0, report.HoldingsValue)
+	assert.Equal(t, 3, report.Positions)
+	require.NotNil(t, report.Largest)
+	assert.Equal(t, Position{Symbol: "BBCA", Name: "Bank Central Asia", Value: 6000, Share: 60, Accounts: []string{"business", "personal"}}, *report.Largest)
+	assert.Len(t, report.Top, 2)
+	assert.Equal(t, 80.0, report.TopShare)
+	assert.InDelta(t, 60*60+20*20+20*20, report.Herfindahl, 1e-9)
+	assert.InDelta(t, 10000.0/4400, report.EffectivePositions, 1e-9)
+
+	assert.Equal(t, []Exposure{
+		{Key: "Financials", Value: 6000, Share: 60, Holdings: 1},
+		{Key: "Infrastructures", Value: 2000, Share: 20, Holdings: 1},
+		{Key: Unclassified, Value: 2000, Share: 20, Holdings: 1},
+	}, report.Sectors)
+	assert.Equal(t, "Djarum Group", report.Issuers[0].Key)
+
+	assert.Equal(t, []Exposure{
+		{Key: "IDR", Value: 10000, Share: 10000.0 / 12000 * 100, Holdings: 3},
+		{Key: "USD", Value: 2000, Share: 2000.0 / 12000 * 100, Holdings: 1},
+	}, report.Currencies)
+	assert.Equal(t, []string{"SGD"}, report.Unconverted)
+
+	assert.Equal(t, []string{
+		"BBCA is 60.0% of holdings, above the 20% position limit",
+		"sector Financials is 60.0% of holdings, above the 40% sector limit",
+		"issuer Djarum Group is 60.0% of holdings, above the 40% issuer limit",
+	}, report.Warnings)
+}
+
+func TestCompute_empty(t *testing.T) {
+	report := Compute(nil, Options{Currency: "IDR"})
+
+	assert.Nil(t, report.Largest)
+	assert.Zero(t, report.EffectivePositions)
+	assert.Empty(t, report.Sectors)
+	assert.Empty(t, report.Warnings)
+}
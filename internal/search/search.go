@@ -0,0 +1,290 @@
+// Package search resolves free-text queries to asset symbols using held assets and the mutual fund catalogue
+package search
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/chickenzord/goksei"
+	"github.com/chickenzord/portosync/internal/portfolio"
+)
+
+const (
+	// OriginHolding marks assets held in at least one configured account
+	OriginHolding = "holding"
+
+	// OriginCatalogue marks assets from the KSEI mutual fund catalogue
+	OriginCatalogue = "catalogue"
+
+	// DefaultLimit is the number of results returned when no limit is given
+	DefaultLimit = 10
+)
+
+// Match scores of a query against an asset, higher is better
+const (
+	scoreSymbolExact  = 100
+	scoreSymbolPrefix = 80
+	scoreNamePhrase   = 60
+	scoreAllTokens    = 50
+	scoreAcronym      = 45
+	scoreSymbolPart   = 40
+	scoreTypoTokens   = 30
+)
+
+// Asset is a search candidate and result
+type Asset struct {
+	Symbol            string   `json:"symbol"                       jsonschema:"description:Asset symbol or fund code as used in portfolio balances"`
+	Name              string   `json:"name"                         jsonschema:"description:Full asset or product name"`
+	AssetType         string   `json:"asset_type,omitempty"         jsonschema:"description:Asset type such as equity, bond, mutual_fund or cash"`
+	FundType          string   `json:"fund_type,omitempty"          jsonschema:"description:Mutual fund type such as money_market_fund, fixed_income_fund, mixed_asset_fund or equity_fund"`
+	InvestmentManager string   `json:"investment_manager,omitempty" jsonschema:"description:Investment manager of the mutual fund"`
+	Origin            string   `json:"origin"                       jsonschema:"description:holding when held in a configured account, catalogue when only found in the mutual fund catalogue"`
+	Accounts          []string `json:"accounts,omitempty"           jsonschema:"description:Accounts holding the asset"`
+	Score             int      `json:"score"                        jsonschema:"description:Match score, 100 for an exact symbol match and lower for weaker matches"`
+}
+
+// Holdings converts balances into candidates, merging the same symbol held in several accounts
+func Holdings(balances []portfolio.Balance) []Asset {
+	var assets []Asset
+
+	index := make(map[string]int)
+
+	for _, b := range balances {
+		key := b.AssetType + "/" + b.AssetSymbol
+
+		i, ok := index[key]
+		if !ok {
+			i = len(assets)
+			index[key] = i
+
+			assets = append(assets, Asset{
+				Symbol:    b.AssetSymbol,
+				Name:      b.AssetName,
+				AssetType: b.AssetType,
+				FundType:  b.AssetSubType,
+				Origin:    OriginHolding,
+			})
+
+			if fund, found := goksei.MutualFundByCode(b.AssetSymbol); found {
+				assets[i].InvestmentManager = fund.InvestmentManager
+			}
+		}
+
+		if !slices.Contains(assets[i].Accounts, b.SourceAccount) {
+			assets[i].Accounts = append(assets[i].Accounts, b.SourceAccount)
+			slices.Sort(assets[i].Accounts)
+		}
+	}
+
+	return assets
+}
+
+// Catalogue returns all mutual funds known to KSEI as candidates
+func Catalogue() []Asset {
+	funds := goksei.MutualFunds()
+	assets := make([]Asset, 0, len(funds))
+
+	for _, f := range funds {
+		assets = append(assets, Asset{
+			Symbol:            f.Code,
+			Name:              f.ProductName,
+			AssetType:         goksei.MutualFundType.Name(),
+			FundType:          f.FundType,
+			InvestmentManager: f.InvestmentManager,
+			Origin:            OriginCatalogue,
+		})
+	}
+
+	return assets
+}
+
+// Find returns candidates matching the query ordered by score, then holdings before catalogue entries,
+// then symbol. Catalogue entries with the same symbol as a holding are left out.
+func Find(query string, candidates []Asset, limit int) []Asset {
+	queryTokens := tokenize(query)
+	if len(queryTokens) == 0 {
+		return nil
+	}
+
+	held := make(map[string]bool)
+
+	for _, c := range candidates {
+		if c.Origin == OriginHolding {
+			held[c.Symbol] = true
+		}
+	}
+
+	var results []Asset
+
+	for _, c := range candidates {
+		if c.Origin == OriginCatalogue && held[c.Symbol] {
+			continue
+		}
+
+		if c.Score = score(queryTokens, c); c.Score > 0 {
+			results = append(results, c)
+		}
+	}
+
+	slices.SortFunc(results, func(a, b Asset) int {
+		return cmp.Or(
+			cmp.Compare(b.Score, a.Score),
+			cmp.Compare(originRank(a.Origin), originRank(b.Origin)),
+			cmp.Compare(a.Symbol, b.Symbol),
+		)
+	})
+
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+
+	if len(results) > limit {
+		results = results[:limit]
+	}
+
+	return results
+}
+
+func originRank(origin string) int {
+	if origin == OriginHolding {
+		return 0
+	}
+
+	return 1
+}
+
+// score matches query tokens against the symbol, then the name and investment manager
+func score(queryTokens []string, a Asset) int {
+	query := strings.Join(queryTokens, "")
+	symbol := strings.Join(tokenize(a.Symbol), "")
+
+	switch {
+	case symbol == query:
+		return scoreSymbolExact
+	case strings.HasPrefix(symbol, query):
+		return scoreSymbolPrefix
+	}
+
+	words := tokenize(a.Name + " " + a.InvestmentManager)
+	if containsPhrase(words, queryTokens) {
+		return scoreNamePhrase
+	}
+
+	if allTokensMatch(queryTokens, words, strings.HasPrefix) {
+		return scoreAllTokens
+	}
+
+	if len(query) >= 3 && acronym(tokenize(a.Name)) == query {
+		return scoreAcronym
+	}
+
+	if len(query) >= 3 && strings.Contains(symbol, query) {
+		return scoreSymbolPart
+	}
+
+	if allTokensMatch(queryTokens, words, similar) {
+		return scoreTypoTokens
+	}
+
+	return 0
+}
+
+// containsPhrase reports whether the query tokens appear consecutively in words,
+// the last one possibly as a prefix
+func containsPhrase(words, queryTokens []string) bool {
+	for i := 0; i+len(queryTokens) <= len(words); i++ {
+		matched := true
+
+		for j, q := range queryTokens {
+			w := words[i+j]
+			if w != q && (j < len(queryTokens)-1 || !strings.HasPrefix(w, q)) {
+				matched = false
+
+				break
+			}
+		}
+
+		if matched {
+			return true
+		}
+	}
+
+	return false
+}
+
+// acronym returns the initials of the words, skipping legal entity and fund prefixes
+// so that "PT Bank Central Asia Tbk" becomes "bca"
+func acronym(words []string) string {
+	var b strings.Builder
+
+	for _, w := range words {
+		switch w {
+		case "pt", "tbk", "persero", "reksa", "dana":
+			continue
+		}
+
+		b.WriteByte(w[0])
+	}
+
+	return b.String()
+}
+
+func allTokensMatch(queryTokens, words []string, match func(word, query string) bool) bool {
+	for _, q := range queryTokens {
+		if !slices.ContainsFunc(words, func(w string) bool { return match(w, q) }) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// similar reports whether a word matches a query token of at least 4 letters with one typo,
+// either as a whole word or as a prefix of it
+func similar(word, query string) bool {
+	if len(query) < 4 {
+		return false
+	}
+
+	if len(word) > len(query)+1 {
+		word = word[:len(query)+1]
+	}
+
+	return editDistance(word, query) <= 1 || editDistance(word[:min(len(word), len(query))], query) <= 1
+}
+
+// editDistance returns the Levenshtein distance between two strings
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+
+		prev, curr = curr, prev
+	}
+
+	return prev[len(b)]
+}
+
+// tokenize lowercases s and splits it into alphanumeric words
+func tokenize(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
@@ -0,0 +1,85 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/chickenzord/portosync/internal/portfolio"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHoldings(t *testing.T) {
+	assets := Holdings([]portfolio.Balance{
+		{SourceAccount: "personal", AssetSymbol: "BBCA", AssetName: "Bank Central Asia Tbk", AssetType: "equity"},
+		{SourceAccount: "business", AssetSymbol: "BBCA", AssetName: "Bank Central Asia Tbk", AssetType: "equity"},
+		{SourceAccount: "personal", AssetSymbol: "BBCA", AssetName: "Bank Central Asia Tbk", AssetType: "equity"},
+		{SourceAccount: "personal", AssetSymbol: "IDR", AssetName: "RDN BCA 123", AssetType: "cash"},
+	})
+
+	assert.Equal(t, []Asset{
+		{Symbol: "BBCA", Name: "Bank Central Asia Tbk", AssetType: "equity", Origin: OriginHolding, Accounts: []string{"business", "personal"}},
+		{Symbol: "IDR", Name: "RDN BCA 123", AssetType: "cash", Origin: OriginHolding, Accounts: []string{"personal"}},
+	}, assets)
+}
+
+func TestFind(t *testing.T) {
+	candidates := []Asset{
+		{Symbol: "BBCA", Name: "PT Bank Central Asia Tbk", AssetType: "equity", Origin: OriginHolding},
+		{Symbol: "BBRI", Name: "PT Bank Rakyat Indonesia (Persero) Tbk", AssetType: "equity", Origin: OriginHolding},
+		{Symbol: "SCMMF", Name: "Reksa Dana Sucorinvest Money Market Fund", InvestmentManager: "Sucorinvest Asset Management, PT", Origin: OriginHolding},
+		{Symbol: "SCMMF", Name: "Reksa Dana Sucorinvest Money Market Fund", Origin: OriginCatalogue},
+		{Symbol: "SCEQ", Name: "Reksa Dana Sucorinvest Equity Fund", InvestmentManager: "Sucorinvest Asset Management, PT", Origin: OriginCatalogue},
+		{Symbol: "BNIMMF", Name: "Reksa Dana BNI-AM Dana Likuid", InvestmentManager: "PT BNI Asset Management", Origin: OriginCatalogue},
+	}
+
+	tests := []struct {
+		name    string
+		query   string
+		symbols []string
+		score   int
+	}{
+		{name: "exact symbol", query: "bbca", symbols: []string{"BBCA"}, score: scoreSymbolExact},
+		{name: "symbol prefix", query: "BBR", symbols: []string{"BBRI"}, score: scoreSymbolPrefix},
+		{name: "name phrase", query: "bank rakyat", symbols: []string{"BBRI"}, score: scoreNamePhrase},
+		{name: "manager name ranks holdings first", query: "Sucorinvest", symbols: []string{"SCMMF", "SCEQ"}, score: scoreNamePhrase},
+		{name: "tokens in any order", query: "money sucorinvest", symbols: []string{"SCMMF"}, score: scoreAllTokens},
+		{name: "acronym", query: "BCA", symbols: []string{"BBCA"}, score: scoreAcronym},
+		{name: "typo", query: "sucorinvst equity", symbols: []string{"SCEQ"}, score: scoreTypoTokens},
+		{name: "no match", query: "mandiri", symbols: nil},
+		{name: "empty query", query: " - ", symbols: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := Find(tt.query, candidates, 0)
+
+			var symbols []string
+			for _, r := range results {
+				symbols = append(symbols, r.Symbol)
+			}
+
+			assert.Equal(t, tt.symbols, symbols)
+
+			if len(results) > 0 {
+				assert.Equal(t, tt.score, results[0].Score)
+			}
+		})
+	}
+}
+
+func TestFind_Limit(t *testing.T) {
+	results := Find("sucorinvest", Catalogue(), 3)
+	require.Len(t, results, 3)
+
+	for _, r := range results {
+		assert.Equal(t, OriginCatalogue, r.Origin)
+		assert.Equal(t, "mutual_fund", r.AssetType)
+		assert.NotEmpty(t, r.FundType)
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	assert.Equal(t, 0, editDistance("sucor", "sucor"))
+	assert.Equal(t, 1, editDistance("sucorinvest", "sucorinvst"))
+	assert.Equal(t, 3, editDistance("kitten", "sitting"))
+}
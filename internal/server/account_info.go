@@ -0,0 +1,136 @@
+package server
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/chickenzord/goksei"
+	"github.com/chickenzord/portosync/internal/portfolio"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"golang.org/x/sync/errgroup"
+)
+
+// getKseiIdentities retrieves KSEI investor identities of the clients in parallel
+func getKseiIdentities(clients map[string]*goksei.Client) (map[string]goksei.GlobalIdentity, error) {
+	var mu sync.Mutex
+
+	var errs errgroup.Group
+
+	identities := make(map[string]goksei.GlobalIdentity, len(clients))
+
+	for accountName, client := range clients {
+		errs.Go(func() error {
+			res, err := client.GetGlobalIdentity()
+			if err != nil {
+				return fmt.Errorf("%s: %w", accountName, err)
+			}
+
+			if len(res.Identities) == 0 {
+				return nil
+			}
+
+			mu.Lock()
+			identities[accountName] = res.Identities[0]
+			mu.Unlock()
+
+			return nil
+		})
+	}
+
+	if err := errs.Wait(); err != nil {
+		return nil, err
+	}
+
+	return identities, nil
+}
+
+// buildAccountInfos groups balances into per-account sub-account summaries
+func buildAccountInfos(names []string, balances []portfolio.Balance, identities map[string]goksei.GlobalIdentity) []AccountInfo {
+	infos := make([]AccountInfo, 0, len(names))
+
+	for _, name := range names {
+		info := AccountInfo{AccountName: name}
+
+		if identity, ok := identities[name]; ok {
+			info.InvestorID = identity.InvestorID
+			info.InvestorName = identity.InvestorName
+		}
+
+		type key struct{ subAccount, participant string }
+
+		grouped := make(map[key][]portfolio.Balance)
+
+		for _, b := range balances {
+			if b.SourceAccount != name {
+				continue
+			}
+
+			info.SourceType = b.SourceType
+			k := key{b.SubAccount, b.Participant}
+			grouped[k] = append(grouped[k], b)
+		}
+
+		for k, group := range grouped {
+			info.SubAccounts = append(info.SubAccounts, SubAccountInfo{
+				SubAccount:  k.subAccount,
+				Participant: k.participant,
+				Holdings:    len(group),
+				Totals:      portfolio.Totals(group),
+			})
+		}
+
+		slices.SortFunc(info.SubAccounts, func(a, b SubAccountInfo) int {
+			return cmp.Or(cmp.Compare(a.Participant, b.Participant), cmp.Compare(a.SubAccount, b.SubAccount))
+		})
+
+		infos = append(infos, info)
+	}
+
+	return infos
+}
+
+// handleGetAccountInfo handles the get_account_info MCP tool
+func (m *MCP) handleGetAccountInfo(ctx context.Context, req *mcp.CallToolRequest, args GetAccountInfoArgs) (*mcp.CallToolResult, GetAccountInfoResult, error) {
+	result := GetAccountInfoResult{}
+
+	accounts := m.selectAccounts(args.AccountNames)
+	if accounts.empty() {
+		return accountsNotFoundResult(m.getAccountNames()), result, nil
+	}
+
+	var errs errgroup.Group
+
+	var (
+		balances   []portfolio.Balance
+		identities map[string]goksei.GlobalIdentity
+	)
+
+	errs.Go(func() (err error) {
+		balances, err = m.fetchBalances(ctx, accounts)
+
+		return err
+	})
+
+	errs.Go(func() (err error) {
+		identities, err = getKseiIdentities(accounts.kseiClients)
+
+		return err
+	})
+
+	if err := errs.Wait(); err != nil {
+		return nil, result, err
+	}
+
+	result.Accounts = buildAccountInfos(accounts.names(), balances, identities)
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: result.Description(),
+			},
+		},
+	}, result, nil
+}
@@ -0,0 +1,49 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/chickenzord/goksei"
+	"github.com/chickenzord/portosync/internal/portfolio"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildAccountInfos(t *testing.T) {
+	balances := []portfolio.Balance{
+		{SourceType: "ksei", SourceAccount: "personal", AssetSymbol: "BBCA", UnitsValue: 1000, UnitsCurrency: "IDR", Participant: "MANDIRI SEKURITAS", SubAccount: "MS001"},
+		{SourceType: "ksei", SourceAccount: "personal", AssetSymbol: "TLKM", UnitsValue: 500, UnitsCurrency: "IDR", Participant: "MANDIRI SEKURITAS", SubAccount: "MS001"},
+		{SourceType: "ksei", SourceAccount: "personal", AssetSymbol: "IDR", AssetType: "cash", UnitsValue: 200, UnitsCurrency: "IDR", Participant: "PT Bank Jago Tbk", SubAccount: "123"},
+		{SourceType: "manual", SourceAccount: "vault", AssetSymbol: "GOLD", UnitsValue: 3000, UnitsCurrency: "IDR"},
+	}
+
+	identities := map[string]goksei.GlobalIdentity{
+		"personal": {InvestorID: "IDD123", InvestorName: "JOHN DOE", CitizenID: "3171000000000000"},
+	}
+
+	infos := buildAccountInfos([]string{"personal", "vault"}, balances, identities)
+
+	assert.Equal(t, []AccountInfo{
+		{
+			AccountName:  "personal",
+			SourceType:   "ksei",
+			InvestorID:   "IDD123",
+			InvestorName: "JOHN DOE",
+			SubAccounts: []SubAccountInfo{
+				{SubAccount: "MS001", Participant: "MANDIRI SEKURITAS", Holdings: 2, Totals: []portfolio.CurrencyTotal{{Currency: "IDR", SecuritiesValue: 1500, TotalValue: 1500}}},
+				{SubAccount: "123", Participant: "PT Bank Jago Tbk", Holdings: 1, Totals: []portfolio.CurrencyTotal{{Currency: "IDR", CashValue: 200, TotalValue: 200}}},
+			},
+		},
+		{
+			AccountName: "vault",
+			SourceType:  "manual",
+			SubAccounts: []SubAccountInfo{
+				{Holdings: 1, Totals: []portfolio.CurrencyTotal{{Currency: "IDR", SecuritiesValue: 3000, TotalValue: 3000}}},
+			},
+		},
+	}, infos)
+
+	description := GetAccountInfoResult{Accounts: infos}.Description()
+	assert.Contains(t, description, "Account personal (ksei), SID IDD123, investor JOHN DOE:")
+	assert.Contains(t, description, "- MANDIRI SEKURITAS MS001: 2 holdings, total value IDR 1500.000000")
+	assert.NotContains(t, description, "3171000000000000")
+}
@@ -0,0 +1,50 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/chickenzord/portosync/internal/alert"
+	"github.com/chickenzord/portosync/internal/portfolio"
+)
+
+// seedAlerts uses the latest stored snapshot before today as the previous fetch of alert rules,
+// so alerts are raised from the first background fetch after a restart
+func (m *MCP) seedAlerts() {
+	snapshot, err := m.previousSnapshot()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading snapshot for alerts: %v\n", err)
+
+		return
+	}
+
+	if snapshot != nil {
+		m.alerts.Seed(snapshot.Date, snapshot.Balances)
+	}
+}
+
+// evaluateAlerts evaluates alert rules against the result of a background fetch
+// and sends the triggered alerts, when alerting is configured
+func (m *MCP) evaluateAlerts(ctx context.Context, balances []portfolio.Balance, fetchErr error) {
+	if m.alerts == nil {
+		return
+	}
+
+	var alerts []alert.Alert
+	if fetchErr != nil {
+		alerts = m.alerts.Failed(time.Now(), fetchErr)
+	} else {
+		alerts = m.alerts.Fetched(time.Now(), balances)
+	}
+
+	if len(alerts) == 0 {
+		return
+	}
+
+	if err := m.alertSender.Send(ctx, alerts); err != nil {
+		// Undelivered alerts should not stop the fetcher
+		fmt.Fprintf(os.Stderr, "Error sending %d alerts: %v\n", len(alerts), err)
+	}
+}
@@ -0,0 +1,70 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/chickenzord/goksei"
+	"github.com/chickenzord/portosync/internal/alert"
+	"github.com/chickenzord/portosync/internal/portfolio"
+	"github.com/chickenzord/portosync/internal/snapshot"
+	"github.com/chickenzord/portosync/internal/source"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMCP_RunFetcher_Alerts(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "personal.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("holdings:\n  - {symbol: BBCA, type: equity, amount: 100, value: 900}\n"), 0o600))
+
+	store, err := snapshot.NewStore(filepath.Join(dir, "snapshots"))
+	require.NoError(t, err)
+	require.NoError(t, store.Save(portfolio.Snapshot{Date: portfolio.Today().AddDate(0, 0, -1), Balances: []portfolio.Balance{
+		{SourceAccount: "personal", AssetSymbol: "TLKM", AssetType: "equity", UnitsAmount: 100, UnitsValue: 1000, UnitsCurrency: "IDR"},
+	}}))
+
+	received := make(chan alert.Payload, 1)
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload alert.Payload
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		received <- payload
+	}))
+	defer webhook.Close()
+
+	mcpServer := &MCP{
+		kseiClients: make(map[string]*goksei.Client),
+		sources: map[string]source.Source{
+			"personal": source.NewManual(path),
+		},
+		snapshots:   store,
+		alerts:      alert.NewEvaluator(alert.DefaultRules()),
+		alertSender: alert.Webhook{URL: webhook.URL},
+	}
+	mcpServer.seedAlerts()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go mcpServer.RunFetcher(ctx, time.Hour)
+
+	select {
+	case payload := <-received:
+		require.Len(t, payload.Alerts, 3)
+		assert.Equal(t, alert.KindValueDrop, payload.Alerts[0].Kind)
+		assert.Equal(t, 1000.0, payload.Alerts[0].Previous)
+		assert.Equal(t, 900.0, payload.Alerts[0].Value)
+		assert.Equal(t, alert.KindNewAsset, payload.Alerts[1].Kind)
+		assert.Equal(t, "BBCA", payload.Alerts[1].Symbol)
+		assert.Equal(t, alert.KindHoldingGone, payload.Alerts[2].Kind)
+		assert.Equal(t, "TLKM", payload.Alerts[2].Symbol)
+	case <-time.After(10 * time.Second):
+		t.Fatal("no alerts received")
+	}
+}
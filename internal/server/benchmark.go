@@ -0,0 +1,88 @@
+package server
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/chickenzord/portosync/internal/benchmark"
+	"github.com/chickenzord/portosync/internal/performance"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// handleCompareToBenchmark handles the compare_to_benchmark MCP tool
+func (m *MCP) handleCompareToBenchmark(ctx context.Context, req *mcp.CallToolRequest, args CompareToBenchmarkArgs) (*mcp.CallToolResult, CompareToBenchmarkResult, error) {
+	result := CompareToBenchmarkResult{Currency: cmp.Or(args.Currency, "IDR")}
+
+	if m.snapshots == nil {
+		return errorResult(errSnapshotsDisabled.Error()), result, nil
+	}
+
+	if len(m.benchmarks) == 0 {
+		return errorResult("No benchmarks configured, set BENCHMARKS to name=path pairs of CSV files"), result, nil
+	}
+
+	names := args.Benchmarks
+	if len(names) == 0 {
+		names = slices.Sorted(maps.Keys(m.benchmarks))
+	}
+
+	for _, name := range names {
+		if _, ok := m.benchmarks[name]; !ok {
+			return errorResult(fmt.Sprintf("Benchmark %q not found. Available benchmarks: %s", name, strings.Join(slices.Sorted(maps.Keys(m.benchmarks)), ", "))), result, nil
+		}
+	}
+
+	from, to, err := parseDateRange(args.FromDate, args.ToDate)
+	if err != nil {
+		return errorResult(err.Error()), result, nil
+	}
+
+	accounts := m.selectAccounts(args.AccountNames)
+	if accounts.empty() {
+		return accountsNotFoundResult(m.getAccountNames()), result, nil
+	}
+
+	snapshots, err := m.snapshots.Range(from, to)
+	if err != nil {
+		return nil, result, err
+	}
+
+	if len(snapshots) < 2 {
+		return errorResult("At least two stored snapshots are needed in the date range to compare performance"), result, nil
+	}
+
+	transactions, err := m.getTransactions(to)
+	if err != nil {
+		return nil, result, err
+	}
+
+	index := performance.Index(snapshots, transactions, performanceScopes(snapshots, accounts.names())[0], result.Currency)
+
+	points := make([]benchmark.Point, len(index))
+	for i, p := range index {
+		points[i] = benchmark.Point{Date: p.Date, Value: p.Value}
+	}
+
+	for _, name := range names {
+		comparison, ok := benchmark.Compare(points, m.benchmarks[name])
+		if !ok {
+			result.Uncovered = append(result.Uncovered, name)
+
+			continue
+		}
+
+		result.Comparisons = append(result.Comparisons, comparison)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: result.Description(),
+			},
+		},
+	}, result, nil
+}
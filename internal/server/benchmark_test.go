@@ -0,0 +1,94 @@
+package server
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/chickenzord/goksei"
+	"github.com/chickenzord/portosync/internal/benchmark"
+	"github.com/chickenzord/portosync/internal/portfolio"
+	"github.com/chickenzord/portosync/internal/snapshot"
+	"github.com/chickenzord/portosync/internal/source"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMCP_handleCompareToBenchmark(t *testing.T) {
+	dir := t.TempDir()
+
+	store, err := snapshot.NewStore(filepath.Join(dir, "snapshots"))
+	require.NoError(t, err)
+
+	for _, s := range []portfolio.Snapshot{
+		{Date: mustDate(t, "2024-01-01"), Balances: []portfolio.Balance{
+			{SourceAccount: "personal", AssetSymbol: "BBCA", AssetType: "equity", UnitsAmount: 100, UnitsValue: 1000, UnitsCurrency: "IDR"},
+		}},
+		{Date: mustDate(t, "2024-01-02"), Balances: []portfolio.Balance{
+			{SourceAccount: "personal", AssetSymbol: "BBCA", AssetType: "equity", UnitsAmount: 100, UnitsValue: 1100, UnitsCurrency: "IDR"},
+		}},
+		{Date: mustDate(t, "2024-01-03"), Balances: []portfolio.Balance{
+			{SourceAccount: "personal", AssetSymbol: "BBCA", AssetType: "equity", UnitsAmount: 200, UnitsValue: 2310, UnitsCurrency: "IDR"},
+		}},
+	} {
+		require.NoError(t, store.Save(s))
+	}
+
+	mcpServer := &MCP{
+		kseiClients: make(map[string]*goksei.Client),
+		sources: map[string]source.Source{
+			"personal": source.NewManual(filepath.Join(dir, "personal.yaml")),
+		},
+		snapshots: store,
+		benchmarks: map[string]benchmark.Series{
+			"ihsg": {Name: "ihsg", Points: []benchmark.Point{
+				{Date: mustDate(t, "2024-01-01"), Value: 7000},
+				{Date: mustDate(t, "2024-01-03"), Value: 7140},
+			}},
+			"future": {Name: "future", Points: []benchmark.Point{
+				{Date: mustDate(t, "2025-01-01"), Value: 100},
+			}},
+		},
+	}
+
+	ctx := context.Background()
+	req := &mcp.CallToolRequest{}
+
+	result, data, err := mcpServer.handleCompareToBenchmark(ctx, req, CompareToBenchmarkArgs{})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+
+	assert.Equal(t, "IDR", data.Currency)
+	assert.Equal(t, []string{"future"}, data.Uncovered)
+	require.Len(t, data.Comparisons, 1)
+
+	c := data.Comparisons[0]
+	assert.Equal(t, "ihsg", c.Benchmark)
+	assert.Equal(t, "2024-01-01", c.From)
+	assert.Equal(t, "2024-01-03", c.To)
+	assert.InDelta(t, 15.5, c.PortfolioReturn, 1e-9, "10% then 5% excluding the inferred buy")
+	assert.InDelta(t, 2.0, c.BenchmarkReturn, 1e-9)
+	assert.InDelta(t, 13.5, c.Excess, 1e-9)
+	assert.Len(t, c.Points, 3)
+	assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, "outperformed by 13.50 percentage points")
+
+	for _, args := range []CompareToBenchmarkArgs{
+		{Benchmarks: []string{"unknown"}},
+		{FromDate: "2024-01-03"},
+		{AccountNames: []string{"unknown"}},
+	} {
+		result, _, err := mcpServer.handleCompareToBenchmark(ctx, req, args)
+		require.NoError(t, err)
+		assert.True(t, result.IsError, "%+v", args)
+	}
+
+	result, _, err = mcpServer.handleCompareToBenchmark(ctx, req, CompareToBenchmarkArgs{Benchmarks: []string{"lq45"}})
+	require.NoError(t, err)
+	assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, `Benchmark "lq45" not found. Available benchmarks: future, ihsg`)
+
+	mcpServer.benchmarks = nil
+	result, _, err = mcpServer.handleCompareToBenchmark(ctx, req, CompareToBenchmarkArgs{})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+}
@@ -0,0 +1,61 @@
+package server
+
+import (
+	"cmp"
+	"context"
+	"time"
+
+	"github.com/chickenzord/portosync/internal/bond"
+	"github.com/chickenzord/portosync/internal/portfolio"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// defaultMaturityWindow is the number of days upcoming_maturities looks ahead by default
+const defaultMaturityWindow = 365
+
+// bondCatalogue loads the configured bond catalogue, empty when none is configured
+func (m *MCP) bondCatalogue() (bond.Catalogue, error) {
+	if m.bondsFile == "" {
+		return bond.Catalogue{}, nil
+	}
+
+	return bond.Load(m.bondsFile)
+}
+
+// handleUpcomingMaturities handles the upcoming_maturities MCP tool
+func (m *MCP) handleUpcomingMaturities(ctx context.Context, req *mcp.CallToolRequest, args UpcomingMaturitiesArgs) (*mcp.CallToolResult, UpcomingMaturitiesResult, error) {
+	result := UpcomingMaturitiesResult{}
+
+	if args.WithinDays < 0 {
+		return errorResult("within_days must not be negative"), result, nil
+	}
+
+	accounts := m.selectAccounts(args.AccountNames)
+	if accounts.empty() {
+		return accountsNotFoundResult(m.getAccountNames()), result, nil
+	}
+
+	catalogue, err := m.bondCatalogue()
+	if err != nil {
+		return nil, result, err
+	}
+
+	balances, err := m.cachedBalances(ctx, accounts)
+	if err != nil {
+		return nil, result, err
+	}
+
+	today := portfolio.Today()
+	until := today.AddDate(0, 0, cmp.Or(args.WithinDays, defaultMaturityWindow))
+
+	result.Until = until.Format(time.DateOnly)
+	result.Maturities, result.Unknown = catalogue.Upcoming(balances, today, until)
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: result.Description(),
+			},
+		},
+	}, result, nil
+}
@@ -0,0 +1,82 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/chickenzord/goksei"
+	"github.com/chickenzord/portosync/internal/portfolio"
+	"github.com/chickenzord/portosync/internal/source"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMCP_handleUpcomingMaturities(t *testing.T) {
+	dir := t.TempDir()
+	holdings := filepath.Join(dir, "holdings.yaml")
+	bonds := filepath.Join(dir, "bonds.csv")
+
+	today := portfolio.Today()
+	soon := today.AddDate(0, 6, 0).Format(time.DateOnly)
+	later := today.AddDate(5, 0, 0).Format(time.DateOnly)
+
+	require.NoError(t, os.WriteFile(holdings, []byte(`holdings:
+  - {symbol: ORI023T3, type: bond, amount: 50000000, price: 1}
+  - {symbol: FR0098, type: bond, amount: 100000000, price: 1.04}
+  - {symbol: PBS999, type: bond, amount: 1000000, price: 1}
+  - {symbol: BBCA, type: equity, amount: 100, price: 10000}
+`), 0o600))
+	require.NoError(t, os.WriteFile(bonds, []byte(fmt.Sprintf(`symbol,name,coupon_rate,maturity_date,coupon_frequency
+ORI023T3,ORI023 Tenor 3,6.1,%s,12
+FR0098,Obligasi Negara FR0098,7.125,%s,2
+`, soon, later)), 0o600))
+
+	mcpServer := &MCP{
+		kseiClients: make(map[string]*goksei.Client),
+		sources: map[string]source.Source{
+			"vault": source.NewManual(holdings),
+		},
+		bondsFile: bonds,
+	}
+
+	ctx := context.Background()
+	req := &mcp.CallToolRequest{}
+
+	result, data, err := mcpServer.handleUpcomingMaturities(ctx, req, UpcomingMaturitiesArgs{})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	require.Len(t, data.Maturities, 1)
+	assert.Equal(t, "ORI023T3", data.Maturities[0].Symbol)
+	assert.Equal(t, soon, data.Maturities[0].MaturityDate)
+	assert.Equal(t, 50000000.0, data.Maturities[0].Principal)
+	assert.Equal(t, []string{"PBS999"}, data.Unknown)
+	assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, "Bonds missing from the bond catalogue (BONDS_FILE): PBS999")
+
+	_, data, err = mcpServer.handleUpcomingMaturities(ctx, req, UpcomingMaturitiesArgs{WithinDays: 3660})
+	require.NoError(t, err)
+	assert.Len(t, data.Maturities, 2)
+
+	// Bond balances of get_portfolio are enriched from the catalogue
+	_, portfolioData, err := mcpServer.handleGetPortfolio(ctx, req, GetPortfolioArgs{Symbols: []string{"FR0098"}})
+	require.NoError(t, err)
+	require.Len(t, portfolioData.Balances, 1)
+	assert.Equal(t, 7.125, portfolioData.Balances[0].CouponRate)
+	assert.Equal(t, later, portfolioData.Balances[0].MaturityDate)
+	assert.InDelta(t, 5.0, portfolioData.Balances[0].YearsToMaturity, 0.01)
+	assert.InDelta(t, 7.125/1.04, portfolioData.Balances[0].CurrentYield, 1e-9)
+
+	for _, args := range []UpcomingMaturitiesArgs{{WithinDays: -1}, {AccountNames: []string{"unknown"}}} {
+		result, _, err := mcpServer.handleUpcomingMaturities(ctx, req, args)
+		require.NoError(t, err)
+		assert.True(t, result.IsError, "%+v", args)
+	}
+
+	mcpServer.bondsFile = filepath.Join(dir, "missing.csv")
+	_, _, err = mcpServer.handleUpcomingMaturities(ctx, req, UpcomingMaturitiesArgs{})
+	assert.Error(t, err)
+}
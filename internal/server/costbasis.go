@@ -0,0 +1,125 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/chickenzord/portosync/internal/costbasis"
+	"github.com/chickenzord/portosync/internal/transaction"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+var errCostBasisDisabled = errors.New("cost basis entries require a data directory, configure DATA_DIR")
+
+// costBook combines cost basis computed from the transactions file with stored entries,
+// stored entries taking precedence unless transactions of the holding were dated after them
+func (m *MCP) costBook() (costbasis.Book, error) {
+	var fromTransactions, stored []costbasis.Entry
+
+	if m.transactionsFile != "" {
+		transactions, err := transaction.Load(m.transactionsFile)
+		if err != nil {
+			return costbasis.Book{}, err
+		}
+
+		fromTransactions = costbasis.FromTransactions(transactions)
+	}
+
+	if m.costBasis != nil {
+		var err error
+		if stored, err = m.costBasis.Entries(); err != nil {
+			return costbasis.Book{}, err
+		}
+	}
+
+	return costbasis.Merge(fromTransactions, stored), nil
+}
+
+// handleSetCostBasis handles the set_cost_basis MCP tool
+func (m *MCP) handleSetCostBasis(ctx context.Context, req *mcp.CallToolRequest, args SetCostBasisArgs) (*mcp.CallToolResult, SetCostBasisResult, error) {
+	result := SetCostBasisResult{}
+
+	if m.costBasis == nil {
+		return errorResult(errCostBasisDisabled.Error()), result, nil
+	}
+
+	accounts := m.selectAccounts([]string{args.AccountName})
+	if accounts.empty() {
+		return accountsNotFoundResult(m.getAccountNames()), result, nil
+	}
+
+	if args.Symbol == "" {
+		return errorResult("symbol is required"), result, nil
+	}
+
+	if args.Remove {
+		removed, err := m.costBasis.Delete(args.AccountName, args.Symbol)
+		if err != nil {
+			return nil, result, err
+		}
+
+		result.Removed = removed
+
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: result.Description(),
+				},
+			},
+		}, result, nil
+	}
+
+	entry := costbasis.Entry{
+		Account:   args.AccountName,
+		Symbol:    strings.ToUpper(args.Symbol),
+		UpdatedAt: time.Now(),
+	}
+
+	switch {
+	case args.AveragePrice != nil && args.TotalCost != nil:
+		return errorResult("Pass either average_price or total_cost, not both"), result, nil
+	case args.AveragePrice != nil:
+		entry.AveragePrice = *args.AveragePrice
+	case args.TotalCost != nil:
+		// Spread the total cost over the units currently held
+		balances, err := m.fetchBalances(ctx, accounts)
+		if err != nil {
+			return nil, result, err
+		}
+
+		for _, b := range balances {
+			if !b.IsCash() && strings.EqualFold(b.AssetSymbol, entry.Symbol) {
+				entry.Units += b.UnitsAmount
+			}
+		}
+
+		if entry.Units == 0 {
+			return errorResult(fmt.Sprintf("%s is not held in account %s, pass average_price instead", args.Symbol, args.AccountName)), result, nil
+		}
+
+		entry.AveragePrice = *args.TotalCost / entry.Units
+	default:
+		return errorResult("Pass either average_price or total_cost"), result, nil
+	}
+
+	if entry.AveragePrice < 0 {
+		return errorResult("Cost basis cannot be negative"), result, nil
+	}
+
+	if err := m.costBasis.Set(entry); err != nil {
+		return nil, result, err
+	}
+
+	result.Entry = &entry
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: result.Description(),
+			},
+		},
+	}, result, nil
+}
@@ -0,0 +1,103 @@
+package server
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/chickenzord/goksei"
+	"github.com/chickenzord/portosync/internal/costbasis"
+	"github.com/chickenzord/portosync/internal/portfolio"
+	"github.com/chickenzord/portosync/internal/source"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMCP_handleSetCostBasis(t *testing.T) {
+	dir := t.TempDir()
+	holdings := filepath.Join(dir, "holdings.yaml")
+	transactions := filepath.Join(dir, "transactions.csv")
+
+	require.NoError(t, os.WriteFile(holdings, []byte(`holdings:
+  - {symbol: BBCA, type: equity, amount: 200, price: 10000}
+  - {symbol: tlkm, type: equity, amount: 100, price: 3000}
+`), 0o600))
+	require.NoError(t, os.WriteFile(transactions, []byte(`date,account,symbol,type,units,price
+2024-01-15,vault,BBCA,buy,200,8000
+2024-01-15,vault,TLKM,buy,100,4000
+`), 0o600))
+
+	mcpServer := &MCP{
+		kseiClients: make(map[string]*goksei.Client),
+		sources: map[string]source.Source{
+			"vault": source.NewManual(holdings),
+		},
+		costBasis:        costbasis.NewStore(filepath.Join(dir, "cost_basis.json")),
+		transactionsFile: transactions,
+	}
+
+	ctx := context.Background()
+	req := &mcp.CallToolRequest{}
+
+	// Cost basis computed from the transactions file
+	_, portfolioData, err := mcpServer.handleGetPortfolio(ctx, req, GetPortfolioArgs{SortBy: "symbol"})
+	require.NoError(t, err)
+	require.Len(t, portfolioData.Balances, 2)
+	assert.Equal(t, 1600000.0, portfolioData.Balances[0].CostBasis)
+	assert.Equal(t, 25.0, portfolioData.Balances[0].UnrealizedGainPercent)
+	require.NotNil(t, portfolioData.Balances[1].UnrealizedGain)
+	assert.Equal(t, -100000.0, *portfolioData.Balances[1].UnrealizedGain)
+
+	// Entered total cost overrides the transactions file
+	totalCost := 1800000.0
+	result, data, err := mcpServer.handleSetCostBasis(ctx, req, SetCostBasisArgs{AccountName: "vault", Symbol: "bbca", TotalCost: &totalCost})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	require.NotNil(t, data.Entry)
+	assert.Equal(t, 9000.0, data.Entry.AveragePrice)
+	assert.Equal(t, 200.0, data.Entry.Units)
+
+	_, portfolioData, err = mcpServer.handleGetPortfolio(ctx, req, GetPortfolioArgs{SortBy: "symbol"})
+	require.NoError(t, err)
+	assert.Equal(t, 1800000.0, portfolioData.Balances[0].CostBasis)
+	assert.Equal(t, []portfolio.AccountGain{
+		{Account: "vault", Currency: "IDR", CostBasis: 2200000, Value: 2300000, UnrealizedGain: 100000, UnrealizedGainPercent: 100000 * 100 / 2200000.0, Holdings: 2},
+	}, portfolioData.Gains)
+
+	// Removing the entry falls back to the transactions file
+	_, data, err = mcpServer.handleSetCostBasis(ctx, req, SetCostBasisArgs{AccountName: "vault", Symbol: "BBCA", Remove: true})
+	require.NoError(t, err)
+	assert.True(t, data.Removed)
+
+	_, portfolioData, err = mcpServer.handleGetPortfolio(ctx, req, GetPortfolioArgs{SortBy: "symbol"})
+	require.NoError(t, err)
+	assert.Equal(t, 1600000.0, portfolioData.Balances[0].CostBasis)
+
+	// Symbols of manual holdings match regardless of case
+	tlkmCost := 350000.0
+	result, data, err = mcpServer.handleSetCostBasis(ctx, req, SetCostBasisArgs{AccountName: "vault", Symbol: "TLKM", TotalCost: &tlkmCost})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	assert.Equal(t, 3500.0, data.Entry.AveragePrice)
+
+	averagePrice := 5000.0
+	invalid := []SetCostBasisArgs{
+		{AccountName: "unknown", Symbol: "BBCA", AveragePrice: &averagePrice},
+		{AccountName: "vault", Symbol: "BBCA"},
+		{AccountName: "vault", Symbol: "BBCA", AveragePrice: &averagePrice, TotalCost: &totalCost},
+		{AccountName: "vault", Symbol: "GOTO", TotalCost: &totalCost},
+	}
+
+	for _, args := range invalid {
+		result, _, err := mcpServer.handleSetCostBasis(ctx, req, args)
+		require.NoError(t, err)
+		assert.True(t, result.IsError, "%+v", args)
+	}
+
+	mcpServer.costBasis = nil
+	result, _, err = mcpServer.handleSetCostBasis(ctx, req, SetCostBasisArgs{AccountName: "vault", Symbol: "BBCA", AveragePrice: &averagePrice})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+}
@@ -0,0 +1,112 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/chickenzord/portosync/internal/digest"
+	"github.com/chickenzord/portosync/internal/notify"
+	"github.com/chickenzord/portosync/internal/portfolio"
+)
+
+// failureLog keeps messages of failed fetches until they are reported by the next digest
+type failureLog struct {
+	mu       sync.Mutex
+	messages []string
+}
+
+// add records a failed fetch at the time
+func (l *failureLog) add(at time.Time, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.messages = append(l.messages, fmt.Sprintf("%s: %v", at.Format("2006-01-02 15:04"), err))
+}
+
+// drain returns recorded messages and clears them
+func (l *failureLog) drain() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	messages := l.messages
+	l.messages = nil
+
+	return messages
+}
+
+// nextDigest returns the first time after now at the time of day, given as a duration since midnight
+func nextDigest(now time.Time, at time.Duration) time.Time {
+	year, month, day := now.Date()
+
+	next := time.Date(year, month, day, 0, 0, 0, 0, now.Location()).Add(at)
+	if !next.After(now) {
+		next = time.Date(year, month, day+1, 0, 0, 0, 0, now.Location()).Add(at)
+	}
+
+	return next
+}
+
+// RunDigest sends a daily digest through the configured notifiers at the time of day,
+// given as a duration since local midnight, until ctx is cancelled
+func (m *MCP) RunDigest(ctx context.Context, at time.Duration) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Until(nextDigest(time.Now(), at))):
+		}
+
+		if err := m.sendDigest(ctx, time.Now()); err != nil {
+			fmt.Fprintf(os.Stderr, "Error sending digest: %v\n", err)
+		}
+	}
+}
+
+// sendDigest fetches balances of all accounts and sends a digest comparing them with the
+// previous day, reporting fetches that failed since the last digest. Latest known balances
+// are used when the fetch fails.
+func (m *MCP) sendDigest(ctx context.Context, now time.Time) error {
+	accounts := m.selectAccounts(nil)
+
+	balances, err := m.fetchBalances(ctx, accounts)
+	if err != nil {
+		m.failures.add(now, err)
+
+		balances, _ = m.latest.get(accounts.names())
+	}
+
+	previous := m.lastDigest
+
+	if snapshot, err := m.previousSnapshot(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading snapshot for digest: %v\n", err)
+	} else if snapshot != nil {
+		previous = snapshot.Balances
+	}
+
+	if balances != nil {
+		m.lastDigest = balances
+	}
+
+	d := digest.Compose(now, balances, previous, m.failures.drain(), m.digestTopMovers)
+
+	return notify.All(ctx, m.notifiers, d.Message())
+}
+
+// previousSnapshot returns the latest stored snapshot before today,
+// or nil when there is none or snapshots are disabled
+func (m *MCP) previousSnapshot() (*portfolio.Snapshot, error) {
+	date := m.previousSnapshotDate()
+	if date == "" {
+		return nil, nil
+	}
+
+	d, err := portfolio.ParseDate(date)
+	if err != nil {
+		return nil, nil
+	}
+
+	return m.snapshots.Get(d)
+}
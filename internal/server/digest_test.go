@@ -0,0 +1,85 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/chickenzord/goksei"
+	"github.com/chickenzord/portosync/internal/notify"
+	"github.com/chickenzord/portosync/internal/portfolio"
+	"github.com/chickenzord/portosync/internal/snapshot"
+	"github.com/chickenzord/portosync/internal/source"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNextDigest(t *testing.T) {
+	at := 8*time.Hour + 30*time.Minute
+	now := time.Date(2024, 1, 31, 7, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, time.Date(2024, 1, 31, 8, 30, 0, 0, time.UTC), nextDigest(now, at))
+	assert.Equal(t, time.Date(2024, 2, 1, 8, 30, 0, 0, time.UTC), nextDigest(now.Add(90*time.Minute), at), "the time has just passed")
+	assert.Equal(t, time.Date(2024, 2, 1, 8, 30, 0, 0, time.UTC), nextDigest(now.Add(3*time.Hour), at))
+}
+
+func TestMCP_sendDigest(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "personal.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("holdings:\n  - {symbol: BBCA, type: equity, amount: 100, value: 1100}\n  - {symbol: TLKM, type: equity, amount: 100, value: 400}\n"), 0o600))
+
+	store, err := snapshot.NewStore(filepath.Join(dir, "snapshots"))
+	require.NoError(t, err)
+	require.NoError(t, store.Save(portfolio.Snapshot{Date: portfolio.Today().AddDate(0, 0, -1), Balances: []portfolio.Balance{
+		{SourceAccount: "personal", AssetSymbol: "BBCA", AssetType: "equity", UnitsAmount: 100, UnitsValue: 1000, UnitsCurrency: "IDR"},
+		{SourceAccount: "personal", AssetSymbol: "TLKM", AssetType: "equity", UnitsAmount: 100, UnitsValue: 500, UnitsCurrency: "IDR"},
+	}}))
+
+	var slackBodies, telegramBodies []map[string]string
+
+	fake := func(bodies *[]map[string]string) *httptest.Server {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var body map[string]string
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			*bodies = append(*bodies, body)
+		}))
+		t.Cleanup(server.Close)
+
+		return server
+	}
+	slack, telegram := fake(&slackBodies), fake(&telegramBodies)
+
+	mcpServer := &MCP{
+		kseiClients: make(map[string]*goksei.Client),
+		sources: map[string]source.Source{
+			"personal": source.NewManual(path),
+		},
+		snapshots: store,
+		notifiers: []notify.Notifier{
+			notify.Slack{URL: slack.URL},
+			notify.Telegram{URL: telegram.URL, Token: "123:abc", ChatID: "42"},
+		},
+		digestTopMovers: 1,
+	}
+	mcpServer.failures.add(time.Date(2024, 1, 31, 7, 0, 0, 0, time.Local), errors.New("login failed"))
+
+	require.NoError(t, mcpServer.sendDigest(context.Background(), time.Now()))
+
+	text := "Total value:\n- IDR 1500.00 (+0.00, +0.00%)\n\nTop movers:\n- BBCA (personal) IDR +100.00 (+10.00%)\n\nFailures:\n- 2024-01-31 07:00: login failed"
+	title := "Portfolio digest " + portfolio.Today().Format(time.DateOnly)
+
+	require.Len(t, slackBodies, 1)
+	assert.Equal(t, "*"+title+"*\n"+text, slackBodies[0]["text"])
+	require.Len(t, telegramBodies, 1)
+	assert.Equal(t, "<b>"+title+"</b>\n"+text, telegramBodies[0]["text"])
+	assert.Equal(t, "42", telegramBodies[0]["chat_id"])
+
+	require.NoError(t, mcpServer.sendDigest(context.Background(), time.Now()))
+	assert.NotContains(t, slackBodies[1]["text"], "Failures", "failures are reported once")
+}
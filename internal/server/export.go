@@ -0,0 +1,107 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/chickenzord/portosync/internal/export"
+	"github.com/chickenzord/portosync/internal/portfolio"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+var errSnapshotsDisabled = errors.New("snapshot history is not available, configure DATA_DIR to record daily snapshots")
+
+// ExportOptions selects which balances to export and how
+type ExportOptions struct {
+	Format       export.Format
+	AccountNames []string
+
+	// From and To select a stored snapshot date range (inclusive),
+	// leave both zero to export live balances instead
+	From time.Time
+	To   time.Time
+}
+
+// Live reports whether the export reads live balances instead of stored snapshots
+func (o ExportOptions) Live() bool {
+	return o.From.IsZero() && o.To.IsZero()
+}
+
+// Export writes balances selected by opts to w
+func (m *MCP) Export(ctx context.Context, w io.Writer, opts ExportOptions) error {
+	snapshots, err := m.exportSnapshots(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	return export.Write(w, opts.Format, snapshots, m.exportOpts)
+}
+
+func (m *MCP) exportSnapshots(ctx context.Context, opts ExportOptions) ([]portfolio.Snapshot, error) {
+	if opts.Live() {
+		accounts := m.selectAccounts(opts.AccountNames)
+		if accounts.empty() {
+			return nil, fmt.Errorf("selected accounts not found, available accounts are %s", strings.Join(m.getAccountNames(), ", "))
+		}
+
+		balances, err := m.fetchBalances(ctx, accounts)
+		if err != nil {
+			return nil, err
+		}
+
+		return []portfolio.Snapshot{{Date: portfolio.Today(), Balances: balances}}, nil
+	}
+
+	if m.snapshots == nil {
+		return nil, errSnapshotsDisabled
+	}
+
+	snapshots, err := m.snapshots.Range(opts.From, opts.To)
+	if err != nil {
+		return nil, err
+	}
+
+	// Stored snapshots may contain accounts that are no longer configured,
+	// so names are matched against the snapshot contents instead of the clients
+	if len(opts.AccountNames) > 0 {
+		for i := range snapshots {
+			snapshots[i].Balances = slices.DeleteFunc(snapshots[i].Balances, func(b portfolio.Balance) bool {
+				return !slices.Contains(opts.AccountNames, b.SourceAccount)
+			})
+		}
+	}
+
+	return snapshots, nil
+}
+
+// handleExportPortfolio handles the export_portfolio MCP tool
+func (m *MCP) handleExportPortfolio(ctx context.Context, req *mcp.CallToolRequest, args ExportPortfolioArgs) (*mcp.CallToolResult, ExportPortfolioResult, error) {
+	result := ExportPortfolioResult{}
+
+	opts, err := args.options()
+	if err != nil {
+		return errorResult(err.Error()), result, nil
+	}
+
+	var buf bytes.Buffer
+	if err := m.Export(ctx, &buf, opts); err != nil {
+		return errorResult("Export failed: " + err.Error()), result, nil
+	}
+
+	result.Format = string(opts.Format)
+	result.Content = buf.String()
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: result.Content,
+			},
+		},
+	}, result, nil
+}
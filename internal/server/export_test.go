@@ -0,0 +1,64 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/chickenzord/portosync/internal/export"
+	"github.com/chickenzord/portosync/internal/portfolio"
+	"github.com/chickenzord/portosync/internal/snapshot"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMCP_Export_Snapshots(t *testing.T) {
+	store, err := snapshot.NewStore(t.TempDir())
+	require.NoError(t, err)
+
+	for _, d := range []string{"2026-01-01", "2026-01-02"} {
+		date, _ := portfolio.ParseDate(d)
+		require.NoError(t, store.Save(portfolio.Snapshot{
+			Date: date,
+			Balances: []portfolio.Balance{
+				{SourceType: "ksei", SourceAccount: "personal", AssetSymbol: "BBCA", UnitsAmount: 100, UnitsValue: 1000, UnitsCurrency: "IDR"},
+				{SourceType: "ksei", SourceAccount: "business", AssetSymbol: "TLKM", UnitsAmount: 200, UnitsValue: 2000, UnitsCurrency: "IDR"},
+			},
+		}))
+	}
+
+	m := &MCP{snapshots: store}
+
+	from, _ := portfolio.ParseDate("2026-01-02")
+
+	var buf bytes.Buffer
+	require.NoError(t, m.Export(context.Background(), &buf, ExportOptions{
+		Format:       export.FormatCSV,
+		AccountNames: []string{"personal"},
+		From:         from,
+	}))
+
+	assert.Equal(t,
+		"date,source_type,source_account,asset_symbol,asset_name,asset_type,asset_sub_type,units_amount,units_value,units_currency,participant,sub_account\n"+
+			"2026-01-02,ksei,personal,BBCA,,,,100,1000,IDR,,\n",
+		buf.String())
+}
+
+func TestMCP_handleExportPortfolio_Errors(t *testing.T) {
+	m := &MCP{}
+
+	result, _, err := m.handleExportPortfolio(context.Background(), &mcp.CallToolRequest{}, ExportPortfolioArgs{
+		Format:   "csv",
+		FromDate: "2026-01-01",
+	})
+	assert.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, "DATA_DIR")
+
+	result, _, err = m.handleExportPortfolio(context.Background(), &mcp.CallToolRequest{}, ExportPortfolioArgs{
+		Format: "xlsx",
+	})
+	assert.NoError(t, err)
+	assert.True(t, result.IsError)
+}
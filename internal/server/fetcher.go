@@ -0,0 +1,128 @@
+package server
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"reflect"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/chickenzord/portosync/internal/portfolio"
+)
+
+// balanceCache keeps the latest fetched balances of each account
+type balanceCache struct {
+	mu       sync.RWMutex
+	balances map[string][]portfolio.Balance
+}
+
+// update replaces cached balances of the accounts and returns names of accounts whose balances changed
+func (c *balanceCache) update(accounts []string, balances []portfolio.Balance) []string {
+	byAccount := make(map[string][]portfolio.Balance, len(accounts))
+	for _, b := range balances {
+		byAccount[b.SourceAccount] = append(byAccount[b.SourceAccount], b)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.balances == nil {
+		c.balances = make(map[string][]portfolio.Balance)
+	}
+
+	var changed []string
+
+	for _, name := range accounts {
+		current := sortBalances(byAccount[name])
+
+		previous, ok := c.balances[name]
+		if !ok || !reflect.DeepEqual(previous, current) {
+			changed = append(changed, name)
+		}
+
+		c.balances[name] = current
+	}
+
+	return changed
+}
+
+// get returns cached balances of the accounts and the accounts without cached balances
+func (c *balanceCache) get(accounts []string) (balances []portfolio.Balance, missing []string) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for _, name := range accounts {
+		cached, ok := c.balances[name]
+		if !ok {
+			missing = append(missing, name)
+
+			continue
+		}
+
+		balances = append(balances, cached...)
+	}
+
+	return balances, missing
+}
+
+// sortBalances returns balances in a deterministic order so fetches can be compared
+func sortBalances(balances []portfolio.Balance) []portfolio.Balance {
+	sorted := slices.Clone(balances)
+	slices.SortFunc(sorted, func(a, b portfolio.Balance) int {
+		return cmp.Or(
+			cmp.Compare(a.SourceAccount, b.SourceAccount),
+			cmp.Compare(a.AssetType, b.AssetType),
+			cmp.Compare(a.AssetSymbol, b.AssetSymbol),
+			cmp.Compare(a.SubAccount, b.SubAccount),
+		)
+	})
+
+	return sorted
+}
+
+// cachedBalances returns the latest known balances of the accounts,
+// fetching live balances only for accounts that were never fetched
+func (m *MCP) cachedBalances(ctx context.Context, accounts accountSet) ([]portfolio.Balance, error) {
+	balances, missing := m.latest.get(accounts.names())
+	if len(missing) == 0 {
+		return balances, nil
+	}
+
+	fetched, err := m.fetchBalances(ctx, m.selectAccounts(missing))
+	if err != nil {
+		return nil, err
+	}
+
+	return append(balances, fetched...), nil
+}
+
+// RunFetcher fetches balances of all accounts in the background every interval,
+// with up to 10% random jitter, until ctx is cancelled. Alert rules are evaluated after every fetch
+// and failures are kept for the next digest.
+func (m *MCP) RunFetcher(ctx context.Context, interval time.Duration) {
+	for {
+		balances, err := m.fetchBalances(ctx, m.selectAccounts(nil))
+		if err != nil {
+			// Log to stderr because stdout reserved for MCP protocol communication
+			fmt.Fprintf(os.Stderr, "Error fetching balances in background: %v\n", err)
+			m.failures.add(time.Now(), err)
+		}
+
+		m.evaluateAlerts(ctx, balances, err)
+
+		wait := interval
+		if jitter := int64(interval / 10); jitter > 0 {
+			wait += time.Duration(rand.Int64N(jitter))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+	}
+}
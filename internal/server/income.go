@@ -0,0 +1,179 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/chickenzord/portosync/internal/income"
+	"github.com/chickenzord/portosync/internal/portfolio"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+var errIncomeDisabled = errors.New("income entries require a data directory, configure DATA_DIR")
+
+// incomeEntries combines entries of the income file with recorded entries ordered by date,
+// recorded entries replacing file entries with the same key
+func (m *MCP) incomeEntries() ([]income.Entry, error) {
+	var fromFile, recorded []income.Entry
+
+	if m.incomeFile != "" {
+		var err error
+		if fromFile, err = income.Load(m.incomeFile); err != nil {
+			return nil, err
+		}
+	}
+
+	if m.income != nil {
+		var err error
+		if recorded, err = m.income.Entries(); err != nil {
+			return nil, err
+		}
+	}
+
+	entries := slices.DeleteFunc(fromFile, func(f income.Entry) bool {
+		return slices.ContainsFunc(recorded, func(r income.Entry) bool { return r.Key() == f.Key() })
+	})
+	entries = append(entries, recorded...)
+	income.Sort(entries)
+
+	return entries, nil
+}
+
+// handleRecordIncome handles the record_income MCP tool
+func (m *MCP) handleRecordIncome(ctx context.Context, req *mcp.CallToolRequest, args RecordIncomeArgs) (*mcp.CallToolResult, RecordIncomeResult, error) {
+	result := RecordIncomeResult{}
+
+	if m.income == nil {
+		return errorResult(errIncomeDisabled.Error()), result, nil
+	}
+
+	if m.selectAccounts([]string{args.AccountName}).empty() {
+		return accountsNotFoundResult(m.getAccountNames()), result, nil
+	}
+
+	date, err := portfolio.ParseDate(args.Date)
+	if err != nil {
+		return errorResult("Invalid date, expected YYYY-MM-DD"), result, nil
+	}
+
+	entry := income.Entry{
+		Date:     date,
+		Account:  args.AccountName,
+		Symbol:   args.Symbol,
+		Type:     args.Type,
+		Amount:   args.Amount,
+		Tax:      args.Tax,
+		Currency: args.Currency,
+		Note:     args.Note,
+	}
+
+	if args.Remove {
+		// Only the key is needed to remove, normalize it without validating amounts
+		entry.Symbol = strings.ToUpper(entry.Symbol)
+		entry.Type = strings.ToLower(entry.Type)
+
+		if result.Removed, err = m.income.Delete(entry); err != nil {
+			return nil, result, err
+		}
+
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: result.Description(),
+				},
+			},
+		}, result, nil
+	}
+
+	if err := entry.Validate(); err != nil {
+		return errorResult(err.Error()), result, nil
+	}
+
+	if err := m.income.Set(entry); err != nil {
+		return nil, result, err
+	}
+
+	entry.Origin = income.OriginManual
+	result.Entry = &entry
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: result.Description(),
+			},
+		},
+	}, result, nil
+}
+
+// handleGetIncome handles the get_income MCP tool
+func (m *MCP) handleGetIncome(ctx context.Context, req *mcp.CallToolRequest, args GetIncomeArgs) (*mcp.CallToolResult, GetIncomeResult, error) {
+	result := GetIncomeResult{}
+
+	from, to, err := parseDateRange(args.FromDate, args.ToDate)
+	if err != nil {
+		return errorResult(err.Error()), result, nil
+	}
+
+	today := portfolio.Today()
+
+	if from.IsZero() {
+		from = today.AddDate(-1, 0, 1)
+	}
+
+	if to.IsZero() {
+		to = today.AddDate(1, 0, 0)
+	}
+
+	result.From = from.Format(time.DateOnly)
+	result.To = to.Format(time.DateOnly)
+
+	accounts := m.selectAccounts(args.AccountNames)
+	if accounts.empty() {
+		return accountsNotFoundResult(m.getAccountNames()), result, nil
+	}
+
+	entries, err := m.incomeEntries()
+	if err != nil {
+		return nil, result, err
+	}
+
+	names := accounts.names()
+	entries = slices.DeleteFunc(entries, func(e income.Entry) bool {
+		return !slices.Contains(names, e.Account) ||
+			(len(args.Symbols) > 0 && !slices.ContainsFunc(args.Symbols, func(s string) bool { return strings.EqualFold(s, e.Symbol) }))
+	})
+
+	if !args.ExcludeProjected && len(entries) > 0 {
+		balances, err := m.cachedBalances(ctx, accounts)
+		if err != nil {
+			return nil, result, err
+		}
+
+		held := func(account, symbol string) bool {
+			return slices.ContainsFunc(balances, func(b portfolio.Balance) bool {
+				return b.SourceAccount == account && strings.EqualFold(b.AssetSymbol, symbol) && b.UnitsAmount > 0
+			})
+		}
+
+		entries = append(entries, income.Project(entries, today, held)...)
+		income.Sort(entries)
+	}
+
+	entries = slices.DeleteFunc(entries, func(e income.Entry) bool { return e.Date.Before(from) || e.Date.After(to) })
+	result.Summary = income.Summarize(entries, today)
+
+	if args.IncludeEntries {
+		result.Entries = entries
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: result.Description(),
+			},
+		},
+	}, result, nil
+}
@@ -0,0 +1,101 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/chickenzord/goksei"
+	"github.com/chickenzord/portosync/internal/income"
+	"github.com/chickenzord/portosync/internal/portfolio"
+	"github.com/chickenzord/portosync/internal/source"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMCP_handleGetIncome(t *testing.T) {
+	dir := t.TempDir()
+	holdings := filepath.Join(dir, "holdings.yaml")
+	incomeFile := filepath.Join(dir, "income.csv")
+
+	today := portfolio.Today()
+	day := func(months int) string { return today.AddDate(0, months, 0).Format(time.DateOnly) }
+
+	require.NoError(t, os.WriteFile(holdings, []byte(`holdings:
+  - {symbol: BBCA, type: equity, amount: 200, price: 10000}
+  - {symbol: FR0098, type: bond, amount: 100000000, price: 1}
+`), 0o600))
+	require.NoError(t, os.WriteFile(incomeFile, []byte(fmt.Sprintf(`date,account,symbol,type,amount,tax
+%s,vault,FR0098,coupon,1000,100
+%s,vault,FR0098,coupon,1000,100
+%s,vault,BBCA,dividend,500,50
+%s,vault,SOLD,dividend,300,30
+`, day(-9), day(-3), day(-2), day(-1))), 0o600))
+
+	mcpServer := &MCP{
+		kseiClients: make(map[string]*goksei.Client),
+		sources: map[string]source.Source{
+			"vault": source.NewManual(holdings),
+		},
+		income:     income.NewStore(filepath.Join(dir, "income.json")),
+		incomeFile: incomeFile,
+	}
+
+	ctx := context.Background()
+	req := &mcp.CallToolRequest{}
+
+	// Recorded payments replace file payments with the same key
+	result, recorded, err := mcpServer.handleRecordIncome(ctx, req, RecordIncomeArgs{
+		AccountName: "vault", Symbol: "bbca", Type: "dividend", Date: day(-2), Amount: 600, Tax: 60,
+	})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	require.NotNil(t, recorded.Entry)
+	assert.Equal(t, 540.0, recorded.Entry.Net())
+
+	result, data, err := mcpServer.handleGetIncome(ctx, req, GetIncomeArgs{IncludeEntries: true})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+
+	require.Len(t, data.Summary.Totals, 1)
+	assert.Equal(t, income.Totals{Currency: "IDR", Received: 900 + 900 + 540 + 270, Projected: 900 + 900 + 540, Tax: 290, Payments: 7}, data.Summary.Totals[0])
+	assert.Len(t, data.Entries, 7, "four received and three projected, SOLD is no longer held")
+	assert.Equal(t, "FR0098", data.Summary.Assets[0].Symbol)
+	assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, "Per month:")
+
+	_, data, err = mcpServer.handleGetIncome(ctx, req, GetIncomeArgs{Symbols: []string{"bbca"}, ExcludeProjected: true})
+	require.NoError(t, err)
+	assert.Equal(t, income.Totals{Currency: "IDR", Received: 540, Tax: 60, Payments: 1}, data.Summary.Totals[0])
+	assert.Empty(t, data.Entries)
+
+	result, recorded, err = mcpServer.handleRecordIncome(ctx, req, RecordIncomeArgs{AccountName: "vault", Symbol: "BBCA", Type: "dividend", Date: day(-2), Remove: true})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	assert.True(t, recorded.Removed)
+
+	for _, args := range []RecordIncomeArgs{
+		{AccountName: "unknown", Symbol: "BBCA", Type: "dividend", Date: day(0), Amount: 1},
+		{AccountName: "vault", Symbol: "BBCA", Type: "dividend", Date: "yesterday", Amount: 1},
+		{AccountName: "vault", Symbol: "BBCA", Type: "bonus", Date: day(0), Amount: 1},
+		{AccountName: "vault", Symbol: "BBCA", Type: "dividend", Date: day(0)},
+	} {
+		result, _, err := mcpServer.handleRecordIncome(ctx, req, args)
+		require.NoError(t, err)
+		assert.True(t, result.IsError, "%+v", args)
+	}
+
+	for _, args := range []GetIncomeArgs{{ToDate: "soon"}, {AccountNames: []string{"unknown"}}} {
+		result, _, err := mcpServer.handleGetIncome(ctx, req, args)
+		require.NoError(t, err)
+		assert.True(t, result.IsError, "%+v", args)
+	}
+
+	mcpServer.income = nil
+	result, _, err = mcpServer.handleRecordIncome(ctx, req, RecordIncomeArgs{AccountName: "vault", Symbol: "BBCA", Type: "dividend", Date: day(0), Amount: 1})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+}
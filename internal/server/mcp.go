@@ -1,13 +1,30 @@
 package server
 
 import (
+	"cmp"
 	"context"
+	"fmt"
 	"maps"
 	"net/http"
-	"strings"
+	"os"
+	"path/filepath"
+	"slices"
 	"time"
 
 	"github.com/chickenzord/goksei"
+	"github.com/chickenzord/portosync/internal/alert"
+	"github.com/chickenzord/portosync/internal/benchmark"
+	"github.com/chickenzord/portosync/internal/costbasis"
+	"github.com/chickenzord/portosync/internal/digest"
+	"github.com/chickenzord/portosync/internal/export"
+	"github.com/chickenzord/portosync/internal/income"
+	"github.com/chickenzord/portosync/internal/notify"
+	"github.com/chickenzord/portosync/internal/portfolio"
+	"github.com/chickenzord/portosync/internal/price"
+	"github.com/chickenzord/portosync/internal/rebalance"
+	"github.com/chickenzord/portosync/internal/snapshot"
+	"github.com/chickenzord/portosync/internal/source"
+	"github.com/chickenzord/portosync/internal/tag"
 	"github.com/chickenzord/portosync/internal/version"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
@@ -15,7 +32,93 @@ import (
 // MCP wraps the MCP SDK server
 type MCP struct {
 	kseiClients map[string]*goksei.Client
+	sources     map[string]source.Source // accounts provided by non-KSEI sources
+	dataDir     string                   // empty when no data directory is configured
+	snapshots   *snapshot.Store          // nil when no data directory is configured
+	prices      *price.Store             // nil when no data directory is configured
+	exportOpts  export.Options
+	latest      balanceCache // latest fetched balances, backing portfolio resources
 	mcpServer   *mcp.Server
+
+	rebalanceTargets   []rebalance.Target
+	rebalanceTolerance float64
+
+	costBasis        *costbasis.Store // nil when no data directory is configured
+	transactionsFile string
+
+	income     *income.Store // nil when no data directory is configured
+	incomeFile string
+
+	bondsFile          string
+	classificationFile string
+
+	benchmarks map[string]benchmark.Series
+
+	tags tag.Tags
+
+	alerts      *alert.Evaluator // nil when alerting is disabled
+	alertSender alert.Sender
+
+	notifiers       []notify.Notifier
+	digestTopMovers int
+	failures        failureLog          // failed fetches since the last digest
+	lastDigest      []portfolio.Balance // balances of the last digest, compared with when there is no earlier snapshot
+}
+
+// Options configures the MCP server
+type Options struct {
+	KseiAccounts      map[string]Account
+	KseiPlainPassword bool
+	KseiAuthCacheDir  string
+
+	// ManualAccounts maps account names to local YAML or CSV holdings files
+	ManualAccounts map[string]string
+
+	// BrokerAccounts maps account names to broker CSV statements,
+	// read using profiles from BrokerProfilesFile
+	BrokerAccounts     map[string]BrokerAccount
+	BrokerProfilesFile string
+
+	// DataDir stores persistent data such as portfolio snapshots, leave empty to disable persistence
+	DataDir string
+
+	// Ledger configures the beancount and hledger export formats
+	Ledger export.LedgerOptions
+
+	// RebalanceTargets and RebalanceTolerance are the defaults of the suggest_rebalance tool
+	RebalanceTargets   []rebalance.Target
+	RebalanceTolerance float64
+
+	// TransactionsFile is a CSV file of buy and sell transactions used to compute cost basis
+	TransactionsFile string
+
+	// BondsFile is a CSV catalogue of bond coupon rates and maturity dates
+	BondsFile string
+
+	// ClassificationFile is a CSV file mapping symbols to sectors and issuers
+	ClassificationFile string
+
+	// IncomeFile is a CSV file of dividends, coupons and other income received
+	IncomeFile string
+
+	// Benchmarks maps benchmark names to CSV files of index levels or annual rates
+	Benchmarks map[string]string
+
+	// AccountTags maps account names to tags such as retirement or joint
+	AccountTags map[string][]string
+
+	// HoldingTags maps symbols, or account/symbol for a holding in one account, to tags
+	HoldingTags map[string][]string
+
+	// AlertWebhookURL receives alerts raised after background fetches as JSON, leave empty to disable alerting
+	AlertWebhookURL string
+	AlertRules      alert.Rules
+
+	// Notifiers receive the daily digest sent by RunDigest
+	Notifiers []notify.Notifier
+
+	// DigestTopMovers is the number of holdings with the largest value change listed in the digest
+	DigestTopMovers int
 }
 
 // selectKseiClients get clients by multiple names,
@@ -40,12 +143,48 @@ func (m *MCP) selectKseiClients(names []string) map[string]*goksei.Client {
 	return clients
 }
 
-func (m *MCP) getKseiClientNames() []string {
-	names := make([]string, 0, len(m.kseiClients))
+// selectSources get non-KSEI sources by multiple names,
+// if empty or nil, it will return all sources
+func (m *MCP) selectSources(names []string) map[string]source.Source {
+	sources := make(map[string]source.Source)
+
+	if len(names) == 0 {
+		maps.Copy(sources, m.sources)
+
+		return sources
+	}
+
+	for _, name := range names {
+		if src, ok := m.sources[name]; ok {
+			sources[name] = src
+		}
+	}
+
+	return sources
+}
+
+// selectAccounts get accounts of all source types by multiple names,
+// if empty or nil, it will return all accounts
+func (m *MCP) selectAccounts(names []string) accountSet {
+	return accountSet{
+		kseiClients: m.selectKseiClients(names),
+		sources:     m.selectSources(names),
+	}
+}
+
+// getAccountNames returns sorted names of all configured accounts
+func (m *MCP) getAccountNames() []string {
+	names := make([]string, 0, len(m.kseiClients)+len(m.sources))
 	for name := range m.kseiClients {
 		names = append(names, name)
 	}
 
+	for name := range m.sources {
+		names = append(names, name)
+	}
+
+	slices.Sort(names)
+
 	return names
 }
 
@@ -61,26 +200,111 @@ func (m *MCP) RunStdio(ctx context.Context) error {
 	return m.mcpServer.Run(ctx, &mcp.StdioTransport{})
 }
 
-// NewMCP creates a new MCP server using the official MCP Go SDK
-func NewMCP(accounts map[string]Account, plainPassword bool, authCacheDir string) *MCP {
-	authStore, err := goksei.NewFileAuthStore(authCacheDir)
+// NewMCP creates a new MCP server using the official MCP Go SDK.
+// It returns an error when the options are invalid, e.g. a benchmark file cannot be read.
+func NewMCP(opts Options) (*MCP, error) {
+	authStore, err := goksei.NewFileAuthStore(opts.KseiAuthCacheDir)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
 
-	gokseiClients := make(map[string]*goksei.Client, len(accounts))
-	for name, account := range accounts {
+	gokseiClients := make(map[string]*goksei.Client, len(opts.KseiAccounts))
+	for name, account := range opts.KseiAccounts {
 		gokseiClients[name] = goksei.NewClient(goksei.ClientOpts{
 			Username:      account.Username,
 			Password:      account.Password,
-			PlainPassword: plainPassword,
+			PlainPassword: opts.KseiPlainPassword,
 			Timeout:       1 * time.Minute,
 			AuthStore:     authStore,
 		})
 	}
 
+	sources := make(map[string]source.Source, len(opts.ManualAccounts)+len(opts.BrokerAccounts))
+	for name, path := range opts.ManualAccounts {
+		if _, ok := gokseiClients[name]; ok {
+			return nil, fmt.Errorf("manual account %q conflicts with a KSEI account of the same name", name)
+		}
+
+		sources[name] = source.NewManual(path)
+	}
+
+	brokerProfiles, err := source.LoadBrokerProfiles(opts.BrokerProfilesFile)
+	if err != nil {
+		return nil, err
+	}
+
+	for name, account := range opts.BrokerAccounts {
+		if _, ok := gokseiClients[name]; ok {
+			return nil, fmt.Errorf("broker account %q conflicts with a KSEI account of the same name", name)
+		}
+
+		if _, ok := sources[name]; ok {
+			return nil, fmt.Errorf("broker account %q conflicts with a manual account of the same name", name)
+		}
+
+		profile, ok := brokerProfiles[account.Profile]
+		if !ok {
+			return nil, fmt.Errorf("broker account %q uses unknown profile %q", name, account.Profile)
+		}
+
+		sources[name] = source.NewBrokerCSV(account.Path, profile)
+	}
+
 	s := &MCP{
 		kseiClients: gokseiClients,
+		sources:     sources,
+		exportOpts:  export.Options{Ledger: opts.Ledger},
+
+		rebalanceTargets:   opts.RebalanceTargets,
+		rebalanceTolerance: opts.RebalanceTolerance,
+		transactionsFile:   opts.TransactionsFile,
+		incomeFile:         opts.IncomeFile,
+		bondsFile:          opts.BondsFile,
+		classificationFile: opts.ClassificationFile,
+		benchmarks:         make(map[string]benchmark.Series),
+		tags:               tag.New(opts.AccountTags, opts.HoldingTags),
+	}
+
+	if err := s.tags.Validate(s.getAccountNames()); err != nil {
+		return nil, fmt.Errorf("invalid ACCOUNT_TAGS or HOLDING_TAGS: %w", err)
+	}
+
+	for name, path := range opts.Benchmarks {
+		series, err := benchmark.Load(name, path)
+		if err != nil {
+			return nil, fmt.Errorf("invalid benchmark %q: %w", name, err)
+		}
+
+		s.benchmarks[name] = series
+	}
+
+	if opts.DataDir != "" {
+		s.dataDir = opts.DataDir
+
+		snapshots, err := snapshot.NewStore(filepath.Join(opts.DataDir, "snapshots"))
+		if err != nil {
+			return nil, err
+		}
+
+		s.snapshots = snapshots
+
+		prices, err := price.NewStore(filepath.Join(opts.DataDir, "prices"))
+		if err != nil {
+			return nil, err
+		}
+
+		s.prices = prices
+		s.costBasis = costbasis.NewStore(filepath.Join(opts.DataDir, "cost_basis.json"))
+		s.income = income.NewStore(filepath.Join(opts.DataDir, "income.json"))
+	}
+
+	s.notifiers = opts.Notifiers
+	s.digestTopMovers = cmp.Or(opts.DigestTopMovers, digest.DefaultTopMovers)
+
+	if opts.AlertWebhookURL != "" {
+		s.alerts = alert.NewEvaluator(opts.AlertRules)
+		s.alertSender = alert.Webhook{URL: opts.AlertWebhookURL}
+		s.seedAlerts()
 	}
 
 	// Create MCP server with implementation info
@@ -89,15 +313,21 @@ func NewMCP(accounts map[string]Account, plainPassword bool, authCacheDir string
 		Name:    "portosync",
 		Version: versionInfo.Version,
 		Title:   "PortoSync - Financial Portfolio Integration Server",
-	}, nil)
+	}, &mcp.ServerOptions{
+		CompletionHandler: s.handleComplete,
+		// Accept all resource subscriptions, updates are sent by notifyResourcesUpdated
+		SubscribeHandler:   func(context.Context, *mcp.SubscribeRequest) error { return nil },
+		UnsubscribeHandler: func(context.Context, *mcp.UnsubscribeRequest) error { return nil },
+	})
 
 	// Add get_portfolio tool
 	readOnlyTrue := true
 	openWorldFalse := false
+	destructiveTrue := true
 	mcp.AddTool(mcpServer, &mcp.Tool{
 		Name:        "get_portfolio",
 		Title:       "Get Portfolio Balances",
-		Description: "Retrieves current investment portfolio balances from KSEI (Indonesian Central Securities Depository) accounts. Returns detailed information about holdings including asset symbols, names, quantities, values, and currencies. Use this tool when you need to check current portfolio positions, asset allocations, or account balances. The data is fetched in real-time from KSEI AKSES and changes daily during settlement hours.",
+		Description: "Retrieves current investment portfolio balances from KSEI (Indonesian Central Securities Depository) accounts, manually maintained holdings (property, gold, deposits, foreign brokers) and imported broker CSV statements. Returns detailed information about holdings including asset symbols, names, quantities, values, and currencies, RDN cash balances (asset_type cash), and totals per currency. Holdings with a known cost basis include unrealized gain and loss, summed per account in gains. Balances can be filtered by asset type, symbol, currency, minimum value and user-defined tags (e.g. retirement or kids, set on accounts and holdings), sorted (e.g. value_desc) and paged with limit and offset, so a request such as top 10 holdings by value only returns what is needed. With group_by (asset, account, asset_type or currency) holdings are consolidated, e.g. the same symbol held in several accounts, with a per-account breakdown, sorted by value descending or by sort_by. Use this tool when you need to check current portfolio positions, asset allocations, or account balances. The data is fetched in real-time from KSEI AKSES and changes daily during settlement hours.",
 		Annotations: &mcp.ToolAnnotations{
 			Title:           "Get Portfolio Balances",
 			ReadOnlyHint:    true,
@@ -111,7 +341,7 @@ func NewMCP(accounts map[string]Account, plainPassword bool, authCacheDir string
 	mcp.AddTool(mcpServer, &mcp.Tool{
 		Name:        "list_account_names",
 		Title:       "List Available Account Names",
-		Description: "Lists all account names that are currently configured in the server. Use this tool to discover which accounts are available before calling get_portfolio with specific account names. Each account name represents a separate KSEI AKSES account connection. This is useful for understanding the scope of available data and for selecting specific accounts to query.",
+		Description: "Lists all account names that are currently configured in the server. Use this tool to discover which accounts are available before calling get_portfolio with specific account names. Each account name represents a separate KSEI AKSES account connection or another configured holdings source. Accounts are listed with their configured tags (e.g. retirement, kids or joint), and can be narrowed to accounts carrying any of the given tags. This is useful for understanding the scope of available data and for selecting specific accounts to query.",
 		Annotations: &mcp.ToolAnnotations{
 			Title:           "List Available Account Names",
 			ReadOnlyHint:    true,
@@ -121,28 +351,250 @@ func NewMCP(accounts map[string]Account, plainPassword bool, authCacheDir string
 		},
 	}, s.handleListAccountNames)
 
+	// Add export_portfolio tool
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name:        "export_portfolio",
+		Title:       "Export Portfolio Balances",
+		Description: "Exports portfolio balances as CSV, JSON Lines or pretty JSON text with a stable column order, suitable for spreadsheets, or as Beancount/hledger price and balance directives for plain-text accounting. Without a date range, current balances are fetched live from KSEI. With from_date and/or to_date, balances are read from the daily snapshots stored by the server, one row per holding per snapshot date. Use this tool when the user wants a file or table rather than a summary.",
+		Annotations: &mcp.ToolAnnotations{
+			Title:           "Export Portfolio Balances",
+			ReadOnlyHint:    true,
+			IdempotentHint:  false, // Live exports change over time (daily settlement updates)
+			OpenWorldHint:   &openWorldFalse,
+			DestructiveHint: &readOnlyTrue, // false means non-destructive
+		},
+	}, s.handleExportPortfolio)
+
+	// Add get_account_info tool
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name:        "get_account_info",
+		Title:       "Get Account Information",
+		Description: "Retrieves identity and structure of configured accounts: the KSEI investor ID (SID) and investor name, plus every securities sub-account or RDN cash account with its participant (broker, asset manager or bank), number of holdings and total value per currency. Use this tool to answer questions like how much is held at each broker, or which sub-account a holding sits in. Sensitive identity numbers such as NIK and NPWP are never returned.",
+		Annotations: &mcp.ToolAnnotations{
+			Title:           "Get Account Information",
+			ReadOnlyHint:    true,
+			IdempotentHint:  false, // Sub-account values change over time (daily settlement updates)
+			OpenWorldHint:   &openWorldFalse,
+			DestructiveHint: &readOnlyTrue, // false means non-destructive
+		},
+	}, s.handleGetAccountInfo)
+
+	// Add suggest_rebalance tool
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name:        "suggest_rebalance",
+		Title:       "Suggest Portfolio Rebalance",
+		Description: "Compares current holdings against target allocation weights and returns the buy or sell amount needed per target. Targets are asset types (e.g. equity), asset type with sub-type (e.g. mutual_fund/money_market_fund) or single symbols (e.g. symbol:BBCA); each holding counts towards its most specific target and unmatched holdings are grouped as other. Targets deviating less than the tolerance band are held. Trades of IDX equity symbol targets are rounded down to whole lots of 100 shares, with action below_lot when less than one lot is needed, using the last known price for symbols not held yet; asset type targets are not rounded. Uses configured targets unless target_allocation is given. This tool only suggests trades, it never places orders.",
+		Annotations: &mcp.ToolAnnotations{
+			Title:           "Suggest Portfolio Rebalance",
+			ReadOnlyHint:    true,
+			IdempotentHint:  false, // Portfolio data changes over time (daily settlement updates)
+			OpenWorldHint:   &openWorldFalse,
+			DestructiveHint: &readOnlyTrue, // false means non-destructive
+		},
+	}, s.handleSuggestRebalance)
+
+	// Add search_assets tool
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name:        "search_assets",
+		Title:       "Search Assets",
+		Description: "Resolves a free-text query to asset symbols and fund codes. Matches symbols, full product names, acronyms (e.g. BCA for Bank Central Asia), investment managers and tolerates small typos. Searches assets held in configured accounts and the KSEI mutual fund catalogue, returning symbol, full product name, fund type, investment manager and holding accounts. Use this tool before other tools when the user refers to an asset by name rather than by code.",
+		Annotations: &mcp.ToolAnnotations{
+			Title:           "Search Assets",
+			ReadOnlyHint:    true,
+			IdempotentHint:  false, // Held assets change over time
+			OpenWorldHint:   &openWorldFalse,
+			DestructiveHint: &readOnlyTrue, // false means non-destructive
+		},
+	}, s.handleSearchAssets)
+
+	// Add set_cost_basis tool
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name:        "set_cost_basis",
+		Title:       "Set Holding Cost Basis",
+		Description: "Records the purchase cost of a holding so get_portfolio can report unrealized gain and loss. Pass the average purchase price per unit, or the total cost of the units currently held. Entered values override cost basis computed from the configured transactions file until a transaction dated after them is added to it; pass remove to delete an entered value. Requires a configured data directory.",
+		Annotations: &mcp.ToolAnnotations{
+			Title:           "Set Holding Cost Basis",
+			ReadOnlyHint:    false,
+			IdempotentHint:  true,
+			OpenWorldHint:   &openWorldFalse,
+			DestructiveHint: &destructiveTrue, // replaces a previously entered cost basis
+		},
+	}, s.handleSetCostBasis)
+
+	// Add list_transactions tool
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name:        "list_transactions",
+		Title:       "List Portfolio Transactions",
+		Description: "Lists buy, sell, subscription and redemption activity, most recent first. KSEI only exposes balances, so transactions are inferred from unit changes per symbol and account between consecutive daily snapshots of each account, dated at the later snapshot and priced at the observed value per unit. Transactions entered in the configured transactions file are included as is and replace inferred transactions of the same account and symbol in the same period. Inferred transactions require a configured data directory with snapshots recorded over time.",
+		Annotations: &mcp.ToolAnnotations{
+			Title:           "List Portfolio Transactions",
+			ReadOnlyHint:    true,
+			IdempotentHint:  false, // New snapshots add inferred transactions
+			OpenWorldHint:   &openWorldFalse,
+			DestructiveHint: &readOnlyTrue, // false means non-destructive
+		},
+	}, s.handleListTransactions)
+
+	// Add get_performance tool
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name:        "get_performance",
+		Title:       "Get Portfolio Performance",
+		Description: "Computes time-weighted return (TWR) and money-weighted return (XIRR) from stored daily snapshots over a date range, overall, per account and per asset type. Buys and sells from list_transactions are treated as cash flows and cash balances are excluded, so returns reflect how the holdings performed rather than how much was added. Days without a snapshot, or where an account is missing from it, are bridged by chaining the surrounding snapshots and reported as missing days. Use TWR to compare against benchmarks or deposit rates and XIRR for the return on the money actually invested. Requires a configured data directory.",
+		Annotations: &mcp.ToolAnnotations{
+			Title:           "Get Portfolio Performance",
+			ReadOnlyHint:    true,
+			IdempotentHint:  false, // New snapshots extend the default range
+			OpenWorldHint:   &openWorldFalse,
+			DestructiveHint: &readOnlyTrue, // false means non-destructive
+		},
+	}, s.handleGetPerformance)
+
+	// Add compare_to_benchmark tool
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name:        "compare_to_benchmark",
+		Title:       "Compare Portfolio to Benchmark",
+		Description: "Compares the time-weighted growth of the portfolio against configured benchmark series, such as a market index or a deposit rate, over a date range. Both are rebased to 100 at the first snapshot date the benchmark is known and reported at every snapshot date, together with the return of each and the excess return in percentage points. Benchmarks are loaded from CSV files of index levels or annual rates. Cash balances are excluded and buys and sells are treated as cash flows like in get_performance. Requires a configured data directory.",
+		Annotations: &mcp.ToolAnnotations{
+			Title:           "Compare Portfolio to Benchmark",
+			ReadOnlyHint:    true,
+			IdempotentHint:  false, // New snapshots extend the default range
+			OpenWorldHint:   &openWorldFalse,
+			DestructiveHint: &readOnlyTrue, // false means non-destructive
+		},
+	}, s.handleCompareToBenchmark)
+
+	// Add import_prices tool
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name:        "import_prices",
+		Title:       "Import Price History",
+		Description: "Imports historical prices, such as mutual fund NAVs or IDX closing prices, from a local CSV file or CSV content into the price store. Columns are date, symbol, a price column (price, close, nav or value) and optionally currency. The symbol column may be omitted when a symbol is passed, e.g. for the NAV history of a single fund. Imported prices replace stored prices of the same symbol and date. Can also record prices implied by all stored snapshots, which happens automatically for every new fetch. Requires a configured data directory.",
+		Annotations: &mcp.ToolAnnotations{
+			Title:           "Import Price History",
+			ReadOnlyHint:    false,
+			IdempotentHint:  true,
+			OpenWorldHint:   &openWorldFalse,
+			DestructiveHint: &destructiveTrue, // replaces stored prices of the same dates
+		},
+	}, s.handleImportPrices)
+
+	// Add get_price_history tool
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name:        "get_price_history",
+		Title:       "Get Price History",
+		Description: "Returns stored prices of a symbol over a date range, from imported CSV files and from the value per unit observed in daily snapshots, together with the price change over the range. Use search_assets to resolve names to symbols. Requires a configured data directory.",
+		Annotations: &mcp.ToolAnnotations{
+			Title:           "Get Price History",
+			ReadOnlyHint:    true,
+			IdempotentHint:  false, // New fetches and imports add prices
+			OpenWorldHint:   &openWorldFalse,
+			DestructiveHint: &readOnlyTrue, // false means non-destructive
+		},
+	}, s.handleGetPriceHistory)
+
+	// Add record_income tool
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name:        "record_income",
+		Title:       "Record Income Payment",
+		Description: "Records a dividend, coupon, interest or distribution payment of a holding in the income ledger, as the gross amount and the tax withheld. Payments dated in the future are recorded as expected income, e.g. a declared dividend. A payment replaces any recorded payment of the same date, account, symbol and type; pass remove to delete it. Payments can also be imported from the configured income file. Requires a configured data directory.",
+		Annotations: &mcp.ToolAnnotations{
+			Title:           "Record Income Payment",
+			ReadOnlyHint:    false,
+			IdempotentHint:  true,
+			OpenWorldHint:   &openWorldFalse,
+			DestructiveHint: &destructiveTrue, // replaces a previously recorded payment
+		},
+	}, s.handleRecordIncome)
+
+	// Add get_income tool
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name:        "get_income",
+		Title:       "Get Portfolio Income",
+		Description: "Summarizes dividend, coupon and other income per month and per asset from the income ledger, net of withheld tax. By default covers the past year of received income and the coming year of projected income. Projected income repeats every payment received in the past year one year later for holdings still held, unless a payment for the same holding is already recorded for that month. Use record_income to add payments.",
+		Annotations: &mcp.ToolAnnotations{
+			Title:           "Get Portfolio Income",
+			ReadOnlyHint:    true,
+			IdempotentHint:  false, // Projections move with the current date
+			OpenWorldHint:   &openWorldFalse,
+			DestructiveHint: &readOnlyTrue, // false means non-destructive
+		},
+	}, s.handleGetIncome)
+
+	// Add upcoming_maturities tool
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name:        "upcoming_maturities",
+		Title:       "List Upcoming Bond Maturities",
+		Description: "Lists bond holdings maturing within a number of days, earliest first, with the principal repaid, the next coupon date and the coupon income left until maturity. KSEI only reports bond symbols and amounts, so coupon rates and maturity dates come from the configured bond catalogue; bonds held but missing from it are listed separately. Use to plan reinvestment of maturing bonds.",
+		Annotations: &mcp.ToolAnnotations{
+			Title:           "List Upcoming Bond Maturities",
+			ReadOnlyHint:    true,
+			IdempotentHint:  false, // Maturities move with the current date
+			OpenWorldHint:   &openWorldFalse,
+			DestructiveHint: &readOnlyTrue, // false means non-destructive
+		},
+	}, s.handleUpcomingMaturities)
+
+	// Add get_risk_report tool
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name:        "get_risk_report",
+		Title:       "Get Portfolio Risk Report",
+		Description: "Measures how concentrated the portfolio is: the largest position, the combined share of the top N positions, the Herfindahl index and effective number of positions, exposure per sector and issuer group from the built-in classification of IDX equities and the configured classification file, and exposure per currency. The same symbol held in several accounts counts as one position and cash is excluded from concentration metrics. Returns warnings for positions, sectors and issuers above the limits; mention them to the user, e.g. when one stock has become too big.",
+		Annotations: &mcp.ToolAnnotations{
+			Title:           "Get Portfolio Risk Report",
+			ReadOnlyHint:    true,
+			IdempotentHint:  false, // Data changes daily during settlement hours
+			OpenWorldHint:   &openWorldFalse,
+			DestructiveHint: &readOnlyTrue, // false means non-destructive
+		},
+	}, s.handleGetRiskReport)
+
+	// Add get_net_worth_timeline tool
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name:        "get_net_worth_timeline",
+		Title:       "Get Net Worth Timeline",
+		Description: "Returns the total value of all accounts, holdings and cash combined, per day, week or month from stored snapshots, converted to one currency, for charting net worth over time. Each point takes the last snapshot of its period. Accounts missing from a snapshot, e.g. after a failed fetch, keep their last known value, and periods without a snapshot are filled by carrying the previous value forward, interpolating linearly, or left out. Other currencies are converted with the given fx_rates or with imported exchange rate prices such as USDIDR. Requires a configured data directory.",
+		Annotations: &mcp.ToolAnnotations{
+			Title:           "Get Net Worth Timeline",
+			ReadOnlyHint:    true,
+			IdempotentHint:  false, // New snapshots extend the default range
+			OpenWorldHint:   &openWorldFalse,
+			DestructiveHint: &readOnlyTrue, // false means non-destructive
+		},
+	}, s.handleGetNetWorthTimeline)
+
+	s.addResources(mcpServer)
+	s.addPrompts(mcpServer)
+
 	s.mcpServer = mcpServer
 
-	return s
+	return s, nil
 }
 
 // handleGetPortfolio handles the get_portfolio MCP tool
 func (m *MCP) handleGetPortfolio(ctx context.Context, req *mcp.CallToolRequest, args GetPortfolioArgs) (*mcp.CallToolResult, GetPortfolioResult, error) {
-	result := GetPortfolioResult{}
+	result := GetPortfolioResult{Offset: max(args.Offset, 0)}
 
-	clients := m.selectKseiClients(args.AccountNames)
-	if len(clients) == 0 {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				&mcp.TextContent{
-					Text: "Selected accounts not found, available accounts are " + strings.Join(m.getKseiClientNames(), ", "),
-				},
-			},
-			IsError: true,
-		}, result, nil
+	if err := portfolio.Sort(nil, args.SortBy); err != nil {
+		return errorResult("Invalid sort_by: " + err.Error()), result, nil
 	}
 
-	balances, err := getAllBalances(clients)
+	if args.GroupBy != "" {
+		if _, err := portfolio.GroupBalances(nil, args.GroupBy); err != nil {
+			return errorResult("Invalid group_by: " + err.Error()), result, nil
+		}
+	}
+
+	accounts := m.selectAccounts(args.AccountNames)
+	if accounts.empty() {
+		return accountsNotFoundResult(m.getAccountNames()), result, nil
+	}
+
+	if len(args.Tags) > 0 {
+		if accounts = m.selectTagged(accounts, args.Tags); accounts.empty() {
+			return tagsNotFoundResult("accounts or holdings", m.tags.Names()), result, nil
+		}
+	}
+
+	balances, err := m.fetchBalances(ctx, accounts)
 	if err != nil {
 		return nil, result, err
 	}
@@ -158,7 +610,46 @@ func (m *MCP) handleGetPortfolio(ctx context.Context, req *mcp.CallToolRequest,
 		}, result, nil
 	}
 
-	result.Balances = balances
+	if book, err := m.costBook(); err != nil {
+		// Missing cost basis should not prevent returning live data
+		fmt.Fprintf(os.Stderr, "Error loading cost basis: %v\n", err)
+	} else {
+		book.Apply(balances)
+	}
+
+	classification, err := m.classification()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading classification file: %v\n", err)
+	}
+
+	classification.Apply(balances)
+	m.tags.Apply(balances)
+
+	if catalogue, err := m.bondCatalogue(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading bond catalogue: %v\n", err)
+	} else {
+		catalogue.Apply(balances, portfolio.Today())
+	}
+
+	filter := args.filter()
+	matched := filter.Apply(balances)
+	_ = portfolio.Sort(matched, args.SortBy) // validated above
+
+	result.Totals = portfolio.Totals(matched)
+	result.Gains = portfolio.Gains(matched)
+	result.Matched = len(matched)
+	result.filtered = filter.Active()
+
+	if args.GroupBy != "" {
+		groups, _ := portfolio.GroupBalances(matched, args.GroupBy) // validated above
+		_ = portfolio.SortGroups(groups, args.SortBy)               // validated by Sort above
+
+		result.GroupBy = args.GroupBy
+		result.Groups = portfolio.Page(groups, args.Offset, args.Limit)
+		result.Matched = len(groups)
+	} else {
+		result.Balances = portfolio.Page(matched, args.Offset, args.Limit)
+	}
 
 	return &mcp.CallToolResult{
 		Content: []mcp.Content{
@@ -170,8 +661,17 @@ func (m *MCP) handleGetPortfolio(ctx context.Context, req *mcp.CallToolRequest,
 }
 
 func (m *MCP) handleListAccountNames(ctx context.Context, req *mcp.CallToolRequest, args ListAccountNamesArgs) (*mcp.CallToolResult, ListAccountNamesResult, error) {
+	names := m.getAccountNames()
+	if len(args.Tags) > 0 {
+		if names = m.tags.Accounts(names, args.Tags); len(names) == 0 {
+			return tagsNotFoundResult("accounts", m.tags.Names()), ListAccountNamesResult{}, nil
+		}
+	}
+
 	result := ListAccountNamesResult{
-		AccountNames: m.getKseiClientNames(),
+		AccountNames: names,
+		AccountTags:  m.accountTags(names),
+		Tags:         m.tags.Names(),
 	}
 
 	return &mcp.CallToolResult{
@@ -2,11 +2,17 @@ package server
 
 import (
 	"context"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/chickenzord/goksei"
+	"github.com/chickenzord/portosync/internal/portfolio"
+	"github.com/chickenzord/portosync/internal/source"
+	"github.com/chickenzord/portosync/internal/tag"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestListAccountNamesResult_Description(t *testing.T) {
@@ -29,6 +35,15 @@ func TestListAccountNamesResult_Description(t *testing.T) {
 			},
 			expected: "Available accounts: personal",
 		},
+		{
+			name: "tagged accounts",
+			result: ListAccountNamesResult{
+				AccountNames: []string{"personal", "business"},
+				AccountTags:  map[string][]string{"personal": {"joint", "retirement"}},
+				Tags:         []string{"core", "joint", "retirement"},
+			},
+			expected: "Available accounts: personal (joint, retirement), business\nConfigured tags: core, joint, retirement",
+		},
 		{
 			name: "no accounts",
 			result: ListAccountNamesResult{
@@ -126,3 +141,234 @@ func TestMCP_handleListAccountNames_NoAccounts(t *testing.T) {
 	assert.True(t, ok)
 	assert.Equal(t, "No accounts configured", textContent.Text)
 }
+
+func TestMCP_handleListAccountNames_Tags(t *testing.T) {
+	mcpServer := &MCP{
+		kseiClients: make(map[string]*goksei.Client),
+		sources: map[string]source.Source{
+			"personal": source.NewManual("personal.yaml"),
+			"kids":     source.NewManual("kids.yaml"),
+			"business": source.NewManual("business.yaml"),
+		},
+		tags: tag.New(
+			map[string][]string{"personal": {"retirement", "joint"}, "kids": {"kids"}},
+			map[string][]string{"BBCA": {"core"}},
+		),
+	}
+
+	ctx := context.Background()
+	req := &mcp.CallToolRequest{}
+
+	result, data, err := mcpServer.handleListAccountNames(ctx, req, ListAccountNamesArgs{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"business", "kids", "personal"}, data.AccountNames)
+	assert.Equal(t, map[string][]string{"kids": {"kids"}, "personal": {"joint", "retirement"}}, data.AccountTags)
+	assert.Equal(t, []string{"core", "joint", "kids", "retirement"}, data.Tags)
+	assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, "personal (joint, retirement)")
+
+	_, data, err = mcpServer.handleListAccountNames(ctx, req, ListAccountNamesArgs{Tags: []string{"Joint", "kids"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"kids", "personal"}, data.AccountNames)
+
+	result, _, err = mcpServer.handleListAccountNames(ctx, req, ListAccountNamesArgs{Tags: []string{"core"}})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, "configured tags are core, joint, kids, retirement")
+}
+
+func TestMCP_handleGetPortfolio_ManualSource(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "holdings.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("holdings:\n  - {symbol: GOLD, type: commodity, amount: 10, value: 15000000}\n"), 0o600))
+
+	mcpServer := &MCP{
+		kseiClients: make(map[string]*goksei.Client),
+		sources: map[string]source.Source{
+			"vault": source.NewManual(path),
+		},
+	}
+
+	ctx := context.Background()
+	req := &mcp.CallToolRequest{}
+
+	result, data, err := mcpServer.handleGetPortfolio(ctx, req, GetPortfolioArgs{})
+	assert.NoError(t, err)
+	assert.False(t, result.IsError)
+	require.Len(t, data.Balances, 1)
+	assert.Equal(t, "vault", data.Balances[0].SourceAccount)
+	assert.Equal(t, "manual", data.Balances[0].SourceType)
+	assert.Equal(t, 15000000.0, data.Balances[0].UnitsValue)
+
+	result, _, err = mcpServer.handleGetPortfolio(ctx, req, GetPortfolioArgs{AccountNames: []string{"unknown"}})
+	assert.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, "vault")
+}
+
+func TestMCP_handleGetPortfolio_FilterSortPage(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "holdings.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`holdings:
+  - {symbol: BBCA, type: equity, amount: 100, value: 900000}
+  - {symbol: TLKM, type: equity, amount: 100, value: 300000}
+  - {symbol: GOTO, type: equity, amount: 100, value: 7000}
+  - {symbol: GOLD, type: commodity, amount: 10, value: 15000000}
+  - {symbol: AAPL, type: equity, amount: 1, value: 200, currency: USD}
+`), 0o600))
+
+	mcpServer := &MCP{
+		kseiClients: make(map[string]*goksei.Client),
+		sources: map[string]source.Source{
+			"vault": source.NewManual(path),
+		},
+	}
+
+	ctx := context.Background()
+	req := &mcp.CallToolRequest{}
+
+	result, data, err := mcpServer.handleGetPortfolio(ctx, req, GetPortfolioArgs{
+		AssetTypes: []string{"equity"},
+		Currency:   "IDR",
+		MinValue:   10000,
+		SortBy:     "value_desc",
+		Limit:      1,
+	})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	require.Len(t, data.Balances, 1)
+	assert.Equal(t, "BBCA", data.Balances[0].AssetSymbol)
+	assert.Equal(t, 2, data.Matched)
+	assert.Equal(t, []portfolio.CurrencyTotal{{Currency: "IDR", SecuritiesValue: 1200000, TotalValue: 1200000}}, data.Totals)
+	assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, "balances 1 to 1 of 2 matching")
+
+	_, data, err = mcpServer.handleGetPortfolio(ctx, req, GetPortfolioArgs{SortBy: "value_desc", Offset: 1, Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, data.Balances, 2)
+	assert.Equal(t, "BBCA", data.Balances[0].AssetSymbol)
+	assert.Equal(t, "TLKM", data.Balances[1].AssetSymbol)
+	assert.Equal(t, 5, data.Matched)
+
+	result, data, err = mcpServer.handleGetPortfolio(ctx, req, GetPortfolioArgs{Symbols: []string{"unknown"}})
+	require.NoError(t, err)
+	assert.False(t, result.IsError)
+	assert.Empty(t, data.Balances)
+	assert.Equal(t, "No portfolio balances match the filters", result.Content[0].(*mcp.TextContent).Text)
+	assert.Equal(t, "Portfolio is empty", GetPortfolioResult{}.Description(), "without filters")
+
+	result, _, err = mcpServer.handleGetPortfolio(ctx, req, GetPortfolioArgs{SortBy: "random"})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+}
+
+func TestMCP_handleGetPortfolio_GroupBy(t *testing.T) {
+	dir := t.TempDir()
+	personal := filepath.Join(dir, "personal.yaml")
+	business := filepath.Join(dir, "business.yaml")
+	require.NoError(t, os.WriteFile(personal, []byte(`holdings:
+  - {symbol: BBCA, name: Bank Central Asia, type: equity, amount: 100, value: 900000}
+  - {symbol: TLKM, type: equity, amount: 100, value: 300000}
+`), 0o600))
+	require.NoError(t, os.WriteFile(business, []byte(`holdings:
+  - {symbol: BBCA, name: Bank Central Asia, type: equity, amount: 200, value: 1800000}
+`), 0o600))
+
+	mcpServer := &MCP{
+		kseiClients: make(map[string]*goksei.Client),
+		sources: map[string]source.Source{
+			"personal": source.NewManual(personal),
+			"business": source.NewManual(business),
+		},
+	}
+
+	ctx := context.Background()
+	req := &mcp.CallToolRequest{}
+
+	result, data, err := mcpServer.handleGetPortfolio(ctx, req, GetPortfolioArgs{GroupBy: "asset", Limit: 1})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	assert.Empty(t, data.Balances)
+	assert.Equal(t, 2, data.Matched)
+	require.Len(t, data.Groups, 1)
+	assert.Equal(t, "BBCA", data.Groups[0].Key)
+	assert.Equal(t, 300.0, data.Groups[0].UnitsAmount)
+	assert.Equal(t, 2700000.0, data.Groups[0].UnitsValue)
+	assert.Equal(t, []portfolio.AccountShare{
+		{Account: "business", UnitsAmount: 200, UnitsValue: 1800000},
+		{Account: "personal", UnitsAmount: 100, UnitsValue: 900000},
+	}, data.Groups[0].Accounts)
+	assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, "groups by asset 1 to 1 of 2 matching")
+
+	// sort_by applies to groups
+	_, data, err = mcpServer.handleGetPortfolio(ctx, req, GetPortfolioArgs{GroupBy: "asset", SortBy: "value_asc", Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, data.Groups, 1)
+	assert.Equal(t, "TLKM", data.Groups[0].Key)
+
+	result, _, err = mcpServer.handleGetPortfolio(ctx, req, GetPortfolioArgs{GroupBy: "planet"})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+}
+
+func TestMCP_handleGetPortfolio_Tags(t *testing.T) {
+	dir := t.TempDir()
+	personal := filepath.Join(dir, "personal.yaml")
+	kids := filepath.Join(dir, "kids.yaml")
+	require.NoError(t, os.WriteFile(personal, []byte(`holdings:
+  - {symbol: BBCA, type: equity, amount: 100, value: 900000}
+  - {symbol: TLKM, type: equity, amount: 100, value: 300000}
+`), 0o600))
+	require.NoError(t, os.WriteFile(kids, []byte(`holdings:
+  - {symbol: TLKM, type: equity, amount: 50, value: 150000}
+`), 0o600))
+
+	mcpServer := &MCP{
+		kseiClients: make(map[string]*goksei.Client),
+		sources: map[string]source.Source{
+			"personal": source.NewManual(personal),
+			"kids":     source.NewManual(kids),
+			"business": source.NewManual(filepath.Join(dir, "missing.yaml")),
+		},
+		tags: tag.New(
+			map[string][]string{"kids": {"kids"}},
+			map[string][]string{"personal/BBCA": {"core"}},
+		),
+	}
+
+	ctx := context.Background()
+	req := &mcp.CallToolRequest{}
+
+	// The business account is never fetched, as none of its balances can carry the tags
+	result, data, err := mcpServer.handleGetPortfolio(ctx, req, GetPortfolioArgs{Tags: []string{"core", "Kids"}, SortBy: "value_desc"})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	require.Len(t, data.Balances, 2)
+	assert.Equal(t, "BBCA", data.Balances[0].AssetSymbol)
+	assert.Equal(t, []string{"core"}, data.Balances[0].Tags)
+	assert.Equal(t, "kids", data.Balances[1].SourceAccount)
+	assert.Equal(t, []string{"kids"}, data.Balances[1].Tags)
+	assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, "tags core")
+
+	_, data, err = mcpServer.handleGetPortfolio(ctx, req, GetPortfolioArgs{AccountNames: []string{"personal", "kids"}, Tags: []string{"kids"}})
+	require.NoError(t, err)
+	require.Len(t, data.Balances, 1)
+	assert.Equal(t, "kids", data.Balances[0].SourceAccount)
+
+	result, _, err = mcpServer.handleGetPortfolio(ctx, req, GetPortfolioArgs{AccountNames: []string{"personal"}, Tags: []string{"kids"}})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, "configured tags are core, kids")
+}
+
+func TestNewMCP_InvalidOptions(t *testing.T) {
+	dir := t.TempDir()
+
+	_, err := NewMCP(Options{
+		KseiAuthCacheDir: dir,
+		AccountTags:      map[string][]string{"unknown": {"retirement"}},
+	})
+	assert.ErrorContains(t, err, "invalid ACCOUNT_TAGS or HOLDING_TAGS")
+
+	_, err = NewMCP(Options{
+		KseiAuthCacheDir: dir,
+		Benchmarks:       map[string]string{"ihsg": filepath.Join(dir, "missing.csv")},
+	})
+	assert.ErrorContains(t, err, `invalid benchmark "ihsg"`)
+}
@@ -1,60 +1,80 @@
 package server
 
 import (
+	"cmp"
 	"fmt"
+	"math"
 	"strings"
+	"time"
+
+	"github.com/chickenzord/portosync/internal/benchmark"
+	"github.com/chickenzord/portosync/internal/bond"
+	"github.com/chickenzord/portosync/internal/costbasis"
+	"github.com/chickenzord/portosync/internal/export"
+	"github.com/chickenzord/portosync/internal/income"
+	"github.com/chickenzord/portosync/internal/networth"
+	"github.com/chickenzord/portosync/internal/performance"
+	"github.com/chickenzord/portosync/internal/portfolio"
+	"github.com/chickenzord/portosync/internal/price"
+	"github.com/chickenzord/portosync/internal/rebalance"
+	"github.com/chickenzord/portosync/internal/risk"
+	"github.com/chickenzord/portosync/internal/search"
+	"github.com/chickenzord/portosync/internal/transaction"
 )
 
 type GetPortfolioArgs struct {
-	AccountNames []string `json:"account_names" jsonschema:"description:List of specific account names to retrieve portfolio data from. Each name must match a configured account. If empty or omitted, returns portfolio data from all configured accounts. Use the list_account_names tool to discover available account names."`
-}
-
-type Balance struct {
-	SourceType    string  `json:"source_type"    jsonschema:"description:Type of data source providing this balance (e.g., KSEI for Indonesian securities depository)"`
-	SourceAccount string  `json:"source_account" jsonschema:"description:The account name from which this balance was retrieved, matching one of the configured account names"`
-	AssetSymbol   string  `json:"asset_symbol"   jsonschema:"description:Trading symbol or ticker of the asset (e.g., BBCA for Bank Central Asia stock)"`
-	AssetName     string  `json:"asset_name"     jsonschema:"description:Full descriptive name of the asset"`
-	AssetType     string  `json:"asset_type"     jsonschema:"description:Primary classification of the asset (e.g., Stock, Bond, Mutual Fund)"`
-	AssetSubType  string  `json:"asset_sub_type" jsonschema:"description:Additional classification or subtype of the asset, providing more granular categorization"`
-	UnitsAmount   float64 `json:"units_amount"   jsonschema:"description:Quantity of asset units held in the account"`
-	UnitsValue    float64 `json:"units_value"    jsonschema:"description:Total monetary value of the asset holdings in the specified currency"`
-	UnitsCurrency string  `json:"units_currency" jsonschema:"description:Currency code for the asset value (e.g., IDR for Indonesian Rupiah, USD for US Dollar)"`
+	AccountNames []string `json:"account_names"         jsonschema:"description:List of specific account names to retrieve portfolio data from. Each name must match a configured account. If empty or omitted, returns portfolio data from all configured accounts. Use the list_account_names tool to discover available account names."`
+	Tags         []string `json:"tags,omitempty"        jsonschema:"description:Only include holdings tagged with any of these tags, e.g. retirement or kids, either directly or through their account. Combines with account_names. Use the list_account_names tool to discover configured tags."`
+	AssetTypes   []string `json:"asset_types,omitempty" jsonschema:"description:Only include these asset types, e.g. equity, bond, mutual_fund or cash"`
+	Symbols      []string `json:"symbols,omitempty"     jsonschema:"description:Only include these asset symbols or fund codes"`
+	Currency     string   `json:"currency,omitempty"    jsonschema:"description:Only include balances in this currency, e.g. IDR"`
+	MinValue     float64  `json:"min_value,omitempty"   jsonschema:"description:Only include balances valued at least this much in their own currency"`
+	SortBy       string   `json:"sort_by,omitempty"     jsonschema:"description:Sort order: account (default), value_desc, value_asc, amount_desc or symbol. Value and amount orders sort by currency first since values in different currencies are not comparable. With group_by, groups are sorted by value_desc by default, value and amount orders apply within each currency and account or symbol sort groups by key."`
+	GroupBy      string   `json:"group_by,omitempty"    jsonschema:"description:Consolidate matching balances into groups by asset, account, asset_type, currency, sector or issuer_group, e.g. asset to merge the same symbol held in several accounts. Groups are returned instead of balances, split by currency and ordered by sort_by (value descending by default), with a per-account breakdown."`
+	Limit        int      `json:"limit,omitempty"       jsonschema:"description:Maximum number of balances (or groups with group_by) to return, e.g. 10 with sort_by value_desc for the top 10 holdings. Returns all if omitted."`
+	Offset       int      `json:"offset,omitempty"      jsonschema:"description:Number of matching balances (or groups with group_by) to skip, for paging through results together with limit"`
 }
 
-func (b Balance) AssetTypeFull() string {
-	fragments := []string{}
-
-	if b.AssetType != "" {
-		fragments = append(fragments, b.AssetType)
+// filter returns the balance filter selected by the arguments
+func (a GetPortfolioArgs) filter() portfolio.Filter {
+	return portfolio.Filter{
+		AssetTypes: a.AssetTypes,
+		Symbols:    a.Symbols,
+		Currency:   a.Currency,
+		MinValue:   a.MinValue,
+		Tags:       a.Tags,
 	}
-
-	if b.AssetSubType != "" {
-		fragments = append(fragments, b.AssetSubType)
-	}
-
-	return strings.Join(fragments, "/")
-}
-
-func (b Balance) Description() string {
-	return fmt.Sprintf("%s %s: %f units of %s, total value %s %f (%s)",
-		b.AssetSymbol,
-		b.AssetName,
-		b.UnitsAmount,
-		b.AssetTypeFull(),
-		b.UnitsCurrency,
-		b.UnitsValue,
-		b.SourceAccount,
-	)
 }
 
 type GetPortfolioResult struct {
-	Balances []Balance `json:"balances" jsonschema:"description:Array of portfolio balances across all requested accounts. Each balance represents a single asset holding with quantity and value information."`
+	Balances []portfolio.Balance       `json:"balances"           jsonschema:"description:Array of portfolio balances across all requested accounts. Each balance represents a single asset holding or cash balance (asset_type cash) with quantity and value information. Empty when group_by is set."`
+	GroupBy  string                    `json:"group_by,omitempty" jsonschema:"description:Dimension the groups are consolidated by"`
+	Groups   []portfolio.Group         `json:"groups,omitempty"   jsonschema:"description:Consolidated balances when group_by is set, with total units (by asset), value, weight within the currency and per-account breakdown"`
+	Totals   []portfolio.CurrencyTotal `json:"totals"             jsonschema:"description:Total securities, cash and overall values of all balances matching the filters per currency, including those outside the returned page"`
+	Gains    []portfolio.AccountGain   `json:"gains,omitempty"    jsonschema:"description:Cost basis and unrealized gain per account and currency of matching balances with known cost basis"`
+	Matched  int                       `json:"matched"            jsonschema:"description:Number of balances (or groups with group_by) matching the filters before limit and offset are applied"`
+	Offset   int                       `json:"offset"             jsonschema:"description:Number of matching balances (or groups with group_by) skipped before the returned ones"`
+
+	filtered bool // whether any filter argument was set
 }
 
 // Description returns a description of the GetPortfolioResult as MCP response text
 func (r GetPortfolioResult) Description() string {
-	if len(r.Balances) == 0 {
-		return "Portfolio is empty"
+	if r.Matched == 0 {
+		if !r.filtered {
+			return "Portfolio is empty"
+		}
+
+		return "No portfolio balances match the filters"
+	}
+
+	items, returned := "balances", len(r.Balances)
+	if r.GroupBy != "" {
+		items, returned = "groups by "+r.GroupBy, len(r.Groups)
+	}
+
+	if returned == 0 {
+		return fmt.Sprintf("No portfolio %s at offset %d, %d match the filters", items, r.Offset, r.Matched)
 	}
 
 	var descriptions []string
@@ -62,15 +82,41 @@ func (r GetPortfolioResult) Description() string {
 		descriptions = append(descriptions, fmt.Sprintf("- %s", balance.Description()))
 	}
 
-	return "Portfolio:\n" + strings.Join(descriptions, "\n")
+	for _, group := range r.Groups {
+		descriptions = append(descriptions, fmt.Sprintf("- %s", group.Description()))
+	}
+
+	for _, total := range r.Totals {
+		descriptions = append(descriptions, fmt.Sprintf("- Total %s: %f (securities %f, cash %f)",
+			total.Currency, total.TotalValue, total.SecuritiesValue, total.CashValue))
+	}
+
+	for _, gain := range r.Gains {
+		descriptions = append(descriptions, fmt.Sprintf("- Unrealized gain %s %s: %f (%+.2f%%) on cost basis %f of %d holdings",
+			gain.Account, gain.Currency, gain.UnrealizedGain, gain.UnrealizedGainPercent, gain.CostBasis, gain.Holdings))
+	}
+
+	header := "Portfolio:"
+	if r.GroupBy != "" {
+		header = fmt.Sprintf("Portfolio grouped by %s:", r.GroupBy)
+	}
+
+	if returned < r.Matched {
+		header = fmt.Sprintf("Portfolio, %s %d to %d of %d matching (totals include all matching balances):",
+			items, r.Offset+1, r.Offset+returned, r.Matched)
+	}
+
+	return header + "\n" + strings.Join(descriptions, "\n")
 }
 
 type ListAccountNamesArgs struct {
-	// This tool requires no parameters - it returns all configured account names
+	Tags []string `json:"tags,omitempty" jsonschema:"description:Only list accounts tagged with any of these tags, e.g. retirement or joint. Lists all configured accounts if omitted."`
 }
 
 type ListAccountNamesResult struct {
-	AccountNames []string `json:"account_names" jsonschema:"description:Array of configured account names. These names can be used as parameters when calling the get_portfolio tool to filter results by specific accounts."`
+	AccountNames []string            `json:"account_names"          jsonschema:"description:Array of configured account names. These names can be used as parameters when calling the get_portfolio tool to filter results by specific accounts."`
+	AccountTags  map[string][]string `json:"account_tags,omitempty" jsonschema:"description:Tags of the listed accounts by account name, for accounts with configured tags"`
+	Tags         []string            `json:"tags,omitempty"         jsonschema:"description:All tags configured on accounts and holdings, usable as the tags parameter of get_portfolio"`
 }
 
 // Description returns a description of the ListAccountNamesResult as MCP response text
@@ -79,5 +125,625 @@ func (r ListAccountNamesResult) Description() string {
 		return "No accounts configured"
 	}
 
-	return fmt.Sprintf("Available accounts: %s", strings.Join(r.AccountNames, ", "))
+	names := make([]string, len(r.AccountNames))
+	for i, name := range r.AccountNames {
+		names[i] = name
+		if tags := r.AccountTags[name]; len(tags) > 0 {
+			names[i] += " (" + strings.Join(tags, ", ") + ")"
+		}
+	}
+
+	description := fmt.Sprintf("Available accounts: %s", strings.Join(names, ", "))
+
+	if len(r.Tags) > 0 {
+		description += fmt.Sprintf("\nConfigured tags: %s", strings.Join(r.Tags, ", "))
+	}
+
+	return description
+}
+
+type ExportPortfolioArgs struct {
+	Format       string   `json:"format"              jsonschema:"description:Output format, one of csv, jsonl (JSON Lines), json (pretty-printed JSON array), beancount or hledger. Defaults to csv."`
+	AccountNames []string `json:"account_names"       jsonschema:"description:List of specific account names to export. If empty or omitted, exports all accounts."`
+	FromDate     string   `json:"from_date,omitempty" jsonschema:"description:Start date (YYYY-MM-DD, inclusive) of stored snapshots to export. If both from_date and to_date are omitted, current balances are fetched live."`
+	ToDate       string   `json:"to_date,omitempty"   jsonschema:"description:End date (YYYY-MM-DD, inclusive) of stored snapshots to export. If both from_date and to_date are omitted, current balances are fetched live."`
+}
+
+func (a ExportPortfolioArgs) options() (ExportOptions, error) {
+	opts := ExportOptions{
+		Format:       export.FormatCSV,
+		AccountNames: a.AccountNames,
+	}
+
+	if a.Format != "" {
+		format, err := export.ParseFormat(a.Format)
+		if err != nil {
+			return opts, err
+		}
+
+		opts.Format = format
+	}
+
+	var err error
+
+	opts.From, opts.To, err = parseDateRange(a.FromDate, a.ToDate)
+
+	return opts, err
+}
+
+// parseDateRange parses optional from_date and to_date arguments, leaving omitted dates zero
+func parseDateRange(from, to string) (fromDate, toDate time.Time, err error) {
+	if from != "" {
+		if fromDate, err = portfolio.ParseDate(from); err != nil {
+			return fromDate, toDate, fmt.Errorf("invalid from_date: %w", err)
+		}
+	}
+
+	if to != "" {
+		if toDate, err = portfolio.ParseDate(to); err != nil {
+			return fromDate, toDate, fmt.Errorf("invalid to_date: %w", err)
+		}
+	}
+
+	return fromDate, toDate, nil
+}
+
+type ExportPortfolioResult struct {
+	Format  string `json:"format"  jsonschema:"description:Format of the exported content"`
+	Content string `json:"content" jsonschema:"description:Exported balances as text in the requested format"`
+}
+
+type GetAccountInfoArgs struct {
+	AccountNames []string `json:"account_names" jsonschema:"description:List of specific account names to describe. If empty or omitted, describes all configured accounts."`
+}
+
+type SubAccountInfo struct {
+	SubAccount  string                    `json:"sub_account" jsonschema:"description:Securities sub-account or RDN account number, empty when the source does not provide one"`
+	Participant string                    `json:"participant" jsonschema:"description:Broker, asset manager or bank administering the sub-account"`
+	Holdings    int                       `json:"holdings"    jsonschema:"description:Number of holdings in the sub-account"`
+	Totals      []portfolio.CurrencyTotal `json:"totals"      jsonschema:"description:Total value of the sub-account per currency"`
+}
+
+type AccountInfo struct {
+	AccountName  string           `json:"account_name"            jsonschema:"description:Configured account name"`
+	SourceType   string           `json:"source_type,omitempty"   jsonschema:"description:Type of data source of the account"`
+	InvestorID   string           `json:"investor_id,omitempty"   jsonschema:"description:KSEI Single Investor Identification (SID) number"`
+	InvestorName string           `json:"investor_name,omitempty" jsonschema:"description:Investor name registered in KSEI"`
+	SubAccounts  []SubAccountInfo `json:"sub_accounts"            jsonschema:"description:Sub-accounts holding the account balances, grouped by participant"`
+}
+
+type GetAccountInfoResult struct {
+	Accounts []AccountInfo `json:"accounts" jsonschema:"description:Information about each requested account"`
+}
+
+// Description returns a description of the GetAccountInfoResult as MCP response text
+func (r GetAccountInfoResult) Description() string {
+	if len(r.Accounts) == 0 {
+		return "No accounts found"
+	}
+
+	var lines []string
+
+	for _, a := range r.Accounts {
+		line := fmt.Sprintf("Account %s", a.AccountName)
+		if a.SourceType != "" {
+			line += fmt.Sprintf(" (%s)", a.SourceType)
+		}
+
+		if a.InvestorID != "" {
+			line += fmt.Sprintf(", SID %s, investor %s", a.InvestorID, a.InvestorName)
+		}
+
+		lines = append(lines, line+":")
+
+		for _, s := range a.SubAccounts {
+			var totals []string
+			for _, t := range s.Totals {
+				totals = append(totals, fmt.Sprintf("%s %f", t.Currency, t.TotalValue))
+			}
+
+			lines = append(lines, fmt.Sprintf("- %s %s: %d holdings, total value %s",
+				cmp.Or(s.Participant, "unknown participant"),
+				s.SubAccount,
+				s.Holdings,
+				strings.Join(totals, ", ")))
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+type SuggestRebalanceArgs struct {
+	AccountNames     []string `json:"account_names"               jsonschema:"description:List of specific account names to include. If empty or omitted, includes all configured accounts."`
+	Currency         string   `json:"currency,omitempty"          jsonschema:"description:Currency of the holdings to rebalance, defaults to IDR. Holdings in other currencies are ignored."`
+	TargetAllocation string   `json:"target_allocation,omitempty" jsonschema:"description:Target weights in percent overriding the configured targets, e.g. equity=50,mutual_fund/money_market_fund=20,symbol:BBCA=10. Keys are asset types, asset_type/sub_type or symbol:SYMBOL."`
+	Tolerance        *float64 `json:"tolerance,omitempty"         jsonschema:"description:Allowed deviation from target in percentage points before suggesting a trade, overriding the configured tolerance"`
+}
+
+type SuggestRebalanceResult struct {
+	Plan rebalance.Plan `json:"plan" jsonschema:"description:Current versus target allocation with suggested buy and sell amounts"`
+}
+
+// Description returns a description of the SuggestRebalanceResult as MCP response text
+func (r SuggestRebalanceResult) Description() string {
+	p := r.Plan
+	if p.TotalValue == 0 {
+		return fmt.Sprintf("No holdings in %s to rebalance", p.Currency)
+	}
+
+	lines := []string{fmt.Sprintf("Rebalance plan for total value %s %f (tolerance %g percentage points):", p.Currency, p.TotalValue, p.Tolerance)}
+
+	for _, s := range p.Suggestions {
+		line := fmt.Sprintf("- %s: current %.2f%%, target %.2f%%, deviation %+.2f, %s",
+			s.Target, s.CurrentWeight, s.TargetWeight, s.Deviation, s.Action)
+
+		if s.Action == "buy" || s.Action == "sell" {
+			line += fmt.Sprintf(" %s %f", p.Currency, math.Abs(s.Amount))
+		}
+
+		if s.Lots > 0 {
+			line += fmt.Sprintf(" (%d lots, %g shares)", s.Lots, s.Shares)
+		}
+
+		lines = append(lines, line)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+type SearchAssetsArgs struct {
+	Query string `json:"query"           jsonschema:"description:Symbol, fund code, partial name, acronym or investment manager to look up, e.g. BCA or Sucorinvest money market"`
+	Scope string `json:"scope,omitempty" jsonschema:"description:Where to search: all (default), holdings for assets held in configured accounts, or catalogue for the KSEI mutual fund catalogue"`
+	Limit int    `json:"limit,omitempty" jsonschema:"description:Maximum number of results, defaults to 10"`
+}
+
+type SearchAssetsResult struct {
+	Query  string         `json:"query"  jsonschema:"description:The searched query"`
+	Assets []search.Asset `json:"assets" jsonschema:"description:Matching assets ordered by match score, held assets before catalogue entries"`
+}
+
+// Description returns a description of the SearchAssetsResult as MCP response text
+func (r SearchAssetsResult) Description() string {
+	if len(r.Assets) == 0 {
+		return fmt.Sprintf("No assets found matching %q", r.Query)
+	}
+
+	lines := []string{fmt.Sprintf("Assets matching %q:", r.Query)}
+
+	for _, a := range r.Assets {
+		line := fmt.Sprintf("- %s: %s", a.Symbol, a.Name)
+
+		details := []string{cmp.Or(a.AssetType, "unknown type")}
+		if a.FundType != "" {
+			details = append(details, a.FundType)
+		}
+
+		if a.InvestmentManager != "" {
+			details = append(details, a.InvestmentManager)
+		}
+
+		if len(a.Accounts) > 0 {
+			details = append(details, "held in "+strings.Join(a.Accounts, ", "))
+		} else {
+			details = append(details, "not held")
+		}
+
+		lines = append(lines, line+" ("+strings.Join(details, "; ")+")")
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+type SetCostBasisArgs struct {
+	AccountName  string   `json:"account_name"            jsonschema:"description:Account holding the asset"`
+	Symbol       string   `json:"symbol"                  jsonschema:"description:Asset symbol or fund code"`
+	AveragePrice *float64 `json:"average_price,omitempty" jsonschema:"description:Average purchase price per unit including fees"`
+	TotalCost    *float64 `json:"total_cost,omitempty"    jsonschema:"description:Total purchase cost of the units currently held, converted to an average price using the live balance. Use instead of average_price."`
+	Remove       bool     `json:"remove,omitempty"        jsonschema:"description:Remove the entered cost basis instead of setting it, falling back to the transactions file if configured"`
+}
+
+type SetCostBasisResult struct {
+	Entry   *costbasis.Entry `json:"entry,omitempty"   jsonschema:"description:The stored cost basis entry"`
+	Removed bool             `json:"removed,omitempty" jsonschema:"description:Whether an entry was removed"`
+}
+
+// Description returns a description of the SetCostBasisResult as MCP response text
+func (r SetCostBasisResult) Description() string {
+	if r.Entry == nil {
+		if r.Removed {
+			return "Cost basis entry removed"
+		}
+
+		return "No cost basis entry to remove"
+	}
+
+	return fmt.Sprintf("Cost basis of %s in %s set to average price %f per unit", r.Entry.Symbol, r.Entry.Account, r.Entry.AveragePrice)
+}
+
+type ListTransactionsArgs struct {
+	AccountNames []string `json:"account_names"       jsonschema:"description:List of specific account names to list transactions of. If empty or omitted, includes all configured accounts."`
+	Symbols      []string `json:"symbols,omitempty"   jsonschema:"description:Only include transactions of these asset symbols or fund codes"`
+	Types        []string `json:"types,omitempty"     jsonschema:"description:Only include these transaction types: buy, sell, subscription or redemption"`
+	FromDate     string   `json:"from_date,omitempty" jsonschema:"description:Start date (YYYY-MM-DD, inclusive) of transactions to list"`
+	ToDate       string   `json:"to_date,omitempty"   jsonschema:"description:End date (YYYY-MM-DD, inclusive) of transactions to list"`
+	Limit        int      `json:"limit,omitempty"     jsonschema:"description:Maximum number of most recent transactions to return. Returns all if omitted."`
+}
+
+type ListTransactionsResult struct {
+	Transactions []transaction.Transaction `json:"transactions" jsonschema:"description:Transactions ordered from most recent, either entered in the transactions file (origin manual) or inferred from unit changes between daily snapshots (origin inferred)"`
+	Matched      int                       `json:"matched"      jsonschema:"description:Number of transactions matching the filters before limit is applied"`
+}
+
+// Description returns a description of the ListTransactionsResult as MCP response text
+func (r ListTransactionsResult) Description() string {
+	if len(r.Transactions) == 0 {
+		return "No transactions found"
+	}
+
+	lines := []string{fmt.Sprintf("Transactions, %d of %d matching, most recent first:", len(r.Transactions), r.Matched)}
+
+	for _, t := range r.Transactions {
+		line := fmt.Sprintf("- %s %s %s %f units of %s at %f, value %f (%s)",
+			t.Date.Format(time.DateOnly), t.Account, t.Type, t.Units, t.Symbol, t.Price, t.Value(), t.Origin)
+
+		if t.Fee != 0 {
+			line += fmt.Sprintf(", fee %f", t.Fee)
+		}
+
+		if t.Note != "" {
+			line += ", note: " + t.Note
+		}
+
+		lines = append(lines, line)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+type GetPerformanceArgs struct {
+	AccountNames []string `json:"account_names"       jsonschema:"description:List of specific account names to measure. If empty or omitted, measures all configured accounts."`
+	FromDate     string   `json:"from_date,omitempty" jsonschema:"description:Start date (YYYY-MM-DD, inclusive). Defaults to the first stored snapshot."`
+	ToDate       string   `json:"to_date,omitempty"   jsonschema:"description:End date (YYYY-MM-DD, inclusive). Defaults to the last stored snapshot."`
+	Currency     string   `json:"currency,omitempty"  jsonschema:"description:Currency of the holdings to measure, defaults to IDR. Holdings in other currencies are ignored."`
+}
+
+type GetPerformanceResult struct {
+	Currency  string               `json:"currency"  jsonschema:"description:Currency of the measured holdings"`
+	Overall   performance.Result   `json:"overall"   jsonschema:"description:Performance of all selected accounts together"`
+	Breakdown []performance.Result `json:"breakdown" jsonschema:"description:Performance per account and per asset type"`
+}
+
+// Description returns a description of the GetPerformanceResult as MCP response text
+func (r GetPerformanceResult) Description() string {
+	lines := []string{fmt.Sprintf("Performance of %s holdings (cash excluded, buys and sells treated as cash flows):", r.Currency)}
+
+	for _, p := range append([]performance.Result{r.Overall}, r.Breakdown...) {
+		line := fmt.Sprintf("- %s from %s to %s: value %f to %f, net flows %f, gain %f, TWR %.2f%%",
+			p.Scope, p.From, p.To, p.StartValue, p.EndValue, p.NetFlows, p.Gain, p.TWR)
+
+		if p.TWRAnnualized != nil {
+			line += fmt.Sprintf(" (%.2f%% annualized)", *p.TWRAnnualized)
+		}
+
+		if p.XIRR != nil {
+			line += fmt.Sprintf(", XIRR %.2f%%", *p.XIRR)
+		}
+
+		line += fmt.Sprintf(", %d periods, %d days without snapshot", p.Periods, p.MissingDays)
+
+		if p.CarriedDays > 0 {
+			line += fmt.Sprintf(", %d days with accounts carried from the previous snapshot", p.CarriedDays)
+		}
+
+		lines = append(lines, line)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+type CompareToBenchmarkArgs struct {
+	Benchmarks   []string `json:"benchmarks"          jsonschema:"description:Names of the configured benchmarks to compare against. If empty or omitted, compares against all configured benchmarks."`
+	AccountNames []string `json:"account_names"       jsonschema:"description:List of specific account names to measure. If empty or omitted, measures all configured accounts."`
+	FromDate     string   `json:"from_date,omitempty" jsonschema:"description:Start date (YYYY-MM-DD, inclusive). Defaults to the first stored snapshot."`
+	ToDate       string   `json:"to_date,omitempty"   jsonschema:"description:End date (YYYY-MM-DD, inclusive). Defaults to the last stored snapshot."`
+	Currency     string   `json:"currency,omitempty"  jsonschema:"description:Currency of the holdings to measure, defaults to IDR. Holdings in other currencies are ignored."`
+}
+
+type CompareToBenchmarkResult struct {
+	Currency    string                 `json:"currency"    jsonschema:"description:Currency of the measured holdings"`
+	Comparisons []benchmark.Comparison `json:"comparisons" jsonschema:"description:Comparison against each benchmark"`
+	Uncovered   []string               `json:"uncovered"   jsonschema:"description:Benchmarks without values on at least two snapshot dates in the range"`
+}
+
+// Description returns a description of the CompareToBenchmarkResult as MCP response text
+func (r CompareToBenchmarkResult) Description() string {
+	lines := []string{fmt.Sprintf("Portfolio of %s holdings compared to benchmarks (cash excluded, rebased to 100):", r.Currency)}
+
+	for _, c := range r.Comparisons {
+		outcome := "outperformed"
+		if c.Excess < 0 {
+			outcome = "underperformed"
+		}
+
+		lines = append(lines, fmt.Sprintf("- %s from %s to %s: portfolio %.2f%%, benchmark %.2f%%, %s by %.2f percentage points over %d snapshot dates",
+			c.Benchmark, c.From, c.To, c.PortfolioReturn, c.BenchmarkReturn, outcome, math.Abs(c.Excess), len(c.Points)))
+	}
+
+	if len(r.Uncovered) > 0 {
+		lines = append(lines, "Not enough overlapping dates to compare: "+strings.Join(r.Uncovered, ", "))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+type ImportPricesArgs struct {
+	Path          string `json:"path,omitempty"           jsonschema:"description:Path of a CSV file inside the server data directory (DATA_DIR), relative to it. Use either path or content."`
+	Content       string `json:"content,omitempty"        jsonschema:"description:CSV content to import, with a header row. Use either path or content."`
+	Symbol        string `json:"symbol,omitempty"         jsonschema:"description:Symbol of all rows, for CSV without a symbol column such as the NAV history of a single fund"`
+	FromSnapshots bool   `json:"from_snapshots,omitempty" jsonschema:"description:Also record prices implied by the value per unit of all stored snapshots. New fetches record them automatically, use this once for snapshots recorded before."`
+}
+
+type ImportPricesResult struct {
+	Read     int      `json:"read"     jsonschema:"description:Number of prices read from the CSV and snapshots"`
+	Stored   int      `json:"stored"   jsonschema:"description:Number of prices added or changed in the price store"`
+	Symbols  []string `json:"symbols"  jsonschema:"description:Symbols of the prices read"`
+	Snapshot int      `json:"snapshot" jsonschema:"description:Number of stored snapshots prices were implied from"`
+}
+
+// Description returns a description of the ImportPricesResult as MCP response text
+func (r ImportPricesResult) Description() string {
+	text := fmt.Sprintf("Read %d prices of %d symbols, stored %d new or changed prices", r.Read, len(r.Symbols), r.Stored)
+
+	if r.Snapshot > 0 {
+		text += fmt.Sprintf(" (including prices implied by %d snapshots)", r.Snapshot)
+	}
+
+	if len(r.Symbols) > 0 {
+		text += ": " + strings.Join(r.Symbols, ", ")
+	}
+
+	return text
+}
+
+type GetPriceHistoryArgs struct {
+	Symbol   string `json:"symbol"              jsonschema:"description:Asset symbol or fund code"`
+	FromDate string `json:"from_date,omitempty" jsonschema:"description:Start date (YYYY-MM-DD, inclusive). Defaults to the first stored price."`
+	ToDate   string `json:"to_date,omitempty"   jsonschema:"description:End date (YYYY-MM-DD, inclusive). Defaults to the last stored price."`
+}
+
+type GetPriceHistoryResult struct {
+	Symbol        string        `json:"symbol"         jsonschema:"description:Asset symbol or fund code"`
+	Prices        []price.Price `json:"prices"         jsonschema:"description:Stored prices in ascending date order"`
+	Change        float64       `json:"change"         jsonschema:"description:Last price minus first price in the range"`
+	ChangePercent float64       `json:"change_percent" jsonschema:"description:Price change in percent of the first price"`
+}
+
+// Description returns a description of the GetPriceHistoryResult as MCP response text
+func (r GetPriceHistoryResult) Description() string {
+	if len(r.Prices) == 0 {
+		return fmt.Sprintf("No stored prices of %s in the date range", r.Symbol)
+	}
+
+	first, last := r.Prices[0], r.Prices[len(r.Prices)-1]
+	lines := []string{fmt.Sprintf("%d prices of %s from %s to %s, %s %f to %f (%+.2f%%):",
+		len(r.Prices), r.Symbol, first.Date.Format(time.DateOnly), last.Date.Format(time.DateOnly), last.Currency, first.Price, last.Price, r.ChangePercent)}
+
+	for _, p := range r.Prices {
+		lines = append(lines, fmt.Sprintf("- %s: %f %s (%s)", p.Date.Format(time.DateOnly), p.Price, p.Currency, p.Source))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+type RecordIncomeArgs struct {
+	AccountName string  `json:"account_name"       jsonschema:"description:Account holding the asset"`
+	Symbol      string  `json:"symbol"             jsonschema:"description:Asset symbol or fund code paying the income"`
+	Type        string  `json:"type"               jsonschema:"description:dividend, coupon, interest or distribution"`
+	Date        string  `json:"date"               jsonschema:"description:Payment date (YYYY-MM-DD). Future dates record expected income."`
+	Amount      float64 `json:"amount,omitempty"   jsonschema:"description:Gross amount before tax"`
+	Tax         float64 `json:"tax,omitempty"      jsonschema:"description:Tax withheld from the gross amount, e.g. 10% of dividends of Indonesian companies"`
+	Currency    string  `json:"currency,omitempty" jsonschema:"description:Currency of the amount, defaults to IDR"`
+	Note        string  `json:"note,omitempty"     jsonschema:"description:Free-text note"`
+	Remove      bool    `json:"remove,omitempty"   jsonschema:"description:Remove the recorded payment of the same date, account, symbol and type instead of recording it"`
+}
+
+type RecordIncomeResult struct {
+	Entry   *income.Entry `json:"entry,omitempty"   jsonschema:"description:The recorded payment"`
+	Removed bool          `json:"removed,omitempty" jsonschema:"description:Whether a payment was removed"`
+}
+
+// Description returns a description of the RecordIncomeResult as MCP response text
+func (r RecordIncomeResult) Description() string {
+	if r.Entry == nil {
+		if r.Removed {
+			return "Income payment removed"
+		}
+
+		return "No recorded income payment to remove"
+	}
+
+	return fmt.Sprintf("Recorded %s of %s in %s on %s: %s %f gross, %f net",
+		r.Entry.Type, r.Entry.Symbol, r.Entry.Account, r.Entry.Date.Format(time.DateOnly), r.Entry.Currency, r.Entry.Amount, r.Entry.Net())
+}
+
+type GetIncomeArgs struct {
+	AccountNames     []string `json:"account_names"               jsonschema:"description:List of specific account names to summarize. If empty or omitted, summarizes all configured accounts."`
+	Symbols          []string `json:"symbols,omitempty"           jsonschema:"description:Only include income of these symbols"`
+	FromDate         string   `json:"from_date,omitempty"         jsonschema:"description:Start date (YYYY-MM-DD, inclusive). Defaults to one year ago."`
+	ToDate           string   `json:"to_date,omitempty"           jsonschema:"description:End date (YYYY-MM-DD, inclusive). Defaults to one year from today."`
+	ExcludeProjected bool     `json:"exclude_projected,omitempty" jsonschema:"description:Leave out income projected from past payments, keeping recorded expected payments"`
+	IncludeEntries   bool     `json:"include_entries,omitempty"   jsonschema:"description:Also return every payment in the range"`
+}
+
+type GetIncomeResult struct {
+	From    string         `json:"from"              jsonschema:"description:Start of the summarized range (YYYY-MM-DD)"`
+	To      string         `json:"to"                jsonschema:"description:End of the summarized range (YYYY-MM-DD)"`
+	Summary income.Summary `json:"summary"           jsonschema:"description:Net income received and expected, overall, per month and per asset"`
+	Entries []income.Entry `json:"entries,omitempty" jsonschema:"description:Payments in the range ordered by date, when requested"`
+}
+
+// Description returns a description of the GetIncomeResult as MCP response text
+func (r GetIncomeResult) Description() string {
+	if len(r.Summary.Totals) == 0 {
+		return fmt.Sprintf("No income recorded from %s to %s", r.From, r.To)
+	}
+
+	lines := []string{fmt.Sprintf("Income from %s to %s, net of tax:", r.From, r.To)}
+
+	for _, t := range r.Summary.Totals {
+		lines = append(lines, fmt.Sprintf("- Total %s: received %f (tax %f), projected %f, %d payments", t.Currency, t.Received, t.Tax, t.Projected, t.Payments))
+	}
+
+	lines = append(lines, "", "Per month:")
+	for _, m := range r.Summary.Months {
+		lines = append(lines, fmt.Sprintf("- %s %s: received %f, projected %f", m.Month, m.Currency, m.Received, m.Projected))
+	}
+
+	lines = append(lines, "", "Per asset:")
+	for _, a := range r.Summary.Assets {
+		line := fmt.Sprintf("- %s in %s (%s): received %s %f, projected %f", a.Symbol, a.Account, a.Type, a.Currency, a.Received, a.Projected)
+		if a.LastPaid != "" {
+			line += ", last paid " + a.LastPaid
+		}
+
+		lines = append(lines, line)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+type UpcomingMaturitiesArgs struct {
+	AccountNames []string `json:"account_names"         jsonschema:"description:List of specific account names to check. If empty or omitted, checks all configured accounts."`
+	WithinDays   int      `json:"within_days,omitempty" jsonschema:"description:Only list bonds maturing within this many days from today, defaults to 365"`
+}
+
+type UpcomingMaturitiesResult struct {
+	Until      string          `json:"until"      jsonschema:"description:Last maturity date included (YYYY-MM-DD)"`
+	Maturities []bond.Maturity `json:"maturities" jsonschema:"description:Bond holdings maturing in the period, earliest first"`
+	Unknown    []string        `json:"unknown"    jsonschema:"description:Symbols of bonds held but missing from the bond catalogue"`
+}
+
+// Description returns a description of the UpcomingMaturitiesResult as MCP response text
+func (r UpcomingMaturitiesResult) Description() string {
+	lines := []string{fmt.Sprintf("%d bond holdings maturing until %s:", len(r.Maturities), r.Until)}
+
+	for _, m := range r.Maturities {
+		lines = append(lines, fmt.Sprintf("- %s %s in %s matures %s (%d days): principal %s %f, current value %f, coupon %.3f%%, next coupon %s, remaining coupons %f",
+			m.Symbol, m.Name, m.Account, m.MaturityDate, m.DaysToMaturity, m.Currency, m.Principal, m.CurrentValue, m.CouponRate, m.NextCouponDate, m.RemainingCoupons))
+	}
+
+	if len(r.Unknown) > 0 {
+		lines = append(lines, "Bonds missing from the bond catalogue (BONDS_FILE): "+strings.Join(r.Unknown, ", "))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+type GetRiskReportArgs struct {
+	AccountNames     []string           `json:"account_names"                jsonschema:"description:List of specific account names to include. If empty or omitted, includes all configured accounts."`
+	Currency         string             `json:"currency,omitempty"           jsonschema:"description:Currency of the report, defaults to IDR"`
+	FxRates          map[string]float64 `json:"fx_rates,omitempty"           jsonschema:"description:Value of one unit of other currencies in the report currency, e.g. {\"USD\": 16300}. Balances in currencies without a rate are left out."`
+	TopN             int                `json:"top_n,omitempty"              jsonschema:"description:Number of largest positions to list and sum, defaults to 5"`
+	MaxPositionShare float64            `json:"max_position_share,omitempty" jsonschema:"description:Share in percent of holdings value a single position may reach before a warning, defaults to 20"`
+	MaxSectorShare   float64            `json:"max_sector_share,omitempty"   jsonschema:"description:Share in percent of holdings value a single sector or issuer may reach before a warning, defaults to 40"`
+}
+
+type GetRiskReportResult struct {
+	risk.Report
+}
+
+// Description returns a description of the GetRiskReportResult as MCP response text
+func (r GetRiskReportResult) Description() string {
+	lines := []string{fmt.Sprintf("Risk report of %d positions worth %s %f (cash excluded):", r.Positions, r.Currency, r.HoldingsValue)}
+
+	if r.Largest != nil {
+		lines = append(lines, fmt.Sprintf("- Largest position: %s %s, %.2f%%", r.Largest.Symbol, r.Largest.Name, r.Largest.Share))
+	}
+
+	lines = append(lines,
+		fmt.Sprintf("- Top %d positions: %.2f%%", len(r.Top), r.TopShare),
+		fmt.Sprintf("- Herfindahl index: %.0f (%.1f effective positions)", r.Herfindahl, r.EffectivePositions),
+	)
+
+	if len(r.Top) > 0 {
+		lines = append(lines, "", "Top positions:")
+		for _, p := range r.Top {
+			lines = append(lines, fmt.Sprintf("- %s: %f (%.2f%%) in %s", p.Symbol, p.Value, p.Share, strings.Join(p.Accounts, ", ")))
+		}
+	}
+
+	for _, group := range []struct {
+		name      string
+		exposures []risk.Exposure
+	}{{"Sectors", r.Sectors}, {"Issuers", r.Issuers}, {"Currencies (cash included)", r.Currencies}} {
+		if len(group.exposures) == 0 {
+			continue
+		}
+
+		lines = append(lines, "", group.name+":")
+		for _, e := range group.exposures {
+			lines = append(lines, fmt.Sprintf("- %s: %f (%.2f%%), %d holdings", e.Key, e.Value, e.Share, e.Holdings))
+		}
+	}
+
+	if len(r.Unconverted) > 0 {
+		lines = append(lines, "", "Left out for missing exchange rates: "+strings.Join(r.Unconverted, ", "))
+	}
+
+	if len(r.Warnings) > 0 {
+		lines = append(lines, "", "Warnings:")
+		for _, w := range r.Warnings {
+			lines = append(lines, "- "+w)
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+type GetNetWorthTimelineArgs struct {
+	AccountNames []string           `json:"account_names"        jsonschema:"description:List of specific account names to include. If empty or omitted, includes all configured accounts."`
+	FromDate     string             `json:"from_date,omitempty"  jsonschema:"description:Start date (YYYY-MM-DD, inclusive). Defaults to the first stored snapshot."`
+	ToDate       string             `json:"to_date,omitempty"    jsonschema:"description:End date (YYYY-MM-DD, inclusive). Defaults to the last stored snapshot."`
+	Resolution   string             `json:"resolution,omitempty" jsonschema:"description:Period of each point: day, week (starting Monday), month or auto (default), which picks days for up to three months, weeks for up to two years and months beyond"`
+	Fill         string             `json:"fill,omitempty"       jsonschema:"description:Rule for periods without a snapshot: previous (default) carries the last value forward, linear interpolates between the surrounding snapshots, none leaves the period out"`
+	Currency     string             `json:"currency,omitempty"   jsonschema:"description:Currency to convert all values to, defaults to IDR"`
+	FxRates      map[string]float64 `json:"fx_rates,omitempty"   jsonschema:"description:Value of one unit of other currencies in the timeline currency, e.g. {\"USD\": 16300}, applied to all dates. Without a rate, imported prices of currency pairs such as USDIDR are used; balances in currencies without either are left out."`
+}
+
+type GetNetWorthTimelineResult struct {
+	networth.Timeline
+}
+
+// Description returns a description of the GetNetWorthTimelineResult as MCP response text
+func (r GetNetWorthTimelineResult) Description() string {
+	if len(r.Points) == 0 {
+		return "No stored snapshots of the selected accounts in the date range"
+	}
+
+	lines := []string{
+		fmt.Sprintf("Net worth in %s per %s from %s to %s: %f to %f, change %f (%+.2f%%)",
+			r.Currency, r.Resolution, r.From, r.To, r.StartValue, r.EndValue, r.Change, r.ChangePercent),
+		fmt.Sprintf("High %f on %s, low %f on %s, %d points filled by rule %s", r.High.Value, r.High.Date, r.Low.Value, r.Low.Date, r.Filled, r.Fill),
+		"",
+	}
+
+	for _, p := range r.Points {
+		line := fmt.Sprintf("- %s: %f (%+.2f%%)", p.Date, p.Value, p.ChangePercent)
+
+		if p.Filled {
+			line += " filled"
+		}
+
+		if len(p.CarriedAccounts) > 0 {
+			line += ", carried forward: " + strings.Join(p.CarriedAccounts, ", ")
+		}
+
+		lines = append(lines, line)
+	}
+
+	if len(r.Unconverted) > 0 {
+		lines = append(lines, "", "Left out for missing exchange rates: "+strings.Join(r.Unconverted, ", "))
+	}
+
+	return strings.Join(lines, "\n")
 }
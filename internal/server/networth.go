@@ -0,0 +1,101 @@
+package server
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/chickenzord/portosync/internal/networth"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// exchangeRates returns the value of one unit of a currency in the target currency at a date.
+// Fixed rates take precedence over imported prices of currency pairs such as USDIDR,
+// or the inverse pair IDRUSD, in the price store.
+func (m *MCP) exchangeRates(target string, fixed map[string]float64) func(currency string, date time.Time) (float64, bool) {
+	rates := make(map[string]float64, len(fixed))
+	for currency, rate := range fixed {
+		rates[strings.ToUpper(currency)] = rate
+	}
+
+	return func(currency string, date time.Time) (float64, bool) {
+		if rate, ok := rates[currency]; ok {
+			return rate, true
+		}
+
+		if m.prices == nil {
+			return 0, false
+		}
+
+		if p, ok, err := m.prices.At(currency+target, date); err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading %s%s exchange rate: %v\n", currency, target, err)
+		} else if ok && p.Price > 0 {
+			return p.Price, true
+		}
+
+		if p, ok, err := m.prices.At(target+currency, date); err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading %s%s exchange rate: %v\n", target, currency, err)
+		} else if ok && p.Price > 0 {
+			return 1 / p.Price, true
+		}
+
+		return 0, false
+	}
+}
+
+// handleGetNetWorthTimeline handles the get_net_worth_timeline MCP tool
+func (m *MCP) handleGetNetWorthTimeline(ctx context.Context, req *mcp.CallToolRequest, args GetNetWorthTimelineArgs) (*mcp.CallToolResult, GetNetWorthTimelineResult, error) {
+	result := GetNetWorthTimelineResult{}
+
+	if m.snapshots == nil {
+		return errorResult(errSnapshotsDisabled.Error()), result, nil
+	}
+
+	resolution := strings.ToLower(cmp.Or(args.Resolution, networth.ResolutionAuto))
+	if !slices.Contains(networth.Resolutions, resolution) {
+		return errorResult(fmt.Sprintf("Invalid resolution %q, supported resolutions are %s", args.Resolution, strings.Join(networth.Resolutions, ", "))), result, nil
+	}
+
+	fill := strings.ToLower(cmp.Or(args.Fill, networth.FillPrevious))
+	if !slices.Contains(networth.Fills, fill) {
+		return errorResult(fmt.Sprintf("Invalid fill %q, supported fill rules are %s", args.Fill, strings.Join(networth.Fills, ", "))), result, nil
+	}
+
+	from, to, err := parseDateRange(args.FromDate, args.ToDate)
+	if err != nil {
+		return errorResult(err.Error()), result, nil
+	}
+
+	accounts := m.selectAccounts(args.AccountNames)
+	if accounts.empty() {
+		return accountsNotFoundResult(m.getAccountNames()), result, nil
+	}
+
+	// Earlier snapshots are needed to carry values forward into the start of the range
+	snapshots, err := m.snapshots.Range(time.Time{}, to)
+	if err != nil {
+		return nil, result, err
+	}
+
+	currency := strings.ToUpper(cmp.Or(args.Currency, "IDR"))
+
+	result.Timeline = networth.Build(snapshots, from, to, networth.Options{
+		Currency:   currency,
+		Resolution: resolution,
+		Fill:       fill,
+		Accounts:   accounts.names(),
+		Rate:       m.exchangeRates(currency, args.FxRates),
+	})
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: result.Description(),
+			},
+		},
+	}, result, nil
+}
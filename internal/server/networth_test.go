@@ -0,0 +1,96 @@
+package server
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/chickenzord/goksei"
+	"github.com/chickenzord/portosync/internal/portfolio"
+	"github.com/chickenzord/portosync/internal/price"
+	"github.com/chickenzord/portosync/internal/snapshot"
+	"github.com/chickenzord/portosync/internal/source"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMCP_handleGetNetWorthTimeline(t *testing.T) {
+	dir := t.TempDir()
+
+	snapshots, err := snapshot.NewStore(filepath.Join(dir, "snapshots"))
+	require.NoError(t, err)
+
+	prices, err := price.NewStore(filepath.Join(dir, "prices"))
+	require.NoError(t, err)
+
+	for _, s := range []portfolio.Snapshot{
+		{Date: mustDate(t, "2024-01-01"), Balances: []portfolio.Balance{
+			{SourceAccount: "personal", AssetSymbol: "BBCA", AssetType: "equity", UnitsAmount: 100, UnitsValue: 1000, UnitsCurrency: "IDR"},
+			{SourceAccount: "broker", AssetSymbol: "AAPL", AssetType: "equity", UnitsAmount: 1, UnitsValue: 2, UnitsCurrency: "USD"},
+		}},
+		{Date: mustDate(t, "2024-01-03"), Balances: []portfolio.Balance{
+			{SourceAccount: "personal", AssetSymbol: "BBCA", AssetType: "equity", UnitsAmount: 100, UnitsValue: 1100, UnitsCurrency: "IDR"},
+		}},
+	} {
+		require.NoError(t, snapshots.Save(s))
+	}
+
+	_, err = prices.Put([]price.Price{
+		{Symbol: "USDIDR", Date: mustDate(t, "2024-01-01"), Price: 100, Currency: "IDR", Source: price.SourceImported},
+	})
+	require.NoError(t, err)
+
+	mcpServer := &MCP{
+		kseiClients: make(map[string]*goksei.Client),
+		sources: map[string]source.Source{
+			"personal": source.NewManual(filepath.Join(dir, "personal.yaml")),
+			"broker":   source.NewManual(filepath.Join(dir, "broker.yaml")),
+		},
+		snapshots: snapshots,
+		prices:    prices,
+	}
+
+	ctx := context.Background()
+	req := &mcp.CallToolRequest{}
+
+	result, data, err := mcpServer.handleGetNetWorthTimeline(ctx, req, GetNetWorthTimelineArgs{})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	assert.Equal(t, "IDR", data.Currency)
+	assert.Equal(t, "day", data.Resolution)
+	require.Len(t, data.Points, 3)
+	assert.Equal(t, 1200.0, data.Points[0].Value)
+	assert.True(t, data.Points[1].Filled)
+	assert.Equal(t, 1300.0, data.Points[2].Value)
+	assert.Equal(t, []string{"broker"}, data.Points[2].CarriedAccounts)
+	assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, "- 2024-01-03: 1300.000000 (+8.33%), carried forward: broker")
+
+	// Fixed rates take precedence over imported ones
+	_, data, err = mcpServer.handleGetNetWorthTimeline(ctx, req, GetNetWorthTimelineArgs{FxRates: map[string]float64{"usd": 50}, Fill: "none"})
+	require.NoError(t, err)
+	require.Len(t, data.Points, 2)
+	assert.Equal(t, 1100.0, data.StartValue)
+
+	// IDR converts to USD through the inverse pair
+	_, data, err = mcpServer.handleGetNetWorthTimeline(ctx, req, GetNetWorthTimelineArgs{Currency: "usd", AccountNames: []string{"personal"}, Resolution: "Week"})
+	require.NoError(t, err)
+	require.Len(t, data.Points, 1)
+	assert.Equal(t, 11.0, data.EndValue)
+
+	for _, args := range []GetNetWorthTimelineArgs{
+		{Resolution: "year"},
+		{Fill: "zero"},
+		{FromDate: "soon"},
+		{AccountNames: []string{"unknown"}},
+	} {
+		result, _, err := mcpServer.handleGetNetWorthTimeline(ctx, req, args)
+		require.NoError(t, err)
+		assert.True(t, result.IsError, "%+v", args)
+	}
+
+	mcpServer.snapshots = nil
+	result, _, err = mcpServer.handleGetNetWorthTimeline(ctx, req, GetNetWorthTimelineArgs{})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+}
@@ -0,0 +1,115 @@
+package server
+
+import (
+	"cmp"
+	"context"
+	"slices"
+
+	"github.com/chickenzord/portosync/internal/performance"
+	"github.com/chickenzord/portosync/internal/portfolio"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// performanceScopes returns the overall, per account and per asset type scopes of the accounts
+// found in any of the snapshots, so an account missing from some snapshots stays measured
+func performanceScopes(snapshots []portfolio.Snapshot, accountNames []string) []performance.Scope {
+	var present, assetTypes []string
+
+	for _, s := range snapshots {
+		for _, b := range s.Balances {
+			if slices.Contains(accountNames, b.SourceAccount) && !slices.Contains(present, b.SourceAccount) {
+				present = append(present, b.SourceAccount)
+			}
+		}
+	}
+
+	for _, s := range snapshots {
+		for _, b := range s.Balances {
+			if !b.IsCash() && slices.Contains(accountNames, b.SourceAccount) && !slices.Contains(assetTypes, b.AssetType) {
+				assetTypes = append(assetTypes, b.AssetType)
+			}
+		}
+	}
+
+	slices.Sort(present)
+	slices.Sort(assetTypes)
+
+	inAccounts := func(b portfolio.Balance) bool { return slices.Contains(accountNames, b.SourceAccount) }
+	scopes := []performance.Scope{{Name: "overall", Accounts: present, Match: inAccounts}}
+
+	for _, account := range present {
+		scopes = append(scopes, performance.Scope{
+			Name:     "account:" + account,
+			Accounts: []string{account},
+			Match:    func(b portfolio.Balance) bool { return b.SourceAccount == account },
+		})
+	}
+
+	for _, assetType := range assetTypes {
+		scopes = append(scopes, performance.Scope{
+			Name:     "asset_type:" + assetType,
+			Accounts: present,
+			Match:    func(b portfolio.Balance) bool { return inAccounts(b) && b.AssetType == assetType },
+		})
+	}
+
+	return scopes
+}
+
+// handleGetPerformance handles the get_performance MCP tool
+func (m *MCP) handleGetPerformance(ctx context.Context, req *mcp.CallToolRequest, args GetPerformanceArgs) (*mcp.CallToolResult, GetPerformanceResult, error) {
+	result := GetPerformanceResult{Currency: cmp.Or(args.Currency, "IDR")}
+
+	if m.snapshots == nil {
+		return errorResult(errSnapshotsDisabled.Error()), result, nil
+	}
+
+	from, to, err := parseDateRange(args.FromDate, args.ToDate)
+	if err != nil {
+		return errorResult(err.Error()), result, nil
+	}
+
+	if !from.IsZero() && !to.IsZero() && from.After(to) {
+		return errorResult("from_date must not be after to_date"), result, nil
+	}
+
+	accounts := m.selectAccounts(args.AccountNames)
+	if accounts.empty() {
+		return accountsNotFoundResult(m.getAccountNames()), result, nil
+	}
+
+	snapshots, err := m.snapshots.Range(from, to)
+	if err != nil {
+		return nil, result, err
+	}
+
+	if len(snapshots) < 2 {
+		return errorResult("At least two stored snapshots are needed in the date range to compute performance"), result, nil
+	}
+
+	transactions, err := m.getTransactions(to)
+	if err != nil {
+		return nil, result, err
+	}
+
+	for i, scope := range performanceScopes(snapshots, accounts.names()) {
+		r := performance.Compute(snapshots, transactions, scope, result.Currency)
+
+		switch {
+		case i == 0:
+			result.Overall = r
+		case r.From == "":
+			continue
+		default:
+			result.Breakdown = append(result.Breakdown, r)
+		}
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: result.Description(),
+			},
+		},
+	}, result, nil
+}
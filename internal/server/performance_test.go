@@ -0,0 +1,136 @@
+package server
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/chickenzord/goksei"
+	"github.com/chickenzord/portosync/internal/portfolio"
+	"github.com/chickenzord/portosync/internal/snapshot"
+	"github.com/chickenzord/portosync/internal/source"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMCP_handleGetPerformance(t *testing.T) {
+	dir := t.TempDir()
+
+	store, err := snapshot.NewStore(filepath.Join(dir, "snapshots"))
+	require.NoError(t, err)
+
+	for _, s := range []portfolio.Snapshot{
+		{Date: mustDate(t, "2024-01-01"), Balances: []portfolio.Balance{
+			{SourceAccount: "personal", AssetSymbol: "BBCA", AssetType: "equity", UnitsAmount: 100, UnitsValue: 1000, UnitsCurrency: "IDR"},
+			{SourceAccount: "business", AssetSymbol: "SCMMF", AssetType: "mutual_fund", UnitsAmount: 100, UnitsValue: 1000, UnitsCurrency: "IDR"},
+		}},
+		{Date: mustDate(t, "2024-01-03"), Balances: []portfolio.Balance{
+			{SourceAccount: "personal", AssetSymbol: "BBCA", AssetType: "equity", UnitsAmount: 200, UnitsValue: 2200, UnitsCurrency: "IDR"},
+			{SourceAccount: "business", AssetSymbol: "SCMMF", AssetType: "mutual_fund", UnitsAmount: 100, UnitsValue: 1010, UnitsCurrency: "IDR"},
+		}},
+	} {
+		require.NoError(t, store.Save(s))
+	}
+
+	mcpServer := &MCP{
+		kseiClients: make(map[string]*goksei.Client),
+		sources: map[string]source.Source{
+			"personal": source.NewManual(filepath.Join(dir, "personal.yaml")),
+			"business": source.NewManual(filepath.Join(dir, "business.yaml")),
+		},
+		snapshots: store,
+	}
+
+	ctx := context.Background()
+	req := &mcp.CallToolRequest{}
+
+	result, data, err := mcpServer.handleGetPerformance(ctx, req, GetPerformanceArgs{})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+
+	assert.Equal(t, "overall", data.Overall.Scope)
+	assert.Equal(t, 2000.0, data.Overall.StartValue)
+	assert.Equal(t, 3210.0, data.Overall.EndValue)
+	assert.Equal(t, 1100.0, data.Overall.NetFlows, "inferred buy of 100 BBCA at 11")
+	assert.InDelta(t, 5.5, data.Overall.TWR, 1e-9)
+	assert.Equal(t, 1, data.Overall.MissingDays)
+
+	scopes := make(map[string]float64)
+	for _, r := range data.Breakdown {
+		scopes[r.Scope] = r.TWR
+	}
+
+	assert.InDelta(t, 10.0, scopes["account:personal"], 1e-9)
+	assert.InDelta(t, 1.0, scopes["account:business"], 1e-9)
+	assert.InDelta(t, 10.0, scopes["asset_type:equity"], 1e-9)
+	assert.InDelta(t, 1.0, scopes["asset_type:mutual_fund"], 1e-9)
+	assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, "- account:personal from 2024-01-01 to 2024-01-03")
+
+	_, data, err = mcpServer.handleGetPerformance(ctx, req, GetPerformanceArgs{AccountNames: []string{"business"}})
+	require.NoError(t, err)
+	assert.Equal(t, 1010.0, data.Overall.EndValue)
+	assert.Len(t, data.Breakdown, 2)
+
+	for _, args := range []GetPerformanceArgs{
+		{FromDate: "2024-01-02"},
+		{FromDate: "2024-01-03", ToDate: "2024-01-01"},
+		{ToDate: "soon"},
+		{AccountNames: []string{"unknown"}},
+	} {
+		result, _, err := mcpServer.handleGetPerformance(ctx, req, args)
+		require.NoError(t, err)
+		assert.True(t, result.IsError, "%+v", args)
+	}
+
+	mcpServer.snapshots = nil
+	result, _, err = mcpServer.handleGetPerformance(ctx, req, GetPerformanceArgs{})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+}
+
+func TestMCP_handleGetPerformance_MissingAccount(t *testing.T) {
+	dir := t.TempDir()
+
+	store, err := snapshot.NewStore(filepath.Join(dir, "snapshots"))
+	require.NoError(t, err)
+
+	for _, s := range []portfolio.Snapshot{
+		{Date: mustDate(t, "2024-01-01"), Balances: []portfolio.Balance{
+			{SourceAccount: "personal", AssetSymbol: "BBCA", AssetType: "equity", UnitsAmount: 100, UnitsValue: 1000, UnitsCurrency: "IDR"},
+			{SourceAccount: "business", AssetSymbol: "SCMMF", AssetType: "mutual_fund", UnitsAmount: 100, UnitsValue: 1000, UnitsCurrency: "IDR"},
+		}},
+		// The fetch of business failed on the last day
+		{Date: mustDate(t, "2024-01-02"), Balances: []portfolio.Balance{
+			{SourceAccount: "personal", AssetSymbol: "BBCA", AssetType: "equity", UnitsAmount: 100, UnitsValue: 1100, UnitsCurrency: "IDR"},
+		}},
+	} {
+		require.NoError(t, store.Save(s))
+	}
+
+	mcpServer := &MCP{
+		kseiClients: make(map[string]*goksei.Client),
+		sources: map[string]source.Source{
+			"personal": source.NewManual(filepath.Join(dir, "personal.yaml")),
+			"business": source.NewManual(filepath.Join(dir, "business.yaml")),
+		},
+		snapshots: store,
+	}
+
+	result, data, err := mcpServer.handleGetPerformance(context.Background(), &mcp.CallToolRequest{}, GetPerformanceArgs{})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+
+	assert.Equal(t, "2024-01-02", data.Overall.To)
+	assert.Equal(t, 2000.0, data.Overall.StartValue)
+	assert.Equal(t, 2100.0, data.Overall.EndValue, "business keeps its previous value")
+	assert.InDelta(t, 5.0, data.Overall.TWR, 1e-9)
+	assert.Equal(t, 1, data.Overall.CarriedDays)
+
+	var scopes []string
+	for _, r := range data.Breakdown {
+		scopes = append(scopes, r.Scope)
+	}
+
+	assert.Equal(t, []string{"account:business", "account:personal", "asset_type:equity", "asset_type:mutual_fund"}, scopes)
+}
@@ -0,0 +1,131 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/chickenzord/portosync/internal/price"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+var errPricesDisabled = errors.New("price history requires a data directory, configure DATA_DIR")
+
+// handleImportPrices handles the import_prices MCP tool
+func (m *MCP) handleImportPrices(ctx context.Context, req *mcp.CallToolRequest, args ImportPricesArgs) (*mcp.CallToolResult, ImportPricesResult, error) {
+	result := ImportPricesResult{}
+
+	if m.prices == nil {
+		return errorResult(errPricesDisabled.Error()), result, nil
+	}
+
+	var (
+		prices []price.Price
+		err    error
+	)
+
+	switch {
+	case args.Path != "" && args.Content != "":
+		return errorResult("Pass either path or content, not both"), result, nil
+	case args.Path != "":
+		prices, err = m.loadDataFilePrices(args.Path, args.Symbol)
+	case args.Content != "":
+		prices, err = price.Read(strings.NewReader(args.Content), args.Symbol)
+	case !args.FromSnapshots:
+		return errorResult("Pass path or content of a CSV file, or from_snapshots"), result, nil
+	}
+
+	if err != nil {
+		return errorResult("Cannot read prices: " + err.Error()), result, nil
+	}
+
+	if args.FromSnapshots {
+		snapshots, err := m.snapshots.Range(time.Time{}, time.Time{})
+		if err != nil {
+			return nil, result, err
+		}
+
+		// Imported prices come last so they are not replaced by prices of the same date
+		var implied []price.Price
+		for _, s := range snapshots {
+			implied = append(implied, price.FromBalances(s.Date, s.Balances)...)
+		}
+
+		prices = append(implied, prices...)
+		result.Snapshot = len(snapshots)
+	}
+
+	if result.Stored, err = m.prices.Put(prices); err != nil {
+		return nil, result, err
+	}
+
+	result.Read = len(prices)
+
+	for _, p := range prices {
+		if !slices.Contains(result.Symbols, p.Symbol) {
+			result.Symbols = append(result.Symbols, p.Symbol)
+		}
+	}
+
+	slices.Sort(result.Symbols)
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: result.Description(),
+			},
+		},
+	}, result, nil
+}
+
+// handleGetPriceHistory handles the get_price_history MCP tool
+func (m *MCP) handleGetPriceHistory(ctx context.Context, req *mcp.CallToolRequest, args GetPriceHistoryArgs) (*mcp.CallToolResult, GetPriceHistoryResult, error) {
+	result := GetPriceHistoryResult{Symbol: strings.ToUpper(strings.TrimSpace(args.Symbol))}
+
+	if m.prices == nil {
+		return errorResult(errPricesDisabled.Error()), result, nil
+	}
+
+	if result.Symbol == "" {
+		return errorResult("symbol is required"), result, nil
+	}
+
+	from, to, err := parseDateRange(args.FromDate, args.ToDate)
+	if err != nil {
+		return errorResult(err.Error()), result, nil
+	}
+
+	if result.Prices, err = m.prices.History(result.Symbol, from, to); err != nil {
+		return nil, result, err
+	}
+
+	if n := len(result.Prices); n > 0 {
+		first, last := result.Prices[0].Price, result.Prices[n-1].Price
+		result.Change = last - first
+		result.ChangePercent = result.Change / first * 100
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: result.Description(),
+			},
+		},
+	}, result, nil
+}
+
+// loadDataFilePrices reads prices from a CSV file inside the data directory. Paths are resolved
+// relative to it and may not escape it, so MCP clients cannot read other files of the server.
+func (m *MCP) loadDataFilePrices(path, symbol string) ([]price.Price, error) {
+	f, err := os.OpenInRoot(m.dataDir, path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open %q, path must be a file inside DATA_DIR", path)
+	}
+	defer f.Close()
+
+	return price.Read(f, symbol)
+}
@@ -0,0 +1,118 @@
+package server
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/chickenzord/goksei"
+	"github.com/chickenzord/portosync/internal/portfolio"
+	"github.com/chickenzord/portosync/internal/price"
+	"github.com/chickenzord/portosync/internal/snapshot"
+	"github.com/chickenzord/portosync/internal/source"
+	"github.com/chickenzord/portosync/internal/transaction"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMCP_handleImportPrices(t *testing.T) {
+	dir := t.TempDir()
+
+	snapshots, err := snapshot.NewStore(filepath.Join(dir, "snapshots"))
+	require.NoError(t, err)
+
+	prices, err := price.NewStore(filepath.Join(dir, "prices"))
+	require.NoError(t, err)
+
+	for _, s := range []portfolio.Snapshot{
+		{Date: mustDate(t, "2024-01-01"), Balances: []portfolio.Balance{
+			{SourceAccount: "personal", AssetSymbol: "BBCA", AssetType: "equity", UnitsAmount: 100, UnitsValue: 1000, UnitsCurrency: "IDR"},
+		}},
+		{Date: mustDate(t, "2024-01-02"), Balances: []portfolio.Balance{
+			{SourceAccount: "personal", AssetSymbol: "BBCA", AssetType: "equity", UnitsAmount: 200, UnitsValue: 2200, UnitsCurrency: "IDR"},
+		}},
+	} {
+		require.NoError(t, snapshots.Save(s))
+	}
+
+	navFile := filepath.Join(dir, "nav.csv")
+	require.NoError(t, os.WriteFile(navFile, []byte("date,nav\n2024-01-01,1500\n2024-01-02,1501\n"), 0o600))
+
+	mcpServer := &MCP{
+		kseiClients: make(map[string]*goksei.Client),
+		sources: map[string]source.Source{
+			"personal": source.NewManual(filepath.Join(dir, "personal.yaml")),
+		},
+		dataDir:   dir,
+		snapshots: snapshots,
+		prices:    prices,
+	}
+
+	ctx := context.Background()
+	req := &mcp.CallToolRequest{}
+
+	result, data, err := mcpServer.handleImportPrices(ctx, req, ImportPricesArgs{
+		Content:       "date,symbol,close\n2024-01-02,BBCA,10.5\n",
+		FromSnapshots: true,
+	})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	assert.Equal(t, ImportPricesResult{Read: 3, Stored: 2, Symbols: []string{"BBCA"}, Snapshot: 2}, data)
+
+	_, data, err = mcpServer.handleImportPrices(ctx, req, ImportPricesArgs{Path: "nav.csv", Symbol: "scmmf"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, data.Stored)
+
+	result, history, err := mcpServer.handleGetPriceHistory(ctx, req, GetPriceHistoryArgs{Symbol: "bbca"})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	require.Len(t, history.Prices, 2)
+	assert.Equal(t, price.SourceSnapshot, history.Prices[0].Source)
+	assert.Equal(t, price.SourceImported, history.Prices[1].Source)
+	assert.InDelta(t, 5.0, history.ChangePercent, 1e-9)
+	assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, "- 2024-01-02: 10.500000 IDR (imported)")
+
+	_, history, err = mcpServer.handleGetPriceHistory(ctx, req, GetPriceHistoryArgs{Symbol: "SCMMF", FromDate: "2024-01-02"})
+	require.NoError(t, err)
+	require.Len(t, history.Prices, 1)
+	assert.Equal(t, 1501.0, history.Prices[0].Price)
+
+	// Inferred transactions use imported prices of the same day
+	transactions, err := mcpServer.getTransactions(mustDate(t, "2024-01-02"))
+	require.NoError(t, err)
+	require.Len(t, transactions, 1)
+	assert.Equal(t, transaction.OriginInferred, transactions[0].Origin)
+	assert.Equal(t, 10.5, transactions[0].Price)
+
+	for _, args := range []ImportPricesArgs{
+		{},
+		{Path: navFile, Content: "date,symbol,price\n"},
+		{Path: "missing.csv"},
+		{Path: navFile},
+		{Path: "../nav.csv"},
+		{Path: "/etc/passwd"},
+		{Content: "date,symbol,price\n2024-01-02,BBCA,abc\n"},
+	} {
+		result, _, err := mcpServer.handleImportPrices(ctx, req, args)
+		require.NoError(t, err)
+		assert.True(t, result.IsError, "%+v", args)
+	}
+
+	for _, args := range []GetPriceHistoryArgs{{}, {Symbol: "BBCA", ToDate: "soon"}} {
+		result, _, err := mcpServer.handleGetPriceHistory(ctx, req, args)
+		require.NoError(t, err)
+		assert.True(t, result.IsError, "%+v", args)
+	}
+
+	result, _, err = mcpServer.handleImportPrices(ctx, req, ImportPricesArgs{Path: "../nav.csv"})
+	require.NoError(t, err)
+	assert.Equal(t, `Cannot read prices: cannot open "../nav.csv", path must be a file inside DATA_DIR`, result.Content[0].(*mcp.TextContent).Text)
+
+	mcpServer.prices = nil
+	result, _, err = mcpServer.handleGetPriceHistory(ctx, req, GetPriceHistoryArgs{Symbol: "BBCA"})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Equal(t, errPricesDisabled.Error(), result.Content[0].(*mcp.TextContent).Text)
+}
@@ -0,0 +1,243 @@
+package server
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/chickenzord/portosync/internal/portfolio"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+var accountNamesPromptArgument = &mcp.PromptArgument{
+	Name:        "account_names",
+	Title:       "Account Names",
+	Description: "Comma-separated account names to include, all accounts if empty",
+}
+
+// addPrompts registers reusable portfolio analysis prompts on the MCP server
+func (m *MCP) addPrompts(mcpServer *mcp.Server) {
+	mcpServer.AddPrompt(&mcp.Prompt{
+		Name:        "monthly_portfolio_review",
+		Title:       "Monthly Portfolio Review",
+		Description: "Review portfolio value, allocation and notable changes over a month",
+		Arguments: []*mcp.PromptArgument{
+			accountNamesPromptArgument,
+			{
+				Name:        "month",
+				Title:       "Month",
+				Description: "Month to review in YYYY-MM format, defaults to the previous month",
+			},
+		},
+	}, m.handleMonthlyReviewPrompt)
+
+	mcpServer.AddPrompt(&mcp.Prompt{
+		Name:        "rebalance_check",
+		Title:       "Rebalance Check",
+		Description: "Compare current allocation against a target allocation and suggest trades",
+		Arguments: []*mcp.PromptArgument{
+			accountNamesPromptArgument,
+			{
+				Name:        "target_allocation",
+				Title:       "Target Allocation",
+				Description: "Target weights in percent per asset type, e.g. \"equity=60,bond=30,cash=10\"",
+			},
+			{
+				Name:        "tolerance",
+				Title:       "Tolerance",
+				Description: "Allowed deviation from target in percentage points before rebalancing, defaults to 5",
+			},
+		},
+	}, m.handleRebalanceCheckPrompt)
+
+	mcpServer.AddPrompt(&mcp.Prompt{
+		Name:        "explain_changes",
+		Title:       "Explain Changes Since Last Snapshot",
+		Description: "Explain what changed in the portfolio since a previous snapshot",
+		Arguments: []*mcp.PromptArgument{
+			accountNamesPromptArgument,
+			{
+				Name:        "since",
+				Title:       "Since",
+				Description: "Snapshot date to compare against in YYYY-MM-DD format, defaults to the previous stored snapshot",
+			},
+		},
+	}, m.handleExplainChangesPrompt)
+}
+
+// promptAccounts describes the selected accounts and the matching account_names tool argument
+func promptAccounts(args map[string]string) (description, toolArgument string) {
+	var names []string
+
+	for name := range strings.SplitSeq(args["account_names"], ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+
+	if len(names) == 0 {
+		return "all my accounts", "omit account_names to include all accounts"
+	}
+
+	return "accounts " + strings.Join(names, ", "), fmt.Sprintf("account_names [%s]", strings.Join(names, ", "))
+}
+
+func promptResult(description, text string) *mcp.GetPromptResult {
+	return &mcp.GetPromptResult{
+		Description: description,
+		Messages: []*mcp.PromptMessage{
+			{
+				Role:    "user",
+				Content: &mcp.TextContent{Text: text},
+			},
+		},
+	}
+}
+
+func (m *MCP) handleMonthlyReviewPrompt(ctx context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	args := req.Params.Arguments
+
+	today := portfolio.Today()
+	start := time.Date(today.Year(), today.Month()-1, 1, 0, 0, 0, 0, time.Local)
+
+	if month := args["month"]; month != "" {
+		t, err := time.ParseInLocation("2006-01", month, time.Local)
+		if err != nil {
+			return nil, fmt.Errorf("invalid month %q, expected YYYY-MM", month)
+		}
+
+		start = t
+	}
+
+	end := start.AddDate(0, 1, -1)
+	accounts, accountsArg := promptAccounts(args)
+
+	text := fmt.Sprintf(`Review %s for %s (%s to %s).
+
+1. Call export_portfolio with format jsonl, from_date %s and to_date %s (%s) to see how balances evolved during the month.
+2. Call get_portfolio (%s) for the current balances.
+3. Call get_risk_report (%s) for concentration and currency exposure.
+
+Then summarize:
+- Total value per currency at the start and end of the month, and the change in amount and percent
+- Allocation by asset type and the largest holdings by value
+- Holdings that appeared, disappeared or changed significantly in units
+- Cash levels and anything that needs attention, including risk report warnings
+
+Keep the review concise and use tables where helpful.`,
+		accounts, start.Format("January 2006"), start.Format(time.DateOnly), end.Format(time.DateOnly),
+		start.Format(time.DateOnly), end.Format(time.DateOnly), accountsArg,
+		accountsArg, accountsArg)
+
+	return promptResult("Monthly portfolio review for "+start.Format("January 2006"), text), nil
+}
+
+func (m *MCP) handleRebalanceCheckPrompt(ctx context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	args := req.Params.Arguments
+	accounts, accountsArg := promptAccounts(args)
+	tolerance := cmp.Or(args["tolerance"], "5")
+
+	target := "omit target_allocation to use the configured targets, and ask me for my targets if none are configured"
+	if t := args["target_allocation"]; t != "" {
+		target = fmt.Sprintf("target_allocation %q", t)
+	}
+
+	text := fmt.Sprintf(`Check whether %s need rebalancing.
+
+1. Call suggest_rebalance (%s, %s, tolerance %s).
+2. Review the suggested trades, preferring to fund buys from available cash before selling other holdings.
+
+Present current weight, target weight, deviation and suggested trade per target in a table, then explain the most important trades. Do not place any orders.`,
+		accounts, accountsArg, target, tolerance)
+
+	return promptResult("Rebalance check for "+accounts, text), nil
+}
+
+func (m *MCP) handleExplainChangesPrompt(ctx context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	args := req.Params.Arguments
+	accounts, accountsArg := promptAccounts(args)
+
+	since := args["since"]
+	if since == "" {
+		since = m.previousSnapshotDate()
+	} else if _, err := portfolio.ParseDate(since); err != nil {
+		return nil, fmt.Errorf("invalid since date %q, expected YYYY-MM-DD", since)
+	}
+
+	baseline := "Call export_portfolio with format jsonl and from_date set to the earliest date you want to compare (ask me if unsure)"
+	if since != "" {
+		baseline = fmt.Sprintf("Read the resource portosync://snapshots/%s, or call export_portfolio with format jsonl, from_date %s and to_date %s (%s)", since, since, since, accountsArg)
+	}
+
+	text := fmt.Sprintf(`Explain what changed in %s since %s.
+
+1. %s to get the baseline balances.
+2. Call get_portfolio (%s) for the current balances.
+3. Call list_transactions (%s, from_date after the baseline date) for buys, sells, subscriptions and redemptions in the period.
+4. Match holdings by source_account and asset_symbol.
+
+Then explain:
+- The change in total value per currency
+- Holdings that were added or removed
+- Unit changes, using the listed transactions
+- Value changes with unchanged units, which indicate price movements
+
+List the biggest contributors to the change first.`,
+		accounts, cmp.Or(since, "the previous snapshot"), baseline, accountsArg, accountsArg)
+
+	return promptResult("Portfolio changes of "+accounts+" since "+cmp.Or(since, "the previous snapshot"), text), nil
+}
+
+// previousSnapshotDate returns the date of the latest stored snapshot before today, if any
+func (m *MCP) previousSnapshotDate() string {
+	if m.snapshots == nil {
+		return ""
+	}
+
+	dates, err := m.snapshots.Dates()
+	if err != nil {
+		return ""
+	}
+
+	today := portfolio.Today()
+
+	for i := len(dates) - 1; i >= 0; i-- {
+		if dates[i].Before(today) {
+			return dates[i].Format(time.DateOnly)
+		}
+	}
+
+	return ""
+}
+
+// handleComplete suggests configured account names for prompt and resource template arguments
+func (m *MCP) handleComplete(ctx context.Context, req *mcp.CompleteRequest) (*mcp.CompleteResult, error) {
+	result := &mcp.CompleteResult{
+		Completion: mcp.CompletionResultDetails{Values: []string{}},
+	}
+
+	arg := req.Params.Argument
+	if arg.Name != "account_names" && arg.Name != "account" {
+		return result, nil
+	}
+
+	// account_names is comma-separated, so only the last name is completed
+	prefix := ""
+	partial := arg.Value
+
+	if i := strings.LastIndex(partial, ","); i >= 0 && arg.Name == "account_names" {
+		prefix, partial = partial[:i+1], strings.TrimSpace(partial[i+1:])
+	}
+
+	for _, name := range m.getAccountNames() {
+		if strings.HasPrefix(name, partial) {
+			result.Completion.Values = append(result.Completion.Values, prefix+name)
+		}
+	}
+
+	result.Completion.Total = len(result.Completion.Values)
+
+	return result, nil
+}
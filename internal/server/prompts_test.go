@@ -0,0 +1,109 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/chickenzord/goksei"
+	"github.com/chickenzord/portosync/internal/portfolio"
+	"github.com/chickenzord/portosync/internal/snapshot"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func promptText(t *testing.T, result *mcp.GetPromptResult) string {
+	t.Helper()
+
+	require.Len(t, result.Messages, 1)
+
+	text, ok := result.Messages[0].Content.(*mcp.TextContent)
+	require.True(t, ok)
+
+	return text.Text
+}
+
+func TestMCP_Prompts(t *testing.T) {
+	m, err := NewMCP(Options{KseiAuthCacheDir: t.TempDir()})
+	require.NoError(t, err)
+	session := connectTestClient(t, m, nil)
+	ctx := context.Background()
+
+	prompts, err := session.ListPrompts(ctx, nil)
+	require.NoError(t, err)
+
+	var names []string
+	for _, p := range prompts.Prompts {
+		names = append(names, p.Name)
+	}
+
+	assert.ElementsMatch(t, []string{"monthly_portfolio_review", "rebalance_check", "explain_changes"}, names)
+
+	result, err := session.GetPrompt(ctx, &mcp.GetPromptParams{
+		Name:      "monthly_portfolio_review",
+		Arguments: map[string]string{"month": "2026-02", "account_names": "personal, business"},
+	})
+	require.NoError(t, err)
+
+	text := promptText(t, result)
+	assert.Contains(t, text, "from_date 2026-02-01 and to_date 2026-02-28")
+	assert.Contains(t, text, "account_names [personal, business]")
+
+	_, err = session.GetPrompt(ctx, &mcp.GetPromptParams{
+		Name:      "monthly_portfolio_review",
+		Arguments: map[string]string{"month": "February"},
+	})
+	assert.Error(t, err)
+
+	result, err = session.GetPrompt(ctx, &mcp.GetPromptParams{
+		Name:      "rebalance_check",
+		Arguments: map[string]string{"target_allocation": "equity=60,bond=40"},
+	})
+	require.NoError(t, err)
+
+	text = promptText(t, result)
+	assert.Contains(t, text, `target_allocation "equity=60,bond=40"`)
+	assert.Contains(t, text, "tolerance 5")
+	assert.Contains(t, text, "omit account_names")
+}
+
+func TestMCP_previousSnapshotDate(t *testing.T) {
+	store, err := snapshot.NewStore(t.TempDir())
+	require.NoError(t, err)
+
+	m := &MCP{snapshots: store}
+	assert.Equal(t, "", m.previousSnapshotDate())
+
+	today := portfolio.Today()
+	for _, d := range []int{-7, -2, 0} {
+		require.NoError(t, store.Save(portfolio.Snapshot{Date: today.AddDate(0, 0, d)}))
+	}
+
+	assert.Equal(t, today.AddDate(0, 0, -2).Format("2006-01-02"), m.previousSnapshotDate())
+}
+
+func TestMCP_handleComplete(t *testing.T) {
+	m := &MCP{
+		kseiClients: map[string]*goksei.Client{
+			"personal": {},
+			"business": {},
+			"pension":  {},
+		},
+	}
+
+	complete := func(name, value string) []string {
+		result, err := m.handleComplete(context.Background(), &mcp.CompleteRequest{
+			Params: &mcp.CompleteParams{
+				Argument: mcp.CompleteParamsArgument{Name: name, Value: value},
+			},
+		})
+		require.NoError(t, err)
+
+		return result.Completion.Values
+	}
+
+	assert.Equal(t, []string{"pension", "personal"}, complete("account_names", "pe"))
+	assert.Equal(t, []string{"business,personal"}, complete("account_names", "business,perso"))
+	assert.Equal(t, []string{"business"}, complete("account", "b"))
+	assert.Empty(t, complete("month", "2026"))
+}
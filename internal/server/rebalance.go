@@ -0,0 +1,90 @@
+package server
+
+import (
+	"cmp"
+	"context"
+	"strings"
+
+	"github.com/chickenzord/portosync/internal/portfolio"
+	"github.com/chickenzord/portosync/internal/rebalance"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// handleSuggestRebalance handles the suggest_rebalance MCP tool
+func (m *MCP) handleSuggestRebalance(ctx context.Context, req *mcp.CallToolRequest, args SuggestRebalanceArgs) (*mcp.CallToolResult, SuggestRebalanceResult, error) {
+	result := SuggestRebalanceResult{}
+
+	targets := m.rebalanceTargets
+	if args.TargetAllocation != "" {
+		var err error
+		if targets, err = rebalance.ParseTargetString(args.TargetAllocation); err != nil {
+			return errorResult("Invalid target_allocation: " + err.Error()), result, nil
+		}
+	}
+
+	if len(targets) == 0 {
+		return errorResult("No target allocation configured, pass target_allocation such as \"equity=60,bond=30,cash=10\" or configure TARGET_ALLOCATION"), result, nil
+	}
+
+	tolerance := m.rebalanceTolerance
+	if args.Tolerance != nil {
+		tolerance = *args.Tolerance
+	}
+
+	if tolerance < 0 {
+		return errorResult("tolerance must not be negative"), result, nil
+	}
+
+	accounts := m.selectAccounts(args.AccountNames)
+	if accounts.empty() {
+		return accountsNotFoundResult(m.getAccountNames()), result, nil
+	}
+
+	balances, err := m.fetchBalances(ctx, accounts)
+	if err != nil {
+		return nil, result, err
+	}
+
+	result.Plan = rebalance.Suggest(balances, targets, cmp.Or(args.Currency, "IDR"), tolerance, m.rebalanceQuote)
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: result.Description(),
+			},
+		},
+	}, result, nil
+}
+
+// rebalanceQuote returns the last known price of a symbol target not held in the selected accounts,
+// taken from the latest snapshot or else the price history. Priced symbols in the sector classification
+// are IDX equities.
+func (m *MCP) rebalanceQuote(symbol string) (rebalance.Quote, bool) {
+	if m.snapshots != nil {
+		if snapshot, err := m.snapshots.Latest(); err == nil && snapshot != nil {
+			for _, b := range snapshot.Balances {
+				if strings.EqualFold(b.AssetSymbol, symbol) && b.UnitsAmount > 0 {
+					return rebalance.Quote{Price: b.UnitsValue / b.UnitsAmount, Currency: b.UnitsCurrency, AssetType: b.AssetType}, true
+				}
+			}
+		}
+	}
+
+	if m.prices == nil {
+		return rebalance.Quote{}, false
+	}
+
+	p, ok, err := m.prices.At(symbol, portfolio.Today())
+	if err != nil || !ok {
+		return rebalance.Quote{}, false
+	}
+
+	q := rebalance.Quote{Price: p.Price, Currency: p.Currency}
+
+	catalogue, _ := m.classification() // falls back to the built-in classification
+	if _, ok := catalogue.Lookup(symbol); ok {
+		q.AssetType = "equity"
+	}
+
+	return q, true
+}
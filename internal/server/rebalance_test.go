@@ -0,0 +1,94 @@
+package server
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/chickenzord/goksei"
+	"github.com/chickenzord/portosync/internal/portfolio"
+	"github.com/chickenzord/portosync/internal/price"
+	"github.com/chickenzord/portosync/internal/rebalance"
+	"github.com/chickenzord/portosync/internal/source"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMCP_handleSuggestRebalance(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "holdings.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`holdings:
+  - {symbol: BBCA, type: equity, amount: 500, price: 10000}
+  - {symbol: IDR, type: cash, amount: 5000000, value: 5000000}
+`), 0o600))
+
+	mcpServer := &MCP{
+		kseiClients: make(map[string]*goksei.Client),
+		sources: map[string]source.Source{
+			"vault": source.NewManual(path),
+		},
+		rebalanceTolerance: rebalance.DefaultTolerance,
+	}
+
+	ctx := context.Background()
+	req := &mcp.CallToolRequest{}
+
+	result, _, err := mcpServer.handleSuggestRebalance(ctx, req, SuggestRebalanceArgs{})
+	require.NoError(t, err)
+	assert.True(t, result.IsError, "no targets configured")
+
+	result, data, err := mcpServer.handleSuggestRebalance(ctx, req, SuggestRebalanceArgs{TargetAllocation: "equity=80,cash=20"})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	assert.Equal(t, 10000000.0, data.Plan.TotalValue)
+	require.Len(t, data.Plan.Suggestions, 2)
+	assert.Equal(t, "cash", data.Plan.Suggestions[0].Target)
+	assert.Equal(t, "sell", data.Plan.Suggestions[0].Action)
+	assert.Equal(t, "equity", data.Plan.Suggestions[1].Target)
+	assert.Equal(t, "buy", data.Plan.Suggestions[1].Action)
+	assert.Equal(t, 3000000.0, data.Plan.Suggestions[1].Amount)
+	assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, "equity: current 50.00%, target 80.00%")
+
+	tolerance := 40.0
+	_, data, err = mcpServer.handleSuggestRebalance(ctx, req, SuggestRebalanceArgs{TargetAllocation: "equity=80,cash=20", Tolerance: &tolerance})
+	require.NoError(t, err)
+	assert.Equal(t, "hold", data.Plan.Suggestions[1].Action)
+
+	tolerance = -1
+	result, _, err = mcpServer.handleSuggestRebalance(ctx, req, SuggestRebalanceArgs{TargetAllocation: "equity=80,cash=20", Tolerance: &tolerance})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+
+	result, _, err = mcpServer.handleSuggestRebalance(ctx, req, SuggestRebalanceArgs{TargetAllocation: "equity=80,bond=40"})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+}
+
+func TestMCP_handleSuggestRebalance_NotHeld(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "holdings.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`holdings:
+  - {symbol: IDR, type: cash, amount: 10000000, value: 10000000}
+`), 0o600))
+
+	prices, err := price.NewStore(filepath.Join(dir, "prices"))
+	require.NoError(t, err)
+	_, err = prices.Put([]price.Price{{Symbol: "TLKM", Date: portfolio.Today(), Price: 3000, Currency: "IDR", Source: price.SourceImported}})
+	require.NoError(t, err)
+
+	mcpServer := &MCP{
+		kseiClients: make(map[string]*goksei.Client),
+		sources: map[string]source.Source{
+			"vault": source.NewManual(path),
+		},
+		prices: prices,
+	}
+
+	// 2,500,000 to buy at 300,000 per lot of the price history is 8 lots
+	_, data, err := mcpServer.handleSuggestRebalance(context.Background(), &mcp.CallToolRequest{}, SuggestRebalanceArgs{TargetAllocation: "symbol:TLKM=25"})
+	require.NoError(t, err)
+	require.NotEmpty(t, data.Plan.Suggestions)
+	assert.Equal(t, "buy", data.Plan.Suggestions[0].Action)
+	assert.Equal(t, 8, data.Plan.Suggestions[0].Lots)
+}
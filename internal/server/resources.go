@@ -0,0 +1,170 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/chickenzord/portosync/internal/portfolio"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	resourceAccountsURI          = "portosync://accounts"
+	resourcePortfolioURI         = "portosync://portfolio"
+	resourcePortfolioURIPrefix   = "portosync://portfolio/"
+	resourceSnapshotsURIPrefix   = "portosync://snapshots/"
+	resourcePortfolioURITemplate = resourcePortfolioURIPrefix + "{account}"
+	resourceSnapshotsURITemplate = resourceSnapshotsURIPrefix + "{date}"
+	resourceMIMEType             = "application/json"
+)
+
+// addResources registers portfolio resources on the MCP server
+func (m *MCP) addResources(mcpServer *mcp.Server) {
+	mcpServer.AddResource(&mcp.Resource{
+		URI:         resourceAccountsURI,
+		Name:        "accounts",
+		Title:       "Configured Accounts",
+		Description: "Names and tags of all configured accounts, usable in portfolio resource URIs and tool arguments",
+		MIMEType:    resourceMIMEType,
+	}, m.readAccountsResource)
+
+	mcpServer.AddResource(&mcp.Resource{
+		URI:         resourcePortfolioURI,
+		Name:        "portfolio",
+		Title:       "Portfolio Balances",
+		Description: "Latest known balances and per-currency totals of all configured accounts",
+		MIMEType:    resourceMIMEType,
+	}, m.readPortfolioResource)
+
+	mcpServer.AddResourceTemplate(&mcp.ResourceTemplate{
+		URITemplate: resourcePortfolioURITemplate,
+		Name:        "account-portfolio",
+		Title:       "Account Portfolio Balances",
+		Description: "Latest known balances and per-currency totals of a single account",
+		MIMEType:    resourceMIMEType,
+	}, m.readPortfolioResource)
+
+	mcpServer.AddResourceTemplate(&mcp.ResourceTemplate{
+		URITemplate: resourceSnapshotsURITemplate,
+		Name:        "snapshot",
+		Title:       "Portfolio Snapshot",
+		Description: "Stored portfolio snapshot of a date in YYYY-MM-DD format, or \"latest\" for the most recent snapshot",
+		MIMEType:    resourceMIMEType,
+	}, m.readSnapshotResource)
+}
+
+func jsonResource(uri string, v any) (*mcp.ReadResourceResult, error) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return &mcp.ReadResourceResult{
+		Contents: []*mcp.ResourceContents{
+			{
+				URI:      uri,
+				MIMEType: resourceMIMEType,
+				Text:     string(data),
+			},
+		},
+	}, nil
+}
+
+func (m *MCP) readAccountsResource(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	names := m.getAccountNames()
+
+	return jsonResource(req.Params.URI, ListAccountNamesResult{
+		AccountNames: names,
+		AccountTags:  m.accountTags(names),
+		Tags:         m.tags.Names(),
+	})
+}
+
+func (m *MCP) readPortfolioResource(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	uri := req.Params.URI
+
+	var names []string
+
+	if account, ok := strings.CutPrefix(uri, resourcePortfolioURIPrefix); ok {
+		// Account names with spaces or other reserved characters are percent-encoded in URIs
+		name, err := url.PathUnescape(account)
+		if err != nil {
+			return nil, mcp.ResourceNotFoundError(uri)
+		}
+
+		names = []string{name}
+	}
+
+	accounts := m.selectAccounts(names)
+	if accounts.empty() {
+		return nil, mcp.ResourceNotFoundError(uri)
+	}
+
+	balances, err := m.cachedBalances(ctx, accounts)
+	if err != nil {
+		return nil, err
+	}
+
+	return jsonResource(uri, GetPortfolioResult{
+		Balances: balances,
+		Totals:   portfolio.Totals(balances),
+		Matched:  len(balances),
+	})
+}
+
+func (m *MCP) readSnapshotResource(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	uri := req.Params.URI
+
+	if m.snapshots == nil {
+		return nil, errSnapshotsDisabled
+	}
+
+	var (
+		snapshot *portfolio.Snapshot
+		err      error
+	)
+
+	if date := strings.TrimPrefix(uri, resourceSnapshotsURIPrefix); date == "latest" {
+		snapshot, err = m.snapshots.Latest()
+	} else {
+		d, parseErr := portfolio.ParseDate(date)
+		if parseErr != nil {
+			return nil, mcp.ResourceNotFoundError(uri)
+		}
+
+		snapshot, err = m.snapshots.Get(d)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if snapshot == nil {
+		return nil, mcp.ResourceNotFoundError(uri)
+	}
+
+	return jsonResource(uri, snapshot)
+}
+
+// notifyResourcesUpdated notifies subscribed clients about resources affected by changed accounts
+func (m *MCP) notifyResourcesUpdated(ctx context.Context, changedAccounts []string) {
+	if m.mcpServer == nil || len(changedAccounts) == 0 {
+		return
+	}
+
+	uris := []string{resourcePortfolioURI}
+	for _, name := range changedAccounts {
+		uris = append(uris, resourcePortfolioURIPrefix+url.PathEscape(name))
+	}
+
+	if m.snapshots != nil {
+		uris = append(uris, resourceSnapshotsURIPrefix+portfolio.Today().Format(time.DateOnly), resourceSnapshotsURIPrefix+"latest")
+	}
+
+	for _, uri := range uris {
+		_ = m.mcpServer.ResourceUpdated(ctx, &mcp.ResourceUpdatedNotificationParams{URI: uri})
+	}
+}
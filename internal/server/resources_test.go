@@ -0,0 +1,113 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// connectTestClient connects an in-memory MCP client to the server
+func connectTestClient(t *testing.T, m *MCP, opts *mcp.ClientOptions) *mcp.ClientSession {
+	t.Helper()
+
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+
+	serverSession, err := m.mcpServer.Connect(ctx, serverTransport, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = serverSession.Close() })
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test"}, opts)
+	clientSession, err := client.Connect(ctx, clientTransport, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = clientSession.Close() })
+
+	return clientSession
+}
+
+func TestMCP_Resources(t *testing.T) {
+	holdings := filepath.Join(t.TempDir(), "holdings.yaml")
+	require.NoError(t, os.WriteFile(holdings, []byte("holdings:\n  - {symbol: GOLD, amount: 10, value: 1000}\n"), 0o600))
+
+	m, err := NewMCP(Options{
+		KseiAuthCacheDir: t.TempDir(),
+		ManualAccounts:   map[string]string{"vault": holdings, "family vault": holdings},
+		DataDir:          t.TempDir(),
+	})
+	require.NoError(t, err)
+
+	updated := make(chan string, 10)
+	session := connectTestClient(t, m, &mcp.ClientOptions{
+		ResourceUpdatedHandler: func(ctx context.Context, req *mcp.ResourceUpdatedNotificationRequest) {
+			updated <- req.Params.URI
+		},
+	})
+
+	ctx := context.Background()
+
+	res, err := session.ReadResource(ctx, &mcp.ReadResourceParams{URI: "portosync://accounts"})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"account_names": ["family vault", "vault"]}`, res.Contents[0].Text)
+
+	res, err = session.ReadResource(ctx, &mcp.ReadResourceParams{URI: "portosync://portfolio/vault"})
+	require.NoError(t, err)
+
+	var portfolioResult GetPortfolioResult
+	require.NoError(t, json.Unmarshal([]byte(res.Contents[0].Text), &portfolioResult))
+	require.Len(t, portfolioResult.Balances, 1)
+	assert.Equal(t, 1000.0, portfolioResult.Balances[0].UnitsValue)
+
+	// Account names are percent-decoded
+	res, err = session.ReadResource(ctx, &mcp.ReadResourceParams{URI: "portosync://portfolio/family%20vault"})
+	require.NoError(t, err)
+	assert.Contains(t, res.Contents[0].Text, "GOLD")
+
+	_, err = session.ReadResource(ctx, &mcp.ReadResourceParams{URI: "portosync://portfolio/unknown"})
+	assert.Error(t, err)
+
+	// Reading the portfolio recorded today's snapshot
+	res, err = session.ReadResource(ctx, &mcp.ReadResourceParams{URI: "portosync://snapshots/latest"})
+	require.NoError(t, err)
+	assert.Contains(t, res.Contents[0].Text, "GOLD")
+
+	_, err = session.ReadResource(ctx, &mcp.ReadResourceParams{URI: "portosync://snapshots/2000-01-01"})
+	assert.Error(t, err)
+
+	// Changed data fetched in the background notifies subscribers
+	require.NoError(t, session.Subscribe(ctx, &mcp.SubscribeParams{URI: "portosync://portfolio/vault"}))
+	require.NoError(t, os.WriteFile(holdings, []byte("holdings:\n  - {symbol: GOLD, amount: 10, value: 2000}\n"), 0o600))
+	require.NoError(t, os.Chtimes(holdings, time.Now(), time.Now().Add(time.Second)))
+
+	fetchCtx, cancel := context.WithCancel(ctx)
+	go m.RunFetcher(fetchCtx, time.Hour)
+
+	select {
+	case uri := <-updated:
+		assert.Equal(t, "portosync://portfolio/vault", uri)
+	case <-time.After(5 * time.Second):
+		t.Fatal("resource updated notification not received")
+	}
+
+	cancel()
+}
+
+func TestBalanceCache_Update(t *testing.T) {
+	var cache balanceCache
+
+	changed := cache.update([]string{"a", "b"}, nil)
+	assert.Equal(t, []string{"a", "b"}, changed)
+
+	changed = cache.update([]string{"a", "b"}, nil)
+	assert.Empty(t, changed)
+
+	balances, missing := cache.get([]string{"a", "c"})
+	assert.Empty(t, balances)
+	assert.Equal(t, []string{"c"}, missing)
+}
@@ -0,0 +1,77 @@
+package server
+
+import (
+	"cmp"
+	"context"
+	"strings"
+
+	"github.com/chickenzord/portosync/internal/classify"
+	"github.com/chickenzord/portosync/internal/portfolio"
+	"github.com/chickenzord/portosync/internal/risk"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// classification returns the embedded classification of IDX equities,
+// overridden per symbol by the configured classification file
+func (m *MCP) classification() (classify.Catalogue, error) {
+	if m.classificationFile == "" {
+		return classify.Default(), nil
+	}
+
+	override, err := classify.Load(m.classificationFile)
+	if err != nil {
+		return classify.Default(), err
+	}
+
+	return classify.Default().Merge(override), nil
+}
+
+// handleGetRiskReport handles the get_risk_report MCP tool
+func (m *MCP) handleGetRiskReport(ctx context.Context, req *mcp.CallToolRequest, args GetRiskReportArgs) (*mcp.CallToolResult, GetRiskReportResult, error) {
+	result := GetRiskReportResult{}
+
+	if args.TopN < 0 || args.MaxPositionShare < 0 || args.MaxSectorShare < 0 {
+		return errorResult("top_n, max_position_share and max_sector_share must not be negative"), result, nil
+	}
+
+	accounts := m.selectAccounts(args.AccountNames)
+	if accounts.empty() {
+		return accountsNotFoundResult(m.getAccountNames()), result, nil
+	}
+
+	catalogue, err := m.classification()
+	if err != nil {
+		return nil, result, err
+	}
+
+	balances, err := m.cachedBalances(ctx, accounts)
+	if err != nil {
+		return nil, result, err
+	}
+
+	rates := make(map[string]float64, len(args.FxRates))
+	for currency, rate := range args.FxRates {
+		rates[strings.ToUpper(currency)] = rate
+	}
+
+	result.Report = risk.Compute(balances, risk.Options{
+		Currency:    strings.ToUpper(cmp.Or(args.Currency, "IDR")),
+		Rates:       rates,
+		TopN:        args.TopN,
+		MaxPosition: args.MaxPositionShare,
+		MaxSector:   args.MaxSectorShare,
+		Classify: func(b portfolio.Balance) (string, string, bool) {
+			class, ok := catalogue.Lookup(b.AssetSymbol)
+
+			return class.Sector, class.IssuerGroup, ok
+		},
+	})
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: result.Description(),
+			},
+		},
+	}, result, nil
+}
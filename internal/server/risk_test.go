@@ -0,0 +1,78 @@
+package server
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/chickenzord/goksei"
+	"github.com/chickenzord/portosync/internal/risk"
+	"github.com/chickenzord/portosync/internal/source"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMCP_handleGetRiskReport(t *testing.T) {
+	dir := t.TempDir()
+	holdings := filepath.Join(dir, "holdings.yaml")
+	classification := filepath.Join(dir, "classification.csv")
+
+	require.NoError(t, os.WriteFile(holdings, []byte(`holdings:
+  - {symbol: BBCA, type: equity, amount: 100, price: 60}
+  - {symbol: TLKM, type: equity, amount: 100, price: 30}
+  - {symbol: AAPL, type: equity, amount: 1, price: 1, currency: USD}
+  - {symbol: IDR, type: cash, amount: 1000, price: 1}
+`), 0o600))
+	require.NoError(t, os.WriteFile(classification, []byte("symbol,sector,issuer_group\nAAPL,Technology,Apple\n"), 0o600))
+
+	mcpServer := &MCP{
+		kseiClients: make(map[string]*goksei.Client),
+		sources: map[string]source.Source{
+			"vault": source.NewManual(holdings),
+		},
+		classificationFile: classification,
+	}
+
+	ctx := context.Background()
+	req := &mcp.CallToolRequest{}
+
+	result, data, err := mcpServer.handleGetRiskReport(ctx, req, GetRiskReportArgs{FxRates: map[string]float64{"usd": 1000}})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+
+	assert.Equal(t, "IDR", data.Currency)
+	assert.Equal(t, 10000.0, data.HoldingsValue)
+	require.NotNil(t, data.Largest)
+	assert.Equal(t, "BBCA", data.Largest.Symbol)
+	assert.Equal(t, 60.0, data.Largest.Share)
+	assert.Equal(t, []risk.Exposure{
+		{Key: "Financials", Value: 6000, Share: 60, Holdings: 1},
+		{Key: "Infrastructures", Value: 3000, Share: 30, Holdings: 1},
+		{Key: "Technology", Value: 1000, Share: 10, Holdings: 1},
+	}, data.Sectors, "built-in classification with the file adding AAPL")
+	assert.Equal(t, "BUMN", data.Issuers[1].Key)
+	assert.Len(t, data.Currencies, 2)
+	assert.Empty(t, data.Unconverted)
+	assert.Contains(t, data.Warnings, "BBCA is 60.0% of holdings, above the 20% position limit")
+	assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, "Warnings:\n- BBCA is 60.0% of holdings")
+
+	// Without rates, other currencies are left out and higher limits silence warnings
+	_, data, err = mcpServer.handleGetRiskReport(ctx, req, GetRiskReportArgs{MaxPositionShare: 70, MaxSectorShare: 70})
+	require.NoError(t, err)
+	assert.Equal(t, 9000.0, data.HoldingsValue)
+	assert.Equal(t, []string{"USD"}, data.Unconverted)
+	assert.Empty(t, data.Warnings)
+
+	mcpServer.classificationFile = ""
+	_, data, err = mcpServer.handleGetRiskReport(ctx, req, GetRiskReportArgs{FxRates: map[string]float64{"USD": 1000}})
+	require.NoError(t, err)
+	assert.Equal(t, risk.Exposure{Key: risk.Unclassified, Value: 1000, Share: 10, Holdings: 1}, data.Sectors[2])
+
+	for _, args := range []GetRiskReportArgs{{TopN: -1}, {AccountNames: []string{"unknown"}}} {
+		result, _, err := mcpServer.handleGetRiskReport(ctx, req, args)
+		require.NoError(t, err)
+		assert.True(t, result.IsError, "%+v", args)
+	}
+}
@@ -0,0 +1,56 @@
+package server
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/chickenzord/portosync/internal/search"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Scopes of the search_assets tool
+const (
+	searchScopeAll       = "all"
+	searchScopeHoldings  = "holdings"
+	searchScopeCatalogue = "catalogue"
+)
+
+// handleSearchAssets handles the search_assets MCP tool
+func (m *MCP) handleSearchAssets(ctx context.Context, req *mcp.CallToolRequest, args SearchAssetsArgs) (*mcp.CallToolResult, SearchAssetsResult, error) {
+	result := SearchAssetsResult{Query: args.Query}
+
+	scope := args.Scope
+	if scope == "" {
+		scope = searchScopeAll
+	}
+
+	var candidates []search.Asset
+
+	switch scope {
+	case searchScopeAll, searchScopeHoldings:
+		balances, err := m.cachedBalances(ctx, m.selectAccounts(nil))
+		if err != nil {
+			return nil, result, err
+		}
+
+		candidates = search.Holdings(balances)
+
+		if scope == searchScopeAll {
+			candidates = append(candidates, search.Catalogue()...)
+		}
+	case searchScopeCatalogue:
+		candidates = search.Catalogue()
+	default:
+		return errorResult(fmt.Sprintf("Unknown scope %q, use all, holdings or catalogue", scope)), result, nil
+	}
+
+	result.Assets = search.Find(args.Query, candidates, args.Limit)
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: result.Description(),
+			},
+		},
+	}, result, nil
+}
@@ -0,0 +1,54 @@
+package server
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/chickenzord/goksei"
+	"github.com/chickenzord/portosync/internal/search"
+	"github.com/chickenzord/portosync/internal/source"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMCP_handleSearchAssets(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "holdings.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`holdings:
+  - {symbol: BBCA, name: PT Bank Central Asia Tbk, type: equity, amount: 100, price: 10000}
+`), 0o600))
+
+	mcpServer := &MCP{
+		kseiClients: make(map[string]*goksei.Client),
+		sources: map[string]source.Source{
+			"vault": source.NewManual(path),
+		},
+	}
+
+	ctx := context.Background()
+	req := &mcp.CallToolRequest{}
+
+	result, data, err := mcpServer.handleSearchAssets(ctx, req, SearchAssetsArgs{Query: "BCA"})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	require.NotEmpty(t, data.Assets)
+	assert.Equal(t, "BBCA", data.Assets[0].Symbol)
+	assert.Equal(t, search.OriginHolding, data.Assets[0].Origin)
+	assert.Equal(t, []string{"vault"}, data.Assets[0].Accounts)
+	assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, "- BBCA: PT Bank Central Asia Tbk (equity; held in vault)")
+
+	_, data, err = mcpServer.handleSearchAssets(ctx, req, SearchAssetsArgs{Query: "sucorinvest money market", Scope: "catalogue", Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, data.Assets, 2)
+	assert.Equal(t, "money_market_fund", data.Assets[0].FundType)
+
+	_, data, err = mcpServer.handleSearchAssets(ctx, req, SearchAssetsArgs{Query: "sucorinvest", Scope: "holdings"})
+	require.NoError(t, err)
+	assert.Empty(t, data.Assets)
+
+	result, _, err = mcpServer.handleSearchAssets(ctx, req, SearchAssetsArgs{Query: "BCA", Scope: "everywhere"})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+}
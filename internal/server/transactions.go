@@ -0,0 +1,110 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/chickenzord/portosync/internal/portfolio"
+	"github.com/chickenzord/portosync/internal/price"
+	"github.com/chickenzord/portosync/internal/transaction"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// getTransactions returns transactions up to the given date (zero for all), inferred from stored snapshots
+// and corrected by the transactions file
+func (m *MCP) getTransactions(to time.Time) ([]transaction.Transaction, error) {
+	var manual []transaction.Transaction
+
+	if m.transactionsFile != "" {
+		var err error
+		if manual, err = transaction.Load(m.transactionsFile); err != nil {
+			return nil, err
+		}
+	}
+
+	var snapshots []portfolio.Snapshot
+
+	if m.snapshots != nil {
+		var err error
+		if snapshots, err = m.snapshots.Range(time.Time{}, to); err != nil {
+			return nil, err
+		}
+	}
+
+	transactions := transaction.Derive(snapshots, manual)
+
+	// Inferred transactions are priced at the snapshot value per unit, prefer imported prices of the same day
+	if m.prices != nil {
+		for i, t := range transactions {
+			if t.Origin != transaction.OriginInferred {
+				continue
+			}
+
+			prices, err := m.prices.History(t.Symbol, t.Date, t.Date)
+			if err != nil {
+				return nil, err
+			}
+
+			if len(prices) == 1 && prices[0].Source == price.SourceImported {
+				transactions[i].Price = prices[0].Price
+			}
+		}
+	}
+
+	return transactions, nil
+}
+
+// handleListTransactions handles the list_transactions MCP tool
+func (m *MCP) handleListTransactions(ctx context.Context, req *mcp.CallToolRequest, args ListTransactionsArgs) (*mcp.CallToolResult, ListTransactionsResult, error) {
+	result := ListTransactionsResult{}
+
+	from, to, err := parseDateRange(args.FromDate, args.ToDate)
+	if err != nil {
+		return errorResult(err.Error()), result, nil
+	}
+
+	for _, t := range args.Types {
+		if !slices.Contains(transaction.Types, strings.ToLower(t)) {
+			return errorResult(fmt.Sprintf("Unknown transaction type %q, supported types are %s", t, strings.Join(transaction.Types, ", "))), result, nil
+		}
+	}
+
+	accounts := m.selectAccounts(args.AccountNames)
+	if accounts.empty() {
+		return accountsNotFoundResult(m.getAccountNames()), result, nil
+	}
+
+	transactions, err := m.getTransactions(to)
+	if err != nil {
+		return nil, result, err
+	}
+
+	accountNames := accounts.names()
+	filter := portfolio.Filter{Symbols: args.Symbols}
+
+	for _, t := range slices.Backward(transactions) {
+		switch {
+		case !slices.Contains(accountNames, t.Account),
+			!from.IsZero() && t.Date.Before(from),
+			!filter.Match(portfolio.Balance{AssetSymbol: t.Symbol}),
+			len(args.Types) > 0 && !slices.ContainsFunc(args.Types, func(s string) bool { return strings.EqualFold(s, t.Type) }):
+			continue
+		}
+
+		result.Transactions = append(result.Transactions, t)
+	}
+
+	result.Matched = len(result.Transactions)
+	result.Transactions = portfolio.Page(result.Transactions, 0, args.Limit)
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: result.Description(),
+			},
+		},
+	}, result, nil
+}
@@ -0,0 +1,106 @@
+package server
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/chickenzord/goksei"
+	"github.com/chickenzord/portosync/internal/portfolio"
+	"github.com/chickenzord/portosync/internal/snapshot"
+	"github.com/chickenzord/portosync/internal/source"
+	"github.com/chickenzord/portosync/internal/transaction"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMCP_handleListTransactions(t *testing.T) {
+	dir := t.TempDir()
+
+	store, err := snapshot.NewStore(filepath.Join(dir, "snapshots"))
+	require.NoError(t, err)
+
+	for _, s := range []portfolio.Snapshot{
+		{Date: mustDate(t, "2024-01-01"), Balances: []portfolio.Balance{
+			{SourceAccount: "personal", AssetSymbol: "BBCA", AssetType: "equity", UnitsAmount: 100, UnitsValue: 900000},
+			{SourceAccount: "business", AssetSymbol: "TLKM", AssetType: "equity", UnitsAmount: 100, UnitsValue: 400000},
+		}},
+		{Date: mustDate(t, "2024-01-05"), Balances: []portfolio.Balance{
+			{SourceAccount: "personal", AssetSymbol: "BBCA", AssetType: "equity", UnitsAmount: 200, UnitsValue: 2000000},
+			{SourceAccount: "business", AssetSymbol: "TLKM", AssetType: "equity", UnitsAmount: 50, UnitsValue: 200000},
+		}},
+		{Date: mustDate(t, "2024-01-10"), Balances: []portfolio.Balance{
+			{SourceAccount: "personal", AssetSymbol: "BBCA", AssetType: "equity", UnitsAmount: 150, UnitsValue: 1500000},
+			{SourceAccount: "business", AssetSymbol: "TLKM", AssetType: "equity", UnitsAmount: 50, UnitsValue: 200000},
+		}},
+	} {
+		require.NoError(t, store.Save(s))
+	}
+
+	transactions := filepath.Join(dir, "transactions.csv")
+	require.NoError(t, os.WriteFile(transactions, []byte(`date,account,symbol,type,units,price,fee
+2024-01-03,personal,BBCA,buy,100,9500,1425
+`), 0o600))
+
+	mcpServer := &MCP{
+		kseiClients: make(map[string]*goksei.Client),
+		sources: map[string]source.Source{
+			"personal": source.NewManual(filepath.Join(dir, "personal.yaml")),
+			"business": source.NewManual(filepath.Join(dir, "business.yaml")),
+		},
+		snapshots:        store,
+		transactionsFile: transactions,
+	}
+
+	ctx := context.Background()
+	req := &mcp.CallToolRequest{}
+
+	result, data, err := mcpServer.handleListTransactions(ctx, req, ListTransactionsArgs{})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	assert.Equal(t, 3, data.Matched)
+	assert.Equal(t, []transaction.Transaction{
+		{Date: mustDate(t, "2024-01-10"), Account: "personal", Symbol: "BBCA", Type: transaction.TypeSell, Units: 50, Price: 10000, Origin: transaction.OriginInferred},
+		{Date: mustDate(t, "2024-01-05"), Account: "business", Symbol: "TLKM", Type: transaction.TypeSell, Units: 50, Price: 4000, Origin: transaction.OriginInferred},
+		{Date: mustDate(t, "2024-01-03"), Account: "personal", Symbol: "BBCA", Type: transaction.TypeBuy, Units: 100, Price: 9500, Fee: 1425, Origin: transaction.OriginManual},
+	}, data.Transactions)
+	assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, "- 2024-01-03 personal buy 100.000000 units of BBCA at 9500.000000, value 950000.000000 (manual), fee 1425.000000")
+
+	_, data, err = mcpServer.handleListTransactions(ctx, req, ListTransactionsArgs{AccountNames: []string{"personal"}, Types: []string{"Buy"}})
+	require.NoError(t, err)
+	require.Len(t, data.Transactions, 1)
+	assert.Equal(t, transaction.OriginManual, data.Transactions[0].Origin)
+
+	_, data, err = mcpServer.handleListTransactions(ctx, req, ListTransactionsArgs{FromDate: "2024-01-04", ToDate: "2024-01-09"})
+	require.NoError(t, err)
+	require.Len(t, data.Transactions, 1)
+	assert.Equal(t, "TLKM", data.Transactions[0].Symbol)
+
+	_, data, err = mcpServer.handleListTransactions(ctx, req, ListTransactionsArgs{Symbols: []string{"bbca"}, Limit: 1})
+	require.NoError(t, err)
+	assert.Equal(t, 2, data.Matched)
+	require.Len(t, data.Transactions, 1)
+	assert.Equal(t, mustDate(t, "2024-01-10"), data.Transactions[0].Date)
+
+	for _, args := range []ListTransactionsArgs{
+		{Types: []string{"gift"}},
+		{FromDate: "yesterday"},
+		{AccountNames: []string{"unknown"}},
+	} {
+		result, _, err := mcpServer.handleListTransactions(ctx, req, args)
+		require.NoError(t, err)
+		assert.True(t, result.IsError, "%+v", args)
+	}
+}
+
+func mustDate(t *testing.T, s string) time.Time {
+	t.Helper()
+
+	date, err := portfolio.ParseDate(s)
+	require.NoError(t, err)
+
+	return date
+}
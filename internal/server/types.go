@@ -4,3 +4,9 @@ type Account struct {
 	Username string
 	Password string
 }
+
+// BrokerAccount is an account imported from broker CSV statements
+type BrokerAccount struct {
+	Profile string // name of the column mapping profile
+	Path    string // statement file or drop directory
+}
@@ -1,9 +1,19 @@
 package server
 
 import (
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
 	"sync"
 
 	"github.com/chickenzord/goksei"
+	"github.com/chickenzord/portosync/internal/portfolio"
+	"github.com/chickenzord/portosync/internal/price"
+	"github.com/chickenzord/portosync/internal/source"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"golang.org/x/sync/errgroup"
 )
 
@@ -15,16 +25,120 @@ var (
 	}
 )
 
+// accountSet holds selected accounts grouped by how their balances are fetched
+type accountSet struct {
+	kseiClients map[string]*goksei.Client
+	sources     map[string]source.Source
+}
+
+func (a accountSet) empty() bool {
+	return len(a.kseiClients) == 0 && len(a.sources) == 0
+}
+
+func (a accountSet) names() []string {
+	names := slices.Collect(maps.Keys(a.kseiClients))
+	names = append(names, slices.Collect(maps.Keys(a.sources))...)
+	slices.Sort(names)
+
+	return names
+}
+
+// selectTagged narrows accounts to those whose balances can carry any of the tags
+func (m *MCP) selectTagged(accounts accountSet, tags []string) accountSet {
+	candidates := m.tags.Candidates(accounts.names(), tags)
+	if len(candidates) == 0 {
+		return accountSet{}
+	}
+
+	return m.selectAccounts(candidates)
+}
+
+// accountTags returns the tags of the named accounts, leaving out untagged accounts
+func (m *MCP) accountTags(names []string) map[string][]string {
+	tags := make(map[string][]string)
+
+	for _, name := range names {
+		if t := m.tags.Account(name); len(t) > 0 {
+			tags[name] = t
+		}
+	}
+
+	return tags
+}
+
+// fetchBalances retrieves live balances from the selected accounts and records them
+// into today's snapshot when a snapshot store is configured
+func (m *MCP) fetchBalances(ctx context.Context, accounts accountSet) ([]portfolio.Balance, error) {
+	var errs errgroup.Group
+
+	var kseiBalances, sourceBalances []portfolio.Balance
+
+	errs.Go(func() (err error) {
+		kseiBalances, err = getAllBalances(accounts.kseiClients)
+
+		return err
+	})
+
+	errs.Go(func() (err error) {
+		sourceBalances, err = getSourceBalances(ctx, accounts.sources)
+
+		return err
+	})
+
+	if err := errs.Wait(); err != nil {
+		return nil, err
+	}
+
+	balances := append(kseiBalances, sourceBalances...)
+
+	changed := m.latest.update(accounts.names(), balances)
+
+	if m.snapshots != nil {
+		if err := m.snapshots.Merge(portfolio.Today(), accounts.names(), balances); err != nil {
+			// Snapshot failures should not prevent returning live data
+			fmt.Fprintf(os.Stderr, "Error recording snapshot: %v\n", err)
+		}
+	}
+
+	if m.prices != nil {
+		if _, err := m.prices.Put(price.FromBalances(portfolio.Today(), balances)); err != nil {
+			fmt.Fprintf(os.Stderr, "Error recording prices: %v\n", err)
+		}
+	}
+
+	// Notify once stored snapshots are up to date, subscribers may re-read them right away
+	m.notifyResourcesUpdated(ctx, changed)
+
+	return balances, nil
+}
+
 // getAllBalances retrieves all share balances from the client
 // in parallel using map-reduce pattern
-func getAllBalances(clients map[string]*goksei.Client) ([]Balance, error) {
+func getAllBalances(clients map[string]*goksei.Client) ([]portfolio.Balance, error) {
 	var mu sync.Mutex
 
 	var errs errgroup.Group
 
-	var balances []Balance
+	var balances []portfolio.Balance
 
 	for accountName, client := range clients {
+		errs.Go(func() error {
+			res, err := client.GetCashBalances()
+			if err != nil {
+				return err
+			}
+
+			mu.Lock()
+
+			for _, c := range res.Data {
+				balances = append(balances, cashBalance(accountName, c))
+			}
+
+			mu.Unlock()
+
+			return nil
+		})
+
 		for _, portfolioType := range allPortfolioTypes {
 			errs.Go(func() error {
 				res, err := client.GetShareBalances(portfolioType)
@@ -35,7 +149,7 @@ func getAllBalances(clients map[string]*goksei.Client) ([]Balance, error) {
 				mu.Lock()
 
 				for _, b := range res.Data {
-					balance := Balance{
+					balance := portfolio.Balance{
 						SourceType:    "ksei", // TODO: generalize this for other source
 						SourceAccount: accountName,
 						AssetSymbol:   b.Symbol(),
@@ -44,6 +158,8 @@ func getAllBalances(clients map[string]*goksei.Client) ([]Balance, error) {
 						UnitsCurrency: b.Currency,
 						UnitsAmount:   b.Amount,
 						UnitsValue:    b.CurrentValue(),
+						Participant:   strings.TrimSpace(b.Participant),
+						SubAccount:    b.Account,
 					}
 
 					mutualFund, ok := goksei.MutualFundByCode(b.Symbol())
@@ -68,3 +184,89 @@ func getAllBalances(clients map[string]*goksei.Client) ([]Balance, error) {
 
 	return balances, nil
 }
+
+// cashBalance converts a KSEI cash (RDN) balance into a Balance using the currency code as symbol
+func cashBalance(accountName string, c goksei.CashBalance) portfolio.Balance {
+	amount := c.Balance
+	if c.Currency == "IDR" {
+		amount = c.CurrentBalance()
+	}
+
+	bankName, ok := goksei.CustodianBankNameByID(c.BankID)
+	if !ok {
+		bankName = c.BankID
+	}
+
+	return portfolio.Balance{
+		SourceType:    "ksei",
+		SourceAccount: accountName,
+		AssetSymbol:   c.Currency,
+		AssetName:     strings.TrimSpace(fmt.Sprintf("RDN %s %s", bankName, c.AccountNumber)),
+		AssetType:     portfolio.AssetTypeCash,
+		UnitsCurrency: c.Currency,
+		UnitsAmount:   amount,
+		UnitsValue:    amount,
+		Participant:   bankName,
+		SubAccount:    c.AccountNumber,
+	}
+}
+
+// getSourceBalances retrieves balances from non-KSEI sources in parallel
+func getSourceBalances(ctx context.Context, sources map[string]source.Source) ([]portfolio.Balance, error) {
+	var mu sync.Mutex
+
+	var errs errgroup.Group
+
+	var balances []portfolio.Balance
+
+	for accountName, src := range sources {
+		errs.Go(func() error {
+			res, err := src.Balances(ctx)
+			if err != nil {
+				return fmt.Errorf("%s: %w", accountName, err)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			for _, b := range res {
+				b.SourceAccount = accountName
+				balances = append(balances, b)
+			}
+
+			return nil
+		})
+	}
+
+	if err := errs.Wait(); err != nil {
+		return nil, err
+	}
+
+	return balances, nil
+}
+
+// accountsNotFoundResult reports that none of the requested accounts are configured
+func accountsNotFoundResult(available []string) *mcp.CallToolResult {
+	return errorResult("Selected accounts not found, available accounts are " + strings.Join(available, ", "))
+}
+
+// tagsNotFoundResult reports that none of the subjects, e.g. accounts, carry the selected tags
+func tagsNotFoundResult(subjects string, available []string) *mcp.CallToolResult {
+	if len(available) == 0 {
+		return errorResult("No " + subjects + " are tagged, tags are not configured")
+	}
+
+	return errorResult("No " + subjects + " are tagged with the selected tags, configured tags are " + strings.Join(available, ", "))
+}
+
+// errorResult builds a tool result reporting an error the model can act upon
+func errorResult(text string) *mcp.CallToolResult {
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: text,
+			},
+		},
+		IsError: true,
+	}
+}
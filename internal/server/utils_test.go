@@ -0,0 +1,56 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/chickenzord/goksei"
+	"github.com/chickenzord/portosync/internal/portfolio"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCashBalance(t *testing.T) {
+	tests := []struct {
+		name     string
+		cash     goksei.CashBalance
+		expected portfolio.Balance
+	}{
+		{
+			name: "known bank in IDR",
+			cash: goksei.CashBalance{AccountNumber: "1234567890", BankID: "JAGO1", Currency: "IDR", Balance: 0, BalanceIDR: 2500000},
+			expected: portfolio.Balance{
+				SourceType:    "ksei",
+				SourceAccount: "personal",
+				AssetSymbol:   "IDR",
+				AssetName:     "RDN PT Bank Jago Tbk 1234567890",
+				AssetType:     "cash",
+				UnitsAmount:   2500000,
+				UnitsValue:    2500000,
+				UnitsCurrency: "IDR",
+				Participant:   "PT Bank Jago Tbk",
+				SubAccount:    "1234567890",
+			},
+		},
+		{
+			name: "unknown bank in USD keeps original currency amount",
+			cash: goksei.CashBalance{AccountNumber: "987", BankID: "XYZ", Currency: "USD", Balance: 100, BalanceIDR: 1600000},
+			expected: portfolio.Balance{
+				SourceType:    "ksei",
+				SourceAccount: "personal",
+				AssetSymbol:   "USD",
+				AssetName:     "RDN XYZ 987",
+				AssetType:     "cash",
+				UnitsAmount:   100,
+				UnitsValue:    100,
+				UnitsCurrency: "USD",
+				Participant:   "XYZ",
+				SubAccount:    "987",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, cashBalance("personal", tt.cash))
+		})
+	}
+}
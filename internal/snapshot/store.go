@@ -0,0 +1,178 @@
+// Package snapshot stores daily portfolio snapshots on the local filesystem
+package snapshot
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chickenzord/portosync/internal/datafile"
+	"github.com/chickenzord/portosync/internal/portfolio"
+)
+
+const fileExt = ".json"
+
+// Store keeps one JSON file per snapshot date inside a directory
+type Store struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewStore creates a snapshot store in dir, creating the directory if needed
+func NewStore(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("cannot create snapshot dir: %w", err)
+	}
+
+	return &Store{dir: dir}, nil
+}
+
+func (s *Store) path(date time.Time) string {
+	return filepath.Join(s.dir, date.Format(time.DateOnly)+fileExt)
+}
+
+// Save writes the snapshot, replacing any existing snapshot for the same date
+func (s *Store) Save(snapshot portfolio.Snapshot) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.save(snapshot)
+}
+
+func (s *Store) save(snapshot portfolio.Snapshot) error {
+	snapshot.Date = portfolio.DateOf(snapshot.Date)
+
+	return datafile.WriteJSON(s.path(snapshot.Date), snapshot)
+}
+
+// Merge records balances fetched from the given accounts into the snapshot of the given date.
+// Balances previously recorded for those accounts are replaced, other accounts are kept as is.
+// The accounts are recorded as fetched even when they hold nothing.
+func (s *Store) Merge(date time.Time, accounts []string, balances []portfolio.Balance) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, err := s.get(date)
+	if err != nil {
+		return err
+	}
+
+	merged := portfolio.Snapshot{Date: date, Accounts: slices.Clone(accounts)}
+
+	if existing != nil {
+		for _, b := range existing.Balances {
+			if !slices.Contains(accounts, b.SourceAccount) {
+				merged.Balances = append(merged.Balances, b)
+			}
+		}
+
+		merged.Accounts = append(merged.Accounts, existing.FetchedAccounts()...)
+	}
+
+	merged.Balances = append(merged.Balances, balances...)
+
+	slices.Sort(merged.Accounts)
+	merged.Accounts = slices.Compact(merged.Accounts)
+
+	return s.save(merged)
+}
+
+// Get returns the snapshot for the given date, or nil if none was recorded
+func (s *Store) Get(date time.Time) (*portfolio.Snapshot, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.get(date)
+}
+
+func (s *Store) get(date time.Time) (*portfolio.Snapshot, error) {
+	var snapshot portfolio.Snapshot
+
+	found, err := datafile.ReadJSON(s.path(portfolio.DateOf(date)), &snapshot)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read snapshot %s: %w", date.Format(time.DateOnly), err)
+	}
+
+	if !found {
+		return nil, nil
+	}
+
+	// Decoded dates carry a fixed zone, use the local date of the file instead
+	snapshot.Date = portfolio.DateOf(date)
+
+	return &snapshot, nil
+}
+
+// Dates returns all recorded snapshot dates in ascending order
+func (s *Store) Dates() ([]time.Time, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var dates []time.Time
+
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), fileExt)
+		if entry.IsDir() || !ok {
+			continue
+		}
+
+		date, err := portfolio.ParseDate(name)
+		if err != nil {
+			continue
+		}
+
+		dates = append(dates, date)
+	}
+
+	slices.SortFunc(dates, time.Time.Compare)
+
+	return dates, nil
+}
+
+// Range returns all snapshots between from and to (inclusive) in ascending date order.
+// A zero from or to leaves that side of the range open.
+func (s *Store) Range(from, to time.Time) ([]portfolio.Snapshot, error) {
+	dates, err := s.Dates()
+	if err != nil {
+		return nil, err
+	}
+
+	var snapshots []portfolio.Snapshot
+
+	for _, date := range dates {
+		if !from.IsZero() && date.Before(portfolio.DateOf(from)) {
+			continue
+		}
+
+		if !to.IsZero() && date.After(portfolio.DateOf(to)) {
+			continue
+		}
+
+		snapshot, err := s.Get(date)
+		if err != nil {
+			return nil, err
+		}
+
+		if snapshot != nil {
+			snapshots = append(snapshots, *snapshot)
+		}
+	}
+
+	return snapshots, nil
+}
+
+// Latest returns the most recent snapshot, or nil if the store is empty
+func (s *Store) Latest() (*portfolio.Snapshot, error) {
+	dates, err := s.Dates()
+	if err != nil || len(dates) == 0 {
+		return nil, err
+	}
+
+	return s.Get(dates[len(dates)-1])
+}
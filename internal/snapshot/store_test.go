@@ -0,0 +1,131 @@
+package snapshot
+
+import (
+	"testing"
+	"time"
+
+	"github.com/chickenzord/portosync/internal/portfolio"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func date(s string) time.Time {
+	d, err := portfolio.ParseDate(s)
+	if err != nil {
+		panic(err)
+	}
+
+	return d
+}
+
+func TestStore_SaveAndGet(t *testing.T) {
+	store, err := NewStore(t.TempDir())
+	require.NoError(t, err)
+
+	snapshot := portfolio.Snapshot{
+		Date: date("2026-01-02"),
+		Balances: []portfolio.Balance{
+			{SourceAccount: "personal", AssetSymbol: "BBCA", UnitsAmount: 100, UnitsValue: 1000000},
+		},
+	}
+
+	require.NoError(t, store.Save(snapshot))
+
+	got, err := store.Get(date("2026-01-02"))
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, "2026-01-02", got.DateString())
+	assert.Equal(t, snapshot.Balances, got.Balances)
+
+	missing, err := store.Get(date("2026-01-03"))
+	assert.NoError(t, err)
+	assert.Nil(t, missing)
+}
+
+func TestStore_Merge(t *testing.T) {
+	store, err := NewStore(t.TempDir())
+	require.NoError(t, err)
+
+	day := date("2026-01-02")
+
+	require.NoError(t, store.Merge(day, []string{"personal", "business"}, []portfolio.Balance{
+		{SourceAccount: "personal", AssetSymbol: "BBCA"},
+		{SourceAccount: "business", AssetSymbol: "TLKM"},
+	}))
+
+	require.NoError(t, store.Merge(day, []string{"personal"}, []portfolio.Balance{
+		{SourceAccount: "personal", AssetSymbol: "BBRI"},
+	}))
+
+	got, err := store.Get(day)
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.ElementsMatch(t, []portfolio.Balance{
+		{SourceAccount: "business", AssetSymbol: "TLKM"},
+		{SourceAccount: "personal", AssetSymbol: "BBRI"},
+	}, got.Balances)
+
+	// Accounts fetched without holdings are recorded too
+	require.NoError(t, store.Merge(day, []string{"vault"}, nil))
+
+	got, err = store.Get(day)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"business", "personal", "vault"}, got.Accounts)
+	assert.Len(t, got.Balances, 2)
+}
+
+func TestStore_Range(t *testing.T) {
+	store, err := NewStore(t.TempDir())
+	require.NoError(t, err)
+
+	for _, d := range []string{"2026-01-03", "2026-01-01", "2026-01-02", "2026-01-05"} {
+		require.NoError(t, store.Save(portfolio.Snapshot{Date: date(d)}))
+	}
+
+	tests := []struct {
+		name     string
+		from     time.Time
+		to       time.Time
+		expected []string
+	}{
+		{
+			name:     "open range",
+			expected: []string{"2026-01-01", "2026-01-02", "2026-01-03", "2026-01-05"},
+		},
+		{
+			name:     "bounded range",
+			from:     date("2026-01-02"),
+			to:       date("2026-01-03"),
+			expected: []string{"2026-01-02", "2026-01-03"},
+		},
+		{
+			name:     "from only",
+			from:     date("2026-01-04"),
+			expected: []string{"2026-01-05"},
+		},
+		{
+			name:     "empty range",
+			from:     date("2026-02-01"),
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			snapshots, err := store.Range(tt.from, tt.to)
+			require.NoError(t, err)
+
+			var dates []string
+			for _, s := range snapshots {
+				dates = append(dates, s.DateString())
+			}
+
+			assert.Equal(t, tt.expected, dates)
+		})
+	}
+
+	latest, err := store.Latest()
+	require.NoError(t, err)
+	require.NotNil(t, latest)
+	assert.Equal(t, "2026-01-05", latest.DateString())
+}
@@ -0,0 +1,237 @@
+package source
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/chickenzord/portosync/internal/datafile"
+	"github.com/chickenzord/portosync/internal/portfolio"
+	"gopkg.in/yaml.v3"
+)
+
+// TypeBrokerCSV is the source type of balances imported from broker CSV statements
+const TypeBrokerCSV = "broker_csv"
+
+// DefaultBrokerProfileName is the name of the built-in profile
+const DefaultBrokerProfileName = "default"
+
+// BrokerProfile describes how to read positions from a broker CSV export
+type BrokerProfile struct {
+	// Columns maps balance fields (symbol, name, type, sub_type, amount, price, value, currency)
+	// to CSV header names. Unmapped fields default to a column with the field name.
+	Columns map[string]string `yaml:"columns"`
+
+	// Delimiter separates CSV fields, defaults to ","
+	Delimiter string `yaml:"delimiter"`
+
+	// DecimalComma parses numbers like "1.234,56" used by Indonesian exports
+	DecimalComma bool `yaml:"decimal_comma"`
+
+	// SkipLines is the number of lines before the header row
+	SkipLines int `yaml:"skip_lines"`
+
+	// AssetType and Currency are used for rows that do not provide them
+	AssetType string `yaml:"asset_type"`
+	Currency  string `yaml:"currency"`
+}
+
+// DefaultBrokerProfile reads CSV files using the balance field names as headers
+var DefaultBrokerProfile = BrokerProfile{}
+
+func (p BrokerProfile) column(field string) string {
+	return strings.ToLower(cmp.Or(p.Columns[field], field))
+}
+
+func (p BrokerProfile) comma() rune {
+	if p.Delimiter == "" {
+		return ','
+	}
+
+	if p.Delimiter == `\t` {
+		return '\t'
+	}
+
+	return []rune(p.Delimiter)[0]
+}
+
+// LoadBrokerProfiles reads broker profiles from a YAML file with a top-level "profiles" map.
+// The built-in default profile is always included unless overridden.
+func LoadBrokerProfiles(path string) (map[string]BrokerProfile, error) {
+	profiles := map[string]BrokerProfile{
+		DefaultBrokerProfileName: DefaultBrokerProfile,
+	}
+
+	if path == "" {
+		return profiles, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc struct {
+		Profiles map[string]BrokerProfile `yaml:"profiles"`
+	}
+
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("cannot parse broker profiles %s: %w", path, err)
+	}
+
+	for name, profile := range doc.Profiles {
+		profiles[name] = profile
+	}
+
+	return profiles, nil
+}
+
+// BrokerCSV imports positions from CSV statements exported by a broker.
+// The path is either a CSV file or a drop directory, in which case the most
+// recently modified CSV file in the directory is used.
+type BrokerCSV struct {
+	path    string
+	profile BrokerProfile
+	file    watchedFile
+}
+
+// NewBrokerCSV creates a broker CSV source reading statements from path using profile
+func NewBrokerCSV(path string, profile BrokerProfile) *BrokerCSV {
+	b := &BrokerCSV{
+		path:    path,
+		profile: profile,
+	}
+	b.file.load = b.load
+
+	return b
+}
+
+func (b *BrokerCSV) Type() string {
+	return TypeBrokerCSV
+}
+
+// Balances returns the positions in the latest statement
+func (b *BrokerCSV) Balances(ctx context.Context) ([]portfolio.Balance, error) {
+	path, err := b.statementPath()
+	if err != nil {
+		return nil, err
+	}
+
+	balances, err := b.file.get(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot load broker statement %s: %w", path, err)
+	}
+
+	return balances, nil
+}
+
+// statementPath resolves the statement file, picking the latest CSV file in a drop directory
+func (b *BrokerCSV) statementPath() (string, error) {
+	info, err := os.Stat(b.path)
+	if err != nil {
+		return "", err
+	}
+
+	if !info.IsDir() {
+		return b.path, nil
+	}
+
+	entries, err := os.ReadDir(b.path)
+	if err != nil {
+		return "", err
+	}
+
+	var (
+		latest     string
+		latestTime time.Time
+	)
+
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.EqualFold(filepath.Ext(entry.Name()), ".csv") {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return "", err
+		}
+
+		if latest == "" || info.ModTime().After(latestTime) {
+			latest = filepath.Join(b.path, entry.Name())
+			latestTime = info.ModTime()
+		}
+	}
+
+	if latest == "" {
+		return "", fmt.Errorf("no CSV statement found in %s", b.path)
+	}
+
+	return latest, nil
+}
+
+func (b *BrokerCSV) load(path string) ([]portfolio.Balance, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// Skip preamble lines some brokers put before the header row
+	if b.profile.SkipLines > 0 {
+		lines := bytes.SplitN(data, []byte("\n"), b.profile.SkipLines+1)
+		if len(lines) <= b.profile.SkipLines {
+			return nil, fmt.Errorf("statement has less than %d lines", b.profile.SkipLines)
+		}
+
+		data = lines[b.profile.SkipLines]
+	}
+
+	_, records, err := datafile.ReadCSV(bytes.NewReader(data), b.profile.comma())
+	if err != nil {
+		return nil, err
+	}
+
+	p := b.profile
+	balances := make([]portfolio.Balance, 0, len(records))
+
+	for i, r := range records {
+		symbol := r[p.column("symbol")]
+		if symbol == "" {
+			// Brokers commonly append total or footer rows without symbol
+			continue
+		}
+
+		values := make(map[string]float64, 3)
+
+		for _, field := range []string{"amount", "price", "value"} {
+			v, err := parseNumberWith(r[p.column(field)], p.DecimalComma)
+			if err != nil {
+				return nil, fmt.Errorf("row %d: %s: %w", p.SkipLines+i+2, field, err)
+			}
+
+			values[field] = v
+		}
+
+		value := values["value"]
+		if value == 0 {
+			value = values["amount"] * values["price"]
+		}
+
+		balances = append(balances, portfolio.Balance{
+			SourceType:    TypeBrokerCSV,
+			AssetSymbol:   symbol,
+			AssetName:     r[p.column("name")],
+			AssetType:     cmp.Or(r[p.column("type")], p.AssetType),
+			AssetSubType:  r[p.column("sub_type")],
+			UnitsAmount:   values["amount"],
+			UnitsValue:    value,
+			UnitsCurrency: strings.ToUpper(cmp.Or(r[p.column("currency")], p.Currency, defaultCurrency)),
+		})
+	}
+
+	return balances, nil
+}
@@ -0,0 +1,98 @@
+package source
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/chickenzord/portosync/internal/portfolio"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadBrokerProfiles(t *testing.T) {
+	path := writeFile(t, "profiles.yaml", `
+profiles:
+  ibkr:
+    currency: USD
+    asset_type: equity
+    columns:
+      symbol: Symbol
+      amount: Quantity
+      value: Market Value
+`)
+
+	profiles, err := LoadBrokerProfiles(path)
+	require.NoError(t, err)
+	assert.Contains(t, profiles, DefaultBrokerProfileName)
+	require.Contains(t, profiles, "ibkr")
+	assert.Equal(t, "USD", profiles["ibkr"].Currency)
+	assert.Equal(t, "market value", profiles["ibkr"].column("value"))
+	assert.Equal(t, "name", profiles["ibkr"].column("name"))
+
+	profiles, err = LoadBrokerProfiles("")
+	require.NoError(t, err)
+	assert.Len(t, profiles, 1)
+}
+
+func TestBrokerCSV_Profile(t *testing.T) {
+	profile := BrokerProfile{
+		Columns: map[string]string{
+			"symbol": "Kode Saham",
+			"name":   "Nama",
+			"amount": "Jumlah Lembar",
+			"price":  "Harga",
+		},
+		Delimiter:    ";",
+		DecimalComma: true,
+		SkipLines:    2,
+		AssetType:    "equity",
+	}
+
+	path := writeFile(t, "statement.csv", ""+
+		"Laporan Portofolio\n"+
+		"Periode: Januari 2026\n"+
+		"Kode Saham;Nama;Jumlah Lembar;Harga\n"+
+		"BBCA;Bank Central Asia;1.500;9.525,5\n"+
+		";Total;;\n")
+
+	balances, err := NewBrokerCSV(path, profile).Balances(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, []portfolio.Balance{
+		{SourceType: "broker_csv", AssetSymbol: "BBCA", AssetName: "Bank Central Asia", AssetType: "equity", UnitsAmount: 1500, UnitsValue: 14288250, UnitsCurrency: "IDR"},
+	}, balances)
+}
+
+func TestBrokerCSV_DropDirectory(t *testing.T) {
+	dir := t.TempDir()
+	older := filepath.Join(dir, "2026-01.csv")
+	newer := filepath.Join(dir, "2026-02.csv")
+
+	require.NoError(t, os.WriteFile(older, []byte("symbol,amount,value\nOLD,1,100\n"), 0o600))
+	require.NoError(t, os.WriteFile(newer, []byte("symbol,amount,value\nNEW,2,200\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("ignored"), 0o600))
+	require.NoError(t, os.Chtimes(older, time.Now(), time.Now().Add(-time.Hour)))
+
+	src := NewBrokerCSV(dir, DefaultBrokerProfile)
+
+	balances, err := src.Balances(context.Background())
+	require.NoError(t, err)
+	require.Len(t, balances, 1)
+	assert.Equal(t, "NEW", balances[0].AssetSymbol)
+
+	// Dropping a newer statement switches to it
+	latest := filepath.Join(dir, "2026-03.csv")
+	require.NoError(t, os.WriteFile(latest, []byte("symbol,amount,value\nLATEST,3,300\n"), 0o600))
+	require.NoError(t, os.Chtimes(latest, time.Now(), time.Now().Add(time.Hour)))
+
+	balances, err = src.Balances(context.Background())
+	require.NoError(t, err)
+	require.Len(t, balances, 1)
+	assert.Equal(t, "LATEST", balances[0].AssetSymbol)
+
+	_, err = NewBrokerCSV(t.TempDir(), DefaultBrokerProfile).Balances(context.Background())
+	assert.Error(t, err)
+}
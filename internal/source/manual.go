@@ -0,0 +1,166 @@
+package source
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/chickenzord/portosync/internal/datafile"
+	"github.com/chickenzord/portosync/internal/portfolio"
+	"gopkg.in/yaml.v3"
+)
+
+// TypeManual is the source type of manually maintained holdings
+const TypeManual = "manual"
+
+// defaultCurrency is used for manual holdings without currency
+const defaultCurrency = "IDR"
+
+// manualHolding is a single holding entry in a manual holdings file
+type manualHolding struct {
+	Symbol   string  `yaml:"symbol"`
+	Name     string  `yaml:"name"`
+	Type     string  `yaml:"type"`
+	SubType  string  `yaml:"sub_type"`
+	Amount   float64 `yaml:"amount"`
+	Price    float64 `yaml:"price"` // used to compute value when value is not set
+	Value    float64 `yaml:"value"`
+	Currency string  `yaml:"currency"`
+}
+
+func (h manualHolding) balance() (portfolio.Balance, error) {
+	if h.Symbol == "" {
+		return portfolio.Balance{}, fmt.Errorf("holding without symbol")
+	}
+
+	value := h.Value
+	if value == 0 {
+		value = h.Amount * h.Price
+	}
+
+	currency := strings.ToUpper(h.Currency)
+	if currency == "" {
+		currency = defaultCurrency
+	}
+
+	return portfolio.Balance{
+		SourceType:    TypeManual,
+		AssetSymbol:   h.Symbol,
+		AssetName:     h.Name,
+		AssetType:     h.Type,
+		AssetSubType:  h.SubType,
+		UnitsAmount:   h.Amount,
+		UnitsValue:    value,
+		UnitsCurrency: currency,
+	}, nil
+}
+
+// Manual reads holdings from a local YAML or CSV file.
+// The file is watched for changes and reloaded when modified.
+type Manual struct {
+	path string
+	file watchedFile
+}
+
+// NewManual creates a manual source reading holdings from path
+func NewManual(path string) *Manual {
+	return &Manual{
+		path: path,
+		file: watchedFile{load: loadManualFile},
+	}
+}
+
+func (m *Manual) Type() string {
+	return TypeManual
+}
+
+// Balances returns the holdings in the file, reloading it if it changed since the last call
+func (m *Manual) Balances(ctx context.Context) ([]portfolio.Balance, error) {
+	balances, err := m.file.get(m.path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot load manual holdings %s: %w", m.path, err)
+	}
+
+	return balances, nil
+}
+
+func loadManualFile(path string) ([]portfolio.Balance, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var holdings []manualHolding
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		holdings, err = parseManualYAML(data)
+	case ".csv":
+		holdings, err = parseManualCSV(data)
+	default:
+		return nil, fmt.Errorf("unsupported file extension, use .yaml, .yml or .csv")
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	balances := make([]portfolio.Balance, 0, len(holdings))
+
+	for i, h := range holdings {
+		b, err := h.balance()
+		if err != nil {
+			return nil, fmt.Errorf("holding #%d: %w", i+1, err)
+		}
+
+		balances = append(balances, b)
+	}
+
+	return balances, nil
+}
+
+// parseManualYAML parses a YAML document with a top-level "holdings" list
+func parseManualYAML(data []byte) ([]manualHolding, error) {
+	var doc struct {
+		Holdings []manualHolding `yaml:"holdings"`
+	}
+
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+
+	return doc.Holdings, nil
+}
+
+// parseManualCSV parses a CSV file with the same column names as the YAML keys
+func parseManualCSV(data []byte) ([]manualHolding, error) {
+	_, records, err := datafile.ReadCSV(bytes.NewReader(data), ',')
+	if err != nil {
+		return nil, err
+	}
+
+	holdings := make([]manualHolding, 0, len(records))
+
+	for i, r := range records {
+		h := manualHolding{
+			Symbol:   r["symbol"],
+			Name:     r["name"],
+			Type:     r["type"],
+			SubType:  r["sub_type"],
+			Currency: r["currency"],
+		}
+
+		for field, dst := range map[string]*float64{"amount": &h.Amount, "price": &h.Price, "value": &h.Value} {
+			if *dst, err = parseNumber(r[field]); err != nil {
+				return nil, fmt.Errorf("row %d: %s: %w", i+2, field, err)
+			}
+		}
+
+		holdings = append(holdings, h)
+	}
+
+	return holdings, nil
+}
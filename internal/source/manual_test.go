@@ -0,0 +1,96 @@
+package source
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/chickenzord/portosync/internal/portfolio"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	return path
+}
+
+func TestManual_YAML(t *testing.T) {
+	path := writeFile(t, "holdings.yaml", `
+holdings:
+  - symbol: ANTAM-GOLD
+    name: Antam gold bars
+    type: commodity
+    sub_type: gold
+    amount: 50
+    price: 1500000
+  - symbol: HOUSE-BSD
+    name: House in BSD
+    type: property
+    amount: 1
+    value: 2500000000
+  - symbol: IBKR-VOO
+    type: equity
+    amount: 10
+    value: 5000
+    currency: usd
+`)
+
+	balances, err := NewManual(path).Balances(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, []portfolio.Balance{
+		{SourceType: "manual", AssetSymbol: "ANTAM-GOLD", AssetName: "Antam gold bars", AssetType: "commodity", AssetSubType: "gold", UnitsAmount: 50, UnitsValue: 75000000, UnitsCurrency: "IDR"},
+		{SourceType: "manual", AssetSymbol: "HOUSE-BSD", AssetName: "House in BSD", AssetType: "property", UnitsAmount: 1, UnitsValue: 2500000000, UnitsCurrency: "IDR"},
+		{SourceType: "manual", AssetSymbol: "IBKR-VOO", AssetType: "equity", UnitsAmount: 10, UnitsValue: 5000, UnitsCurrency: "USD"},
+	}, balances)
+}
+
+func TestManual_CSV(t *testing.T) {
+	path := writeFile(t, "holdings.csv", ""+
+		"Symbol,Name,Type,Amount,Value,Currency\n"+
+		"DEPO-BCA,BCA time deposit,deposit,1,\"100,000,000\",IDR\n")
+
+	balances, err := NewManual(path).Balances(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, []portfolio.Balance{
+		{SourceType: "manual", AssetSymbol: "DEPO-BCA", AssetName: "BCA time deposit", AssetType: "deposit", UnitsAmount: 1, UnitsValue: 100000000, UnitsCurrency: "IDR"},
+	}, balances)
+}
+
+func TestManual_Reload(t *testing.T) {
+	path := writeFile(t, "holdings.yaml", "holdings:\n  - {symbol: GOLD, amount: 1, value: 100}\n")
+	src := NewManual(path)
+
+	balances, err := src.Balances(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, balances, 1)
+
+	require.NoError(t, os.WriteFile(path, []byte("holdings:\n  - {symbol: GOLD, amount: 1, value: 100}\n  - {symbol: SILVER, amount: 2, value: 50}\n"), 0o600))
+	require.NoError(t, os.Chtimes(path, time.Now(), time.Now().Add(time.Second)))
+
+	balances, err = src.Balances(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, balances, 2)
+}
+
+func TestManual_Errors(t *testing.T) {
+	_, err := NewManual(filepath.Join(t.TempDir(), "missing.yaml")).Balances(context.Background())
+	assert.Error(t, err)
+
+	_, err = NewManual(writeFile(t, "holdings.txt", "")).Balances(context.Background())
+	assert.Error(t, err)
+
+	_, err = NewManual(writeFile(t, "holdings.yaml", "holdings:\n  - {name: no symbol}\n")).Balances(context.Background())
+	assert.Error(t, err)
+
+	_, err = NewManual(writeFile(t, "holdings.csv", "symbol,amount\nGOLD,abc\n")).Balances(context.Background())
+	assert.Error(t, err)
+}
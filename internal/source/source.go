@@ -0,0 +1,49 @@
+// Package source provides portfolio balances from data sources other than KSEI
+package source
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/chickenzord/portosync/internal/portfolio"
+)
+
+// Source provides the balances of a single account.
+// The caller is responsible for setting SourceAccount on returned balances.
+type Source interface {
+	// Type returns the SourceType set on balances provided by this source
+	Type() string
+
+	// Balances returns the current balances held in the account
+	Balances(ctx context.Context) ([]portfolio.Balance, error)
+}
+
+// parseNumber parses a decimal number, ignoring thousand separators
+func parseNumber(s string) (float64, error) {
+	return parseNumberWith(s, false)
+}
+
+// parseNumberWith parses a decimal number using either "." or "," as the decimal separator,
+// ignoring the other one as thousand separator
+func parseNumberWith(s string, decimalComma bool) (float64, error) {
+	s = strings.TrimSpace(s)
+	if decimalComma {
+		s = strings.ReplaceAll(s, ".", "")
+		s = strings.ReplaceAll(s, ",", ".")
+	} else {
+		s = strings.ReplaceAll(s, ",", "")
+	}
+
+	if s == "" {
+		return 0, nil
+	}
+
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid number %q", s)
+	}
+
+	return f, nil
+}
@@ -0,0 +1,46 @@
+package source
+
+import (
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/chickenzord/portosync/internal/portfolio"
+)
+
+// watchedFile caches balances loaded from a file until the file changes
+type watchedFile struct {
+	load func(path string) ([]portfolio.Balance, error)
+
+	mu       sync.Mutex
+	path     string
+	modTime  time.Time
+	size     int64
+	balances []portfolio.Balance
+}
+
+// get returns balances loaded from path, reloading them if the path or its content changed
+func (w *watchedFile) get(path string) ([]portfolio.Balance, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if w.balances == nil || path != w.path || !info.ModTime().Equal(w.modTime) || info.Size() != w.size {
+		balances, err := w.load(path)
+		if err != nil {
+			return nil, err
+		}
+
+		w.balances = balances
+		w.path = path
+		w.modTime = info.ModTime()
+		w.size = info.Size()
+	}
+
+	return slices.Clone(w.balances), nil
+}
@@ -0,0 +1,182 @@
+// Package tag assigns user-defined tags such as retirement or joint to accounts and holdings
+package tag
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/chickenzord/portosync/internal/portfolio"
+)
+
+// holdingSeparator separates the account from the symbol in account-specific holding keys
+const holdingSeparator = "/"
+
+// Tags maps accounts and holdings to their tags
+type Tags struct {
+	accounts map[string][]string // by account name
+	holdings map[string][]string // by symbol, or account/symbol for a holding in one account only
+}
+
+// New returns tags of accounts by account name and of holdings by symbol,
+// or by account/symbol (e.g. personal/BBCA) for a holding in one account only.
+// Tags are matched case-insensitively and stored in lower case.
+func New(accounts, holdings map[string][]string) Tags {
+	t := Tags{
+		accounts: make(map[string][]string, len(accounts)),
+		holdings: make(map[string][]string, len(holdings)),
+	}
+
+	for name, tags := range accounts {
+		if tags = Normalize(tags); len(tags) > 0 {
+			t.accounts[strings.TrimSpace(name)] = tags
+		}
+	}
+
+	for key, tags := range holdings {
+		if tags = Normalize(tags); len(tags) > 0 {
+			account, symbol := splitHoldingKey(key)
+			t.holdings[holdingKey(account, symbol)] = tags
+		}
+	}
+
+	return t
+}
+
+// Normalize returns the non-empty tags in lower case, sorted and without duplicates
+func Normalize(tags []string) []string {
+	var normalized []string
+
+	for _, tag := range tags {
+		if tag = strings.ToLower(strings.TrimSpace(tag)); tag != "" {
+			normalized = append(normalized, tag)
+		}
+	}
+
+	slices.Sort(normalized)
+
+	return slices.Compact(normalized)
+}
+
+// Validate reports an error when accounts or account-specific holdings refer to an account not among names
+func (t Tags) Validate(names []string) error {
+	for name := range t.accounts {
+		if !slices.Contains(names, name) {
+			return fmt.Errorf("tags refer to unknown account %q", name)
+		}
+	}
+
+	for key := range t.holdings {
+		if account, _ := splitHoldingKey(key); account != "" && !slices.Contains(names, account) {
+			return fmt.Errorf("tags of holding %q refer to unknown account %q", key, account)
+		}
+	}
+
+	return nil
+}
+
+// Empty reports whether no account or holding is tagged
+func (t Tags) Empty() bool {
+	return len(t.accounts) == 0 && len(t.holdings) == 0
+}
+
+// Names returns all configured tags, sorted
+func (t Tags) Names() []string {
+	var names []string
+
+	for _, tags := range t.accounts {
+		names = append(names, tags...)
+	}
+
+	for _, tags := range t.holdings {
+		names = append(names, tags...)
+	}
+
+	return Normalize(names)
+}
+
+// Account returns the tags of an account
+func (t Tags) Account(name string) []string {
+	return t.accounts[name]
+}
+
+// Holding returns the tags of a holding, including the tags of the account holding it
+func (t Tags) Holding(account, symbol string) []string {
+	return Normalize(slices.Concat(
+		t.accounts[account],
+		t.holdings[holdingKey("", symbol)],
+		t.holdings[holdingKey(account, symbol)],
+	))
+}
+
+// Apply sets the tags of balances
+func (t Tags) Apply(balances []portfolio.Balance) {
+	for i := range balances {
+		b := &balances[i]
+		b.Tags = t.Holding(b.SourceAccount, b.AssetSymbol)
+	}
+}
+
+// Accounts returns the names tagged with any of the selected tags
+func (t Tags) Accounts(names, selected []string) []string {
+	var tagged []string
+
+	for _, name := range names {
+		if Match(t.accounts[name], selected) {
+			tagged = append(tagged, name)
+		}
+	}
+
+	return tagged
+}
+
+// Candidates returns the names whose balances can carry any of the selected tags,
+// through either an account tag or a holding tag. Holdings tagged by symbol alone
+// can be held in any account, in which case all names are returned.
+func (t Tags) Candidates(names, selected []string) []string {
+	var candidates []string
+
+	for key, tags := range t.holdings {
+		if !Match(tags, selected) {
+			continue
+		}
+
+		account, _ := splitHoldingKey(key)
+		if account == "" {
+			return names
+		}
+
+		candidates = append(candidates, account)
+	}
+
+	return slices.DeleteFunc(slices.Clone(names), func(name string) bool {
+		return !slices.Contains(candidates, name) && !Match(t.accounts[name], selected)
+	})
+}
+
+// Match reports whether any of the tags is among the selected tags, ignoring case
+func Match(tags, selected []string) bool {
+	return slices.ContainsFunc(selected, func(s string) bool {
+		return slices.ContainsFunc(tags, func(tag string) bool {
+			return strings.EqualFold(tag, strings.TrimSpace(s))
+		})
+	})
+}
+
+func splitHoldingKey(key string) (account, symbol string) {
+	account, symbol, ok := strings.Cut(key, holdingSeparator)
+	if !ok {
+		return "", strings.ToUpper(strings.TrimSpace(key))
+	}
+
+	return strings.TrimSpace(account), strings.ToUpper(strings.TrimSpace(symbol))
+}
+
+func holdingKey(account, symbol string) string {
+	symbol = strings.ToUpper(strings.TrimSpace(symbol))
+	if account == "" {
+		return symbol
+	}
+
+	return account + holdingSeparator + symbol
+}
@@ -0,0 +1,59 @@
+package tag
+
+import (
+	"testing"
+
+	"github.com/chickenzord/portosync/internal/portfolio"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalize(t *testing.T) {
+	assert.Equal(t, []string{"joint", "retirement"}, Normalize([]string{" Retirement", "joint", "", "retirement"}))
+	assert.Empty(t, Normalize(nil))
+}
+
+func TestTags(t *testing.T) {
+	tags := New(
+		map[string][]string{"personal": {"Retirement", "joint"}, "kids": {"kids"}, "empty": {" "}},
+		map[string][]string{"bbca": {"core"}, "kids/TLKM": {"dividend"}},
+	)
+
+	assert.False(t, tags.Empty())
+	assert.True(t, New(nil, nil).Empty())
+	assert.Equal(t, []string{"joint", "retirement"}, tags.Account("personal"))
+	assert.Empty(t, tags.Account("empty"))
+	assert.Equal(t, []string{"core", "dividend", "joint", "kids", "retirement"}, tags.Names())
+
+	assert.Equal(t, []string{"core", "joint", "retirement"}, tags.Holding("personal", "BBCA"))
+	assert.Equal(t, []string{"dividend", "kids"}, tags.Holding("kids", "tlkm"))
+	assert.Equal(t, []string{"joint", "retirement"}, tags.Holding("personal", "TLKM"))
+	assert.Empty(t, tags.Holding("business", "TLKM"))
+
+	balances := []portfolio.Balance{
+		{SourceAccount: "kids", AssetSymbol: "BBCA"},
+		{SourceAccount: "business", AssetSymbol: "GOTO"},
+	}
+	tags.Apply(balances)
+	assert.Equal(t, []string{"core", "kids"}, balances[0].Tags)
+	assert.Empty(t, balances[1].Tags)
+
+	names := []string{"business", "kids", "personal"}
+	assert.Equal(t, []string{"personal"}, tags.Accounts(names, []string{"JOINT"}))
+	assert.Empty(t, tags.Accounts(names, []string{"dividend"}))
+
+	assert.Equal(t, []string{"personal"}, tags.Candidates(names, []string{"retirement"}))
+	assert.Equal(t, []string{"kids"}, tags.Candidates(names, []string{"dividend", "kids"}))
+	assert.Equal(t, names, tags.Candidates(names, []string{"core"}))
+	assert.Empty(t, tags.Candidates(names, []string{"unknown"}))
+
+	assert.NoError(t, tags.Validate(names))
+	assert.Error(t, tags.Validate([]string{"personal"}))
+	assert.Error(t, New(nil, map[string][]string{"joint/BBCA": {"core"}}).Validate(names))
+}
+
+func TestMatch(t *testing.T) {
+	assert.True(t, Match([]string{"core", "dividend"}, []string{"growth", " Dividend"}))
+	assert.False(t, Match([]string{"core"}, []string{"growth"}))
+	assert.False(t, Match(nil, []string{"core"}))
+	assert.False(t, Match([]string{"core"}, nil))
+}
@@ -0,0 +1,185 @@
+package transaction
+
+import (
+	"math"
+	"slices"
+	"time"
+
+	"github.com/chickenzord/portosync/internal/portfolio"
+)
+
+// Origins of transactions
+const (
+	OriginManual   = "manual"
+	OriginInferred = "inferred"
+)
+
+// unitsEpsilon ignores unit changes caused by floating point noise in fund unit balances
+const unitsEpsilon = 1e-6
+
+const mutualFundAssetType = "mutual_fund"
+
+// holding sums units and value of one symbol in one account across sub-accounts
+type holding struct {
+	assetType string
+	units     float64
+	value     float64
+}
+
+func (h holding) price() float64 {
+	if h.units == 0 {
+		return 0
+	}
+
+	return h.value / h.units
+}
+
+type holdingKey struct {
+	account string
+	symbol  string
+}
+
+func holdings(s portfolio.Snapshot) (map[holdingKey]holding, map[string]bool) {
+	result := make(map[holdingKey]holding)
+	accounts := make(map[string]bool)
+
+	for _, account := range s.FetchedAccounts() {
+		accounts[account] = true
+	}
+
+	for _, b := range s.Balances {
+		if b.IsCash() {
+			continue
+		}
+
+		k := holdingKey{account: b.SourceAccount, symbol: b.AssetSymbol}
+		h := result[k]
+		h.assetType = b.AssetType
+		h.units += b.UnitsAmount
+		h.value += b.UnitsValue
+		result[k] = h
+	}
+
+	return result, accounts
+}
+
+// Infer derives transactions from unit changes per symbol and account between two consecutive snapshots.
+// Accounts not fetched into either snapshot are skipped since their holdings were not observed.
+// Transactions are dated at the later snapshot and priced at the value per unit observed in it,
+// or in the earlier snapshot for holdings sold completely.
+func Infer(prev, curr portfolio.Snapshot) []Transaction {
+	before, beforeAccounts := holdings(prev)
+	after, afterAccounts := holdings(curr)
+
+	keys := make(map[holdingKey]bool, len(before)+len(after))
+	for k := range before {
+		keys[k] = true
+	}
+
+	for k := range after {
+		keys[k] = true
+	}
+
+	var transactions []Transaction
+
+	for k := range keys {
+		if !beforeAccounts[k.account] || !afterAccounts[k.account] {
+			continue
+		}
+
+		b, a := before[k], after[k]
+
+		delta := a.units - b.units
+		if math.Abs(delta) < unitsEpsilon {
+			continue
+		}
+
+		t := Transaction{
+			Date:    curr.Date,
+			Account: k.account,
+			Symbol:  k.symbol,
+			Units:   math.Abs(delta),
+			Price:   a.price(),
+			Origin:  OriginInferred,
+		}
+
+		assetType := a.assetType
+		if a.units == 0 {
+			t.Price = b.price()
+			assetType = b.assetType
+		}
+
+		switch {
+		case delta > 0 && assetType == mutualFundAssetType:
+			t.Type = TypeSubscription
+		case delta > 0:
+			t.Type = TypeBuy
+		case assetType == mutualFundAssetType:
+			t.Type = TypeRedemption
+		default:
+			t.Type = TypeSell
+		}
+
+		transactions = append(transactions, t)
+	}
+
+	Sort(transactions)
+
+	return transactions
+}
+
+// Derive infers transactions from snapshots ordered by date, and merges manual transactions.
+// Each account is compared with the last snapshot it was present in, so trades made while an account
+// was missing from some snapshots are still inferred.
+// Manual transactions correct inferred ones: an inferred transaction is dropped when a manual transaction
+// of the same account and symbol falls after the previous snapshot and on or before the inferred date.
+func Derive(snapshots []portfolio.Snapshot, manual []Transaction) []Transaction {
+	var transactions []Transaction
+
+	last := make(map[string]portfolio.Snapshot)
+
+	for _, snapshot := range snapshots {
+		for account, curr := range accountSnapshots(snapshot) {
+			if prev, ok := last[account]; ok {
+				for _, t := range Infer(prev, curr) {
+					if !corrected(t, prev.Date, manual) {
+						transactions = append(transactions, t)
+					}
+				}
+			}
+
+			last[account] = curr
+		}
+	}
+
+	transactions = append(transactions, manual...)
+	Sort(transactions)
+
+	return transactions
+}
+
+// accountSnapshots splits a snapshot into one snapshot per account fetched into it
+func accountSnapshots(s portfolio.Snapshot) map[string]portfolio.Snapshot {
+	result := make(map[string]portfolio.Snapshot)
+
+	for _, account := range s.FetchedAccounts() {
+		result[account] = portfolio.Snapshot{Date: s.Date, Accounts: []string{account}}
+	}
+
+	for _, b := range s.Balances {
+		a := result[b.SourceAccount]
+		a.Balances = append(a.Balances, b)
+		result[b.SourceAccount] = a
+	}
+
+	return result
+}
+
+func corrected(inferred Transaction, since time.Time, manual []Transaction) bool {
+	return slices.ContainsFunc(manual, func(m Transaction) bool {
+		return m.Account == inferred.Account &&
+			m.Symbol == inferred.Symbol &&
+			m.Date.After(since) &&
+			!m.Date.After(inferred.Date)
+	})
+}
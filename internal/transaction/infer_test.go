@@ -0,0 +1,92 @@
+package transaction
+
+import (
+	"testing"
+	"time"
+
+	"github.com/chickenzord/portosync/internal/portfolio"
+	"github.com/stretchr/testify/assert"
+)
+
+func date(s string) time.Time {
+	d, err := portfolio.ParseDate(s)
+	if err != nil {
+		panic(err)
+	}
+
+	return d
+}
+
+func TestInfer(t *testing.T) {
+	prev := portfolio.Snapshot{Date: date("2024-01-01"), Balances: []portfolio.Balance{
+		{SourceAccount: "personal", AssetSymbol: "BBCA", AssetType: "equity", UnitsAmount: 100, UnitsValue: 900000, SubAccount: "1"},
+		{SourceAccount: "personal", AssetSymbol: "BBCA", AssetType: "equity", UnitsAmount: 100, UnitsValue: 900000, SubAccount: "2"},
+		{SourceAccount: "personal", AssetSymbol: "TLKM", AssetType: "equity", UnitsAmount: 300, UnitsValue: 1200000},
+		{SourceAccount: "personal", AssetSymbol: "SCMMF", AssetType: "mutual_fund", UnitsAmount: 1000, UnitsValue: 1500000},
+		{SourceAccount: "personal", AssetSymbol: "IDR", AssetType: portfolio.AssetTypeCash, UnitsAmount: 5000, UnitsValue: 5000},
+		{SourceAccount: "removed", AssetSymbol: "GOTO", AssetType: "equity", UnitsAmount: 100, UnitsValue: 7000},
+	}}
+	curr := portfolio.Snapshot{Date: date("2024-01-03"), Balances: []portfolio.Balance{
+		{SourceAccount: "personal", AssetSymbol: "BBCA", AssetType: "equity", UnitsAmount: 100, UnitsValue: 1000000, SubAccount: "1"},
+		{SourceAccount: "personal", AssetSymbol: "BBCA", AssetType: "equity", UnitsAmount: 200, UnitsValue: 2000000, SubAccount: "2"},
+		{SourceAccount: "personal", AssetSymbol: "SCMMF", AssetType: "mutual_fund", UnitsAmount: 1200.5, UnitsValue: 1806752.5},
+		{SourceAccount: "personal", AssetSymbol: "IDR", AssetType: portfolio.AssetTypeCash, UnitsAmount: 100, UnitsValue: 100},
+		{SourceAccount: "added", AssetSymbol: "ANTM", AssetType: "equity", UnitsAmount: 100, UnitsValue: 150000},
+	}}
+
+	assert.Equal(t, []Transaction{
+		{Date: date("2024-01-03"), Account: "personal", Symbol: "BBCA", Type: TypeBuy, Units: 100, Price: 10000, Origin: OriginInferred},
+		{Date: date("2024-01-03"), Account: "personal", Symbol: "SCMMF", Type: TypeSubscription, Units: 200.5, Price: 1505, Origin: OriginInferred},
+		{Date: date("2024-01-03"), Account: "personal", Symbol: "TLKM", Type: TypeSell, Units: 300, Price: 4000, Origin: OriginInferred},
+	}, Infer(prev, curr))
+
+	assert.Empty(t, Infer(curr, curr))
+}
+
+func TestDerive(t *testing.T) {
+	snapshots := []portfolio.Snapshot{
+		{Date: date("2024-01-01"), Balances: []portfolio.Balance{
+			{SourceAccount: "personal", AssetSymbol: "BBCA", AssetType: "equity", UnitsAmount: 100, UnitsValue: 900000},
+		}},
+		{Date: date("2024-01-05"), Balances: []portfolio.Balance{
+			{SourceAccount: "personal", AssetSymbol: "BBCA", AssetType: "equity", UnitsAmount: 200, UnitsValue: 2000000},
+		}},
+		{Date: date("2024-01-10"), Balances: []portfolio.Balance{
+			{SourceAccount: "personal", AssetSymbol: "BBCA", AssetType: "equity", UnitsAmount: 150, UnitsValue: 1500000},
+		}},
+	}
+
+	manual := []Transaction{
+		{Date: date("2024-01-03"), Account: "personal", Symbol: "BBCA", Type: TypeBuy, Units: 100, Price: 9500, Fee: 1425, Origin: OriginManual},
+	}
+
+	assert.Equal(t, []Transaction{
+		{Date: date("2024-01-03"), Account: "personal", Symbol: "BBCA", Type: TypeBuy, Units: 100, Price: 9500, Fee: 1425, Origin: OriginManual},
+		{Date: date("2024-01-10"), Account: "personal", Symbol: "BBCA", Type: TypeSell, Units: 50, Price: 10000, Origin: OriginInferred},
+	}, Derive(snapshots, manual))
+}
+
+func TestDerive_AccountGap(t *testing.T) {
+	snapshots := []portfolio.Snapshot{
+		{Date: date("2024-01-01"), Balances: []portfolio.Balance{
+			{SourceAccount: "personal", AssetSymbol: "BBCA", AssetType: "equity", UnitsAmount: 100, UnitsValue: 900000},
+			{SourceAccount: "business", AssetSymbol: "TLKM", AssetType: "equity", UnitsAmount: 100, UnitsValue: 400000},
+		}},
+		{Date: date("2024-01-02"), Balances: []portfolio.Balance{
+			{SourceAccount: "business", AssetSymbol: "TLKM", AssetType: "equity", UnitsAmount: 100, UnitsValue: 410000},
+		}},
+		{Date: date("2024-01-03"), Balances: []portfolio.Balance{
+			{SourceAccount: "personal", AssetSymbol: "BBCA", AssetType: "equity", UnitsAmount: 300, UnitsValue: 3000000},
+			{SourceAccount: "business", AssetSymbol: "TLKM", AssetType: "equity", UnitsAmount: 100, UnitsValue: 420000},
+		}},
+	}
+
+	assert.Equal(t, []Transaction{
+		{Date: date("2024-01-03"), Account: "personal", Symbol: "BBCA", Type: TypeBuy, Units: 200, Price: 10000, Origin: OriginInferred},
+	}, Derive(snapshots, nil), "the buy made while personal was not fetched is inferred")
+
+	// An account fetched without holdings sold everything
+	emptied := portfolio.Snapshot{Date: date("2024-01-04"), Accounts: []string{"business", "personal"}, Balances: snapshots[2].Balances[1:]}
+	assert.Contains(t, Derive(append(snapshots, emptied), nil),
+		Transaction{Date: date("2024-01-04"), Account: "personal", Symbol: "BBCA", Type: TypeSell, Units: 300, Price: 10000, Origin: OriginInferred})
+}
@@ -0,0 +1,152 @@
+// Package transaction models buy and sell activity of portfolio holdings
+package transaction
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chickenzord/portosync/internal/datafile"
+	"github.com/chickenzord/portosync/internal/portfolio"
+)
+
+// Transaction types. Subscriptions and redemptions are the mutual fund equivalents of buys and sells.
+const (
+	TypeBuy          = "buy"
+	TypeSell         = "sell"
+	TypeSubscription = "subscription"
+	TypeRedemption   = "redemption"
+)
+
+// Types lists supported transaction types
+var Types = []string{TypeBuy, TypeSell, TypeSubscription, TypeRedemption}
+
+// Transaction is a change in units of a holding
+type Transaction struct {
+	Date    time.Time `json:"date"             jsonschema:"description:Transaction date"`
+	Account string    `json:"account"          jsonschema:"description:Account name holding the asset"`
+	Symbol  string    `json:"symbol"           jsonschema:"description:Asset symbol or fund code"`
+	Type    string    `json:"type"             jsonschema:"description:buy, sell, subscription or redemption"`
+	Units   float64   `json:"units"            jsonschema:"description:Number of units bought or sold, always positive"`
+	Price   float64   `json:"price"            jsonschema:"description:Price per unit"`
+	Fee     float64   `json:"fee,omitempty"    jsonschema:"description:Fees and taxes paid on the transaction"`
+	Note    string    `json:"note,omitempty"   jsonschema:"description:Free-text note"`
+	Origin  string    `json:"origin,omitempty" jsonschema:"description:manual when entered in the transactions file, inferred when derived from unit changes between snapshots"`
+}
+
+// Value returns the traded amount excluding fees
+func (t Transaction) Value() float64 {
+	return t.Units * t.Price
+}
+
+// IsIncrease reports whether the transaction adds units to the holding
+func (t Transaction) IsIncrease() bool {
+	return t.Type == TypeBuy || t.Type == TypeSubscription
+}
+
+// Load reads transactions from a CSV file with header columns
+// date, account, symbol, type, units, price and optionally fee and note.
+// Transactions are returned ordered by date.
+func Load(path string) ([]Transaction, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	transactions, err := Read(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return transactions, nil
+}
+
+// Read reads transactions in the CSV format described in Load
+func Read(r io.Reader) ([]Transaction, error) {
+	columns, records, err := datafile.ReadCSV(r, ',')
+	if err != nil {
+		return nil, err
+	}
+
+	if len(columns) == 0 {
+		return nil, nil
+	}
+
+	if err := datafile.RequireColumns(columns, "date", "account", "symbol", "type", "units", "price"); err != nil {
+		return nil, err
+	}
+
+	var transactions []Transaction
+
+	for i, record := range records {
+		if record.Get("symbol") == "" {
+			continue
+		}
+
+		t, err := parseRow(record.Get)
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", i+2, err)
+		}
+
+		transactions = append(transactions, t)
+	}
+
+	Sort(transactions)
+
+	return transactions, nil
+}
+
+func parseRow(field func(name string) string) (Transaction, error) {
+	date, err := portfolio.ParseDate(field("date"))
+	if err != nil {
+		return Transaction{}, fmt.Errorf("invalid date: %w", err)
+	}
+
+	t := Transaction{
+		Date:    date,
+		Account: field("account"),
+		Symbol:  strings.ToUpper(field("symbol")),
+		Type:    strings.ToLower(field("type")),
+		Note:    field("note"),
+		Origin:  OriginManual,
+	}
+
+	if !slices.Contains(Types, t.Type) {
+		return t, fmt.Errorf("unknown type %q, supported types are %s", t.Type, strings.Join(Types, ", "))
+	}
+
+	for name, dst := range map[string]*float64{"units": &t.Units, "price": &t.Price, "fee": &t.Fee} {
+		s := strings.ReplaceAll(field(name), ",", "")
+		if s == "" {
+			continue
+		}
+
+		if *dst, err = strconv.ParseFloat(s, 64); err != nil {
+			return t, fmt.Errorf("invalid %s: %w", name, err)
+		}
+	}
+
+	if t.Units <= 0 {
+		return t, errors.New("units must be positive")
+	}
+
+	return t, nil
+}
+
+// Sort orders transactions by date, account and symbol, keeping the order of same-day transactions
+func Sort(transactions []Transaction) {
+	slices.SortStableFunc(transactions, func(a, b Transaction) int {
+		return cmp.Or(
+			a.Date.Compare(b.Date),
+			cmp.Compare(a.Account, b.Account),
+			cmp.Compare(a.Symbol, b.Symbol),
+		)
+	})
+}
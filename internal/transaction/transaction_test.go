@@ -0,0 +1,65 @@
+package transaction
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRead(t *testing.T) {
+	transactions, err := Read(strings.NewReader(`Date,Account,Symbol,Type,Units,Price,Fee,Note
+2024-02-01,personal,bbca,Sell,50,"10,000",150,
+2024-01-15,personal,BBCA,buy,100,9000,0,first lot
+,,,,,,,
+2024-01-15,business,SCMMF,subscription,1000.5,1500,,
+`))
+	require.NoError(t, err)
+	require.Len(t, transactions, 3)
+
+	assert.Equal(t, Transaction{
+		Date:    time.Date(2024, 1, 15, 0, 0, 0, 0, time.Local),
+		Account: "business",
+		Symbol:  "SCMMF",
+		Type:    TypeSubscription,
+		Units:   1000.5,
+		Price:   1500,
+		Origin:  OriginManual,
+	}, transactions[0])
+	assert.Equal(t, "first lot", transactions[1].Note)
+	assert.True(t, transactions[1].IsIncrease())
+	assert.Equal(t, Transaction{
+		Date:    time.Date(2024, 2, 1, 0, 0, 0, 0, time.Local),
+		Account: "personal",
+		Symbol:  "BBCA",
+		Type:    TypeSell,
+		Units:   50,
+		Price:   10000,
+		Fee:     150,
+		Origin:  OriginManual,
+	}, transactions[2])
+	assert.False(t, transactions[2].IsIncrease())
+}
+
+func TestRead_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		err     string
+	}{
+		{name: "missing column", content: "date,account,symbol,type,units\n", err: `missing column "price"`},
+		{name: "invalid date", content: "date,account,symbol,type,units,price\n01/02/2024,a,BBCA,buy,1,1\n", err: "row 2: invalid date"},
+		{name: "unknown type", content: "date,account,symbol,type,units,price\n2024-01-02,a,BBCA,gift,1,1\n", err: `row 2: unknown type "gift"`},
+		{name: "invalid number", content: "date,account,symbol,type,units,price\n2024-01-02,a,BBCA,buy,one,1\n", err: "row 2: invalid units"},
+		{name: "zero units", content: "date,account,symbol,type,units,price\n2024-01-02,a,BBCA,buy,0,1\n", err: "row 2: units must be positive"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Read(strings.NewReader(tt.content))
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+}